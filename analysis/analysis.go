@@ -0,0 +1,356 @@
+// Package analysis implements static checks of Nilan programs which report suspicious code
+// that is still valid, such as variables which are never used.
+//
+// The checks walk the AST resolving variables with the same scope rules as the ASTCompiler:
+// variables declared outside of any block are globals, and variables declared in a block
+// are locals visible until the end of the block.
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"nilan/ast"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/token"
+	"sort"
+)
+
+// Warning is a problem found by the static analysis. Warnings do not prevent a program from running.
+type Warning struct {
+	// The 1-based line and column where the reported code starts.
+	Line   int
+	Column int
+	// Describes the problem.
+	Message string
+	// The token the warning is reported at, e.g the identifier of an unused variable.
+	Token token.Token
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%d:%d: %s", w.Line, w.Column, w.Message)
+}
+
+// Lint lexes, parses and analyses the source code, returning the warnings found
+// in the order they appear in the source code.
+//
+// An error is returned if the source code can not be lexed or parsed.
+func Lint(source string) ([]Warning, error) {
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		return nil, errors.Join(lexErrs...)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		return nil, errors.Join(parseErrs...)
+	}
+	return Analyze(statements), nil
+}
+
+// Analyze runs the static checks on the statements parsed from the source code, returning
+// the warnings found in the order they appear in the source code.
+//
+// The following problems are reported:
+//   - variables, either local or global, which are declared but never read.
+//   - local variables which shadow a variable declared in an enclosing scope.
+//   - unreachable branches of `if` statements with a constant condition, e.g `if false { ... }`.
+//   - `while` loops with a constant condition.
+//   - variables assigned to themselves, e.g `a = a`.
+func Analyze(statements []ast.Stmt) []Warning {
+	a := &analyzer{globals: map[string]*variable{}}
+	for _, stmt := range statements {
+		stmt.Accept(a)
+	}
+	for _, global := range a.globalOrder {
+		a.reportUnused(global)
+	}
+
+	sort.SliceStable(a.warnings, func(i, j int) bool {
+		return a.warnings[i].Token.Start.Offset < a.warnings[j].Token.Start.Offset
+	})
+	for i := range a.warnings {
+		a.warnings[i].Line = a.warnings[i].Token.Start.Line
+		a.warnings[i].Column = a.warnings[i].Token.Start.Column
+	}
+	return a.warnings
+}
+
+// variable is a declared variable and whether it was read.
+type variable struct {
+	declaration token.Token
+	used        bool
+	// Whether an unused variable is reported. The variable of a catch clause is not reported,
+	// as the grammar requires it even when the thrown value is not needed.
+	reportUnused bool
+}
+
+// analyzer walks the AST resolving variables and collecting warnings.
+type analyzer struct {
+	// The local scopes, from the outermost to the innermost block.
+	scopes [][]*variable
+	// The global variables by name, and in the order they are declared.
+	globals     map[string]*variable
+	globalOrder []*variable
+
+	warnings []Warning
+}
+
+func (a *analyzer) warn(tok token.Token, format string, args ...any) {
+	a.warnings = append(a.warnings, Warning{Token: tok, Message: fmt.Sprintf(format, args...)})
+}
+
+func (a *analyzer) reportUnused(v *variable) {
+	if !v.used && v.reportUnused {
+		a.warn(v.declaration, "variable '%s' is declared but never used", v.declaration.Lexeme)
+	}
+}
+
+func (a *analyzer) beginScope() {
+	a.scopes = append(a.scopes, []*variable{})
+}
+
+func (a *analyzer) endScope() {
+	for _, local := range a.scopes[len(a.scopes)-1] {
+		a.reportUnused(local)
+	}
+	a.scopes = a.scopes[:len(a.scopes)-1]
+}
+
+// declare declares a variable in the current scope.
+func (a *analyzer) declare(name token.Token, reportUnused bool) {
+	declared := &variable{declaration: name, reportUnused: reportUnused}
+	if len(a.scopes) == 0 {
+		if previous, ok := a.globals[name.Lexeme]; ok {
+			// NOTE: Redefining a global replaces it, so the previous definition can no longer be read.
+			a.reportUnused(previous)
+			previous.reportUnused = false
+		}
+		a.globals[name.Lexeme] = declared
+		a.globalOrder = append(a.globalOrder, declared)
+		return
+	}
+	if shadowed := a.resolve(name.Lexeme); shadowed != nil {
+		a.warn(name, "variable '%s' shadows the variable declared on line %d", name.Lexeme, shadowed.declaration.Start.Line)
+	}
+	scope := &a.scopes[len(a.scopes)-1]
+	*scope = append(*scope, declared)
+}
+
+// resolve returns the variable a name refers to, searching from the innermost scope to the
+// global scope, or nil if the variable is not declared.
+func (a *analyzer) resolve(name string) *variable {
+	for i := len(a.scopes) - 1; i >= 0; i-- {
+		scope := a.scopes[i]
+		for j := len(scope) - 1; j >= 0; j-- {
+			if scope[j].declaration.Lexeme == name {
+				return scope[j]
+			}
+		}
+	}
+	return a.globals[name]
+}
+
+func (a *analyzer) VisitExpressionStmt(stmt ast.ExpressionStmt) any {
+	stmt.Expression.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitPrintStmt(stmt ast.PrintStmt) any {
+	stmt.Expression.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitVarStmt(stmt ast.VarStmt) any {
+	if stmt.Initializer != nil {
+		stmt.Initializer.Accept(a)
+	}
+	a.declare(stmt.Name, true)
+	return nil
+}
+
+func (a *analyzer) VisitBlockStmt(block ast.BlockStmt) any {
+	a.beginScope()
+	for _, stmt := range block.Statements {
+		stmt.Accept(a)
+	}
+	a.endScope()
+	return nil
+}
+
+func (a *analyzer) VisitIfStmt(stmt ast.IfStmt) any {
+	stmt.Condition.Accept(a)
+	if value, ok := constantValue(stmt.Condition); ok {
+		switch {
+		case isFalsey(value):
+			a.warn(stmt.Keyword, "unreachable code, the condition of the if statement is always false")
+		case stmt.Else != nil:
+			a.warn(stmt.Keyword, "unreachable else branch, the condition of the if statement is always true")
+		default:
+			a.warn(stmt.Keyword, "the condition of the if statement is always true")
+		}
+	}
+	stmt.Then.Accept(a)
+	if stmt.Else != nil {
+		stmt.Else.Accept(a)
+	}
+	return nil
+}
+
+func (a *analyzer) VisitWhileStmt(stmt ast.WhileStmt) any {
+	stmt.Condition.Accept(a)
+	if value, ok := constantValue(stmt.Condition); ok {
+		if isFalsey(value) {
+			a.warn(stmt.Keyword, "unreachable code, the condition of the while loop is always false")
+		} else {
+			a.warn(stmt.Keyword, "the condition of the while loop is always true")
+		}
+	}
+	stmt.Body.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitTryStmt(stmt ast.TryStmt) any {
+	stmt.Body.Accept(a)
+	if stmt.Catch != nil {
+		a.beginScope()
+		a.declare(stmt.CatchName, false)
+		stmt.Catch.Accept(a)
+		a.endScope()
+	}
+	if stmt.Finally != nil {
+		stmt.Finally.Accept(a)
+	}
+	return nil
+}
+
+func (a *analyzer) VisitThrowStmt(stmt ast.ThrowStmt) any {
+	stmt.Value.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitImportStmt(stmt ast.ImportStmt) any {
+	a.declare(stmt.Name, true)
+	return nil
+}
+
+func (a *analyzer) VisitStructStmt(stmt ast.StructStmt) any {
+	a.declare(stmt.Name, true)
+	return nil
+}
+
+func (a *analyzer) VisitBinary(binary ast.Binary) any {
+	binary.Left.Accept(a)
+	binary.Right.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitLogicalExpression(logical ast.Logical) any {
+	logical.Left.Accept(a)
+	logical.Right.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitUnary(unary ast.Unary) any {
+	unary.Right.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitLiteral(literal ast.Literal) any {
+	return nil
+}
+
+func (a *analyzer) VisitGrouping(grouping ast.Grouping) any {
+	grouping.Expression.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitInterpolation(interpolation ast.Interpolation) any {
+	for _, part := range interpolation.Parts {
+		part.Accept(a)
+	}
+	return nil
+}
+
+func (a *analyzer) VisitCall(call ast.Call) any {
+	call.Callee.Accept(a)
+	for _, argument := range call.Arguments {
+		argument.Accept(a)
+	}
+	return nil
+}
+
+func (a *analyzer) VisitGet(get ast.Get) any {
+	get.Object.Accept(a)
+	return nil
+}
+
+// VisitSet visits the assignment of a field, which uses the variable holding the object.
+func (a *analyzer) VisitSet(set ast.Set) any {
+	set.Object.Accept(a)
+	set.Value.Accept(a)
+	return nil
+}
+
+func (a *analyzer) VisitVariableExpression(variable ast.Variable) any {
+	if declared := a.resolve(variable.Name.Lexeme); declared != nil {
+		declared.used = true
+	}
+	return nil
+}
+
+// VisitAssignExpression checks for self-assignments. Assigning a variable does not count as using it.
+func (a *analyzer) VisitAssignExpression(assign ast.Assign) any {
+	// NOTE: A compound assignment such as `a += a` is not a self-assignment, its value is `a + a`.
+	value := assign.AssignedValue()
+	for {
+		grouping, ok := value.(ast.Grouping)
+		if !ok {
+			break
+		}
+		value = grouping.Expression
+	}
+	if variable, ok := value.(ast.Variable); ok && variable.Name.Lexeme == assign.Name.Lexeme {
+		a.warn(assign.Name, "self-assignment of variable '%s' has no effect", assign.Name.Lexeme)
+		return nil
+	}
+	value.Accept(a)
+	return nil
+}
+
+// constantValue returns the value of an expression which always evaluates to the same value,
+// e.g `false`, `(true)` or `!null`. Expressions which read variables are never constant.
+func constantValue(expr ast.Expression) (any, bool) {
+	switch expr := expr.(type) {
+	case ast.Literal:
+		return expr.Value, true
+	case ast.Grouping:
+		return constantValue(expr.Expression)
+	case ast.Unary:
+		value, ok := constantValue(expr.Right)
+		if !ok || expr.Operator.TokenType != token.BANG {
+			return nil, false
+		}
+		return isFalsey(value), true
+	case ast.Logical:
+		left, ok := constantValue(expr.Left)
+		if !ok {
+			return nil, false
+		}
+		// NOTE: The right operand is not evaluated when the left operand decides the result.
+		if (expr.Operator.TokenType == token.OR) != isFalsey(left) {
+			return left, true
+		}
+		return constantValue(expr.Right)
+	}
+	return nil, false
+}
+
+// isFalsey reports whether a value is false in a condition. Like in the VM, only
+// `false` and `null` are false.
+func isFalsey(value any) bool {
+	if value == nil {
+		return true
+	}
+	boolean, ok := value.(bool)
+	return ok && !boolean
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "no warnings",
+			source: "var a = 1\n{ var b = a + 1 print b }\nprint a",
+			want:   nil,
+		},
+		{
+			name:   "unused global",
+			source: "var a = 1\nvar b = 2\nprint a",
+			want:   []string{"2:5: variable 'b' is declared but never used"},
+		},
+		{
+			name:   "unused local",
+			source: "{\n    var a = 1\n    var b = a\n}",
+			want:   []string{"3:9: variable 'b' is declared but never used"},
+		},
+		{
+			name:   "assigning a variable does not use it",
+			source: "var a\na = 1",
+			want:   []string{"1:5: variable 'a' is declared but never used"},
+		},
+		{
+			name:   "redefined global",
+			source: "var a = 1\nvar a = 2\nprint a",
+			want:   []string{"1:5: variable 'a' is declared but never used"},
+		},
+		{
+			name:   "unused import",
+			source: "import \"a.ni\" as a\nimport \"b.ni\" as b\nprint a.x",
+			want:   []string{"2:18: variable 'b' is declared but never used"},
+		},
+		{
+			name:   "structs",
+			source: "struct Point { x, y }\nstruct Unused {}\nvar p = Point(1, 2)\np.x = 3",
+			want:   []string{"2:8: variable 'Unused' is declared but never used"},
+		},
+		{
+			name:   "unused catch variable is not reported",
+			source: "try { throw 1 } catch (e) { print 2 }",
+			want:   nil,
+		},
+		{
+			name:   "shadowed global",
+			source: "var a = 1\n{\n  var a = 2\n  print a\n}\nprint a",
+			want:   []string{"3:7: variable 'a' shadows the variable declared on line 1"},
+		},
+		{
+			name:   "shadowed local",
+			source: "{ var a = 1 { var a = 2 print a } print a }",
+			want:   []string{"1:19: variable 'a' shadows the variable declared on line 1"},
+		},
+		{
+			name:   "shadowing catch variable",
+			source: "var e = 1\nprint e\ntry { throw 1 } catch (e) { print e }",
+			want:   []string{"3:24: variable 'e' shadows the variable declared on line 1"},
+		},
+		{
+			name:   "variables in sibling blocks do not shadow",
+			source: "{ var a = 1 print a }\n{ var a = 2 print a }",
+			want:   nil,
+		},
+		{
+			name:   "if false",
+			source: "if false {\n  print 1\n}",
+			want:   []string{"1:1: unreachable code, the condition of the if statement is always false"},
+		},
+		{
+			name:   "if with a constant condition",
+			source: "if (true) print 1 else print 2\nif !null print 3\nif false or null print 4",
+			want: []string{
+				"1:1: unreachable else branch, the condition of the if statement is always true",
+				"2:1: the condition of the if statement is always true",
+				"3:1: unreachable code, the condition of the if statement is always false",
+			},
+		},
+		{
+			name:   "if with a variable condition",
+			source: "var a = true\nif a and true print 1",
+			want:   nil,
+		},
+		{
+			name:   "while with a constant condition",
+			source: "while true { print 1 }\nwhile false { print 2 }",
+			want: []string{
+				"1:1: the condition of the while loop is always true",
+				"2:1: unreachable code, the condition of the while loop is always false",
+			},
+		},
+		{
+			name:   "self-assignment",
+			source: "var a = 1\na = (a)\nprint a",
+			want:   []string{"2:1: self-assignment of variable 'a' has no effect"},
+		},
+		{
+			name:   "warnings are sorted by position",
+			source: "var unused = 1\n{\n    var x = 1\n    x = x\n}",
+			want: []string{
+				"1:5: variable 'unused' is declared but never used",
+				"3:9: variable 'x' is declared but never used",
+				"4:5: self-assignment of variable 'x' has no effect",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := Lint(tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []string{}
+			for _, warning := range warnings {
+				got = append(got, warning.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got warnings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestLintErrors(t *testing.T) {
+	if _, err := Lint(`print "unclosed`); err == nil {
+		t.Errorf("expected a lexing error, got nil")
+	}
+	if _, err := Lint("var = 1"); err == nil {
+		t.Errorf("expected a parsing error, got nil")
+	}
+}
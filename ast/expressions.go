@@ -13,6 +13,7 @@ type Binary struct {
 	Left     Expression  // The left-hand expression (e.g., "a" in "a + b")
 	Operator token.Token // The operator (e.g., "+")
 	Right    Expression  // The right-hand expression (e.g., "b" in "a + b")
+	Span
 }
 
 func (binary Binary) Accept(v ExpressionVisitor) any {
@@ -24,6 +25,7 @@ func (binary Binary) Accept(v ExpressionVisitor) any {
 type Unary struct {
 	Operator token.Token // The operator (e.g., "!" or "-")
 	Right    Expression  // The expression the operator is applied to (e.g., "a" or "b")
+	Span
 }
 
 func (unary Unary) Accept(v ExpressionVisitor) any {
@@ -34,6 +36,7 @@ func (unary Unary) Accept(v ExpressionVisitor) any {
 // (e.g., numbers, strings, booleans, or null).
 type Literal struct {
 	Value any // The literal value (Go's `any` allows different possible types)
+	Span
 }
 
 func (literal Literal) Accept(v ExpressionVisitor) any {
@@ -44,6 +47,7 @@ func (literal Literal) Accept(v ExpressionVisitor) any {
 // Useful for controlling evaluation precedence.
 type Grouping struct {
 	Expression Expression // The inner expression inside the parentheses
+	Span
 }
 
 func (grouping Grouping) Accept(v ExpressionVisitor) any {
@@ -54,6 +58,7 @@ func (grouping Grouping) Accept(v ExpressionVisitor) any {
 // variable
 type Variable struct {
 	Name token.Token // An IDENTIFIER token
+	Span
 }
 
 // Variable represents a variable expression in the abstract syntax tree (AST).
@@ -74,15 +79,40 @@ func (variable Variable) Accept(v ExpressionVisitor) any {
 //   - Value: The expression that produces the value being assigned to the variable.
 //     This can be any valid expression node in the AST, which will be
 //     evaluated and then stored in the environment.
+//   - Operator: The assignment operator, `=` or a compound assignment operator such as `+=`.
+//
+// Example:
+// >>> `a = 1`, `a += 1`
 type Assign struct {
-	Name  token.Token
-	Value Expression
+	Name     token.Token
+	Value    Expression
+	Operator token.Token
+	Span
 }
 
 func (assign Assign) Accept(v ExpressionVisitor) any {
 	return v.VisitAssignExpression(assign)
 }
 
+// AssignedValue returns the expression whose value is assigned to the variable. It is the
+// Value of a plain assignment, while a compound assignment such as `a += 1` assigns the
+// Binary expression `a + 1`.
+func (assign Assign) AssignedValue() Expression {
+	operator, ok := token.CompoundAssignments[assign.Operator.TokenType]
+	if !ok {
+		return assign.Value
+	}
+	binaryOperator := assign.Operator
+	binaryOperator.TokenType = operator
+	binaryOperator.Lexeme = string(operator)
+	return Binary{
+		Left:     Variable{Name: assign.Name, Span: TokenSpan(assign.Name)},
+		Operator: binaryOperator,
+		Right:    assign.Value,
+		Span:     assign.Span,
+	}
+}
+
 // Logical represents a logical expression in the abstract syntax tree (AST).
 // It models the logical `if`, `else` expressions.
 //
@@ -97,8 +127,103 @@ type Logical struct {
 	Left     Expression
 	Operator token.Token
 	Right    Expression
+	Span
 }
 
 func (logical Logical) Accept(v ExpressionVisitor) any {
 	return v.VisitLogicalExpression(logical)
 }
+
+// Interpolation represents a string containing interpolated expressions in the abstract syntax tree (AST).
+// Its value is the concatenation of its parts, each converted to a string the same way `print` does.
+//
+// Fields:
+//   - Parts: The parts of the string, in order. Text and interpolated expressions alternate,
+//     starting and ending with text: every even index is a string Literal, which can be empty,
+//     and every odd index is an interpolated expression.
+//
+// Example:
+// >>> `"Hello ${name}, you have ${count + 1} items"`
+type Interpolation struct {
+	Parts []Expression
+	Span
+}
+
+func (interpolation Interpolation) Accept(v ExpressionVisitor) any {
+	return v.VisitInterpolation(interpolation)
+}
+
+// Call represents a call of a function in the abstract syntax tree (AST).
+//
+// Fields:
+//   - Callee: The expression evaluating to the function being called.
+//   - Paren: The closing parenthesis, runtime errors of the call are reported at its position.
+//   - Arguments: The arguments passed to the function, in order.
+//
+// Example:
+// >>> `max(a, b + 1)`
+type Call struct {
+	Callee    Expression
+	Paren     token.Token
+	Arguments []Expression
+	Span
+}
+
+func (call Call) Accept(v ExpressionVisitor) any {
+	return v.VisitCall(call)
+}
+
+// Get represents the access of a property of a value in the abstract syntax tree (AST),
+// such as a member of a module.
+//
+// Fields:
+//   - Object: The expression evaluating to the value whose property is accessed.
+//   - Name: The identifier token of the property.
+//
+// Example:
+// >>> `math.pi`
+type Get struct {
+	Object Expression
+	Name   token.Token
+	Span
+}
+
+func (get Get) Accept(v ExpressionVisitor) any {
+	return v.VisitGet(get)
+}
+
+// Set represents the assignment of a field of a value in the abstract syntax tree (AST),
+// such as a field of a struct instance.
+//
+// Fields:
+//   - Object: The expression evaluating to the value whose field is assigned.
+//   - Name: The identifier token of the field.
+//   - Value: The assigned expression.
+//   - Operator: The assignment operator, `=` or a compound assignment such as `+=`.
+//
+// Example:
+// >>> `p.x = 3`
+type Set struct {
+	Object   Expression
+	Name     token.Token
+	Value    Expression
+	Operator token.Token
+	Span
+}
+
+func (set Set) Accept(v ExpressionVisitor) any {
+	return v.VisitSet(set)
+}
+
+// BinaryOperator returns the operator of the Binary expression computing the assigned value of
+// a compound assignment, e.g `+` for `p.x += 1`, and false for a plain assignment.
+func (set Set) BinaryOperator() (token.Token, bool) {
+	operator, ok := token.CompoundAssignments[set.Operator.TokenType]
+	if !ok {
+		return token.Token{}, false
+	}
+	binaryOperator := set.Operator
+	binaryOperator.TokenType = operator
+	binaryOperator.Lexeme = string(operator)
+	return binaryOperator, true
+}
@@ -4,6 +4,8 @@
 
 package ast
 
+import "nilan/token"
+
 // ExpressionVisitor is the interface for operating on all Expression AST nodes.
 // Any type that wants to perform an operation on expressions (e.g., an interpreter,
 // ast-printer, or type checker) must implement this interface.
@@ -28,6 +30,18 @@ type ExpressionVisitor interface {
 
 	VisitLogicalExpression(logical Logical) any
 
+	// VisitInterpolation is called when visiting a string containing interpolated expressions (e.g., "Hello ${name}").
+	VisitInterpolation(interpolation Interpolation) any
+
+	// VisitCall is called when visiting a function call (e.g., "max(a, b)").
+	VisitCall(call Call) any
+
+	// VisitGet is called when visiting the access of a property (e.g., "math.pi").
+	VisitGet(get Get) any
+
+	// VisitSet is called when visiting the assignment of a field (e.g., "p.x = 3").
+	VisitSet(set Set) any
+
 	// TODO: Add further Visit methods as new expression grammar rules are introduced.
 }
 
@@ -54,6 +68,22 @@ type StmtVisitor interface {
 
 	VisitWhileStmt(stmt WhileStmt) any
 
+	// VisitTryStmt is called when visiting a try statement.
+	// Example: "try { foo } catch (e) { print e } finally { bar }"
+	VisitTryStmt(stmt TryStmt) any
+
+	// VisitThrowStmt is called when visiting a throw statement.
+	// Example: "throw "invalid input""
+	VisitThrowStmt(stmt ThrowStmt) any
+
+	// VisitImportStmt is called when visiting an import statement.
+	// Example: "import "lib/strings.ni" as strings"
+	VisitImportStmt(stmt ImportStmt) any
+
+	// VisitStructStmt is called when visiting a struct declaration.
+	// Example: "struct Point { x, y }"
+	VisitStructStmt(stmt StructStmt) any
+
 	// TODO: Add further visit methods as new statement grammar rules are introduced.
 }
 
@@ -69,6 +99,9 @@ type Stmt interface {
 	// Accept dispatches this statement to the appropriate Visit method
 	// of the provided StmtVisitor implementation.
 	Accept(v StmtVisitor) any
+
+	// SourceSpan returns the location of the statement in the source code.
+	SourceSpan() Span
 }
 
 // Expression is the core interface for all expression nodes in the Abstract Syntax Tree (AST).
@@ -82,4 +115,27 @@ type Expression interface {
 	// v: the Visitor instance that defines behavior for this expression type
 	// Returns: a generic result (any), since the Visitor may define its own return type
 	Accept(v ExpressionVisitor) any
+
+	// SourceSpan returns the location of the expression in the source code.
+	SourceSpan() Span
+}
+
+// Span is the location of an AST node in the source code, from the start of its first
+// token to the end of its last token. It is set by the parser on every node, nodes built
+// by hand, e.g in tests, have an empty span.
+//
+// Every AST node embeds a Span, which implements the node's SourceSpan method.
+type Span struct {
+	Start token.Position `json:"start"`
+	End   token.Position `json:"end"`
+}
+
+// SourceSpan returns the span itself.
+func (span Span) SourceSpan() Span {
+	return span
+}
+
+// TokenSpan returns the span of a single token.
+func TokenSpan(tok token.Token) Span {
+	return Span{Start: tok.Start, End: tok.End}
 }
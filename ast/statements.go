@@ -9,6 +9,7 @@ import "nilan/token"
 // This evaluates the expression and discards the result.
 type ExpressionStmt struct {
 	Expression Expression // The expression used as a statement
+	Span
 }
 
 func (e ExpressionStmt) Accept(v StmtVisitor) any {
@@ -19,6 +20,7 @@ func (e ExpressionStmt) Accept(v StmtVisitor) any {
 // of evaluating an expression. Example: `print foo + bar;`
 type PrintStmt struct {
 	Expression Expression // The expression whose result will be printed
+	Span
 }
 
 func (p PrintStmt) Accept(v StmtVisitor) any {
@@ -37,6 +39,7 @@ type VarStmt struct {
 	// For example, `var x=5` the initialser is `5`. Since this is an expression,
 	// this is also supported `var x = 5+3`.
 	Initializer Expression
+	Span
 }
 
 func (varStmt VarStmt) Accept(v StmtVisitor) any {
@@ -47,6 +50,7 @@ func (varStmt VarStmt) Accept(v StmtVisitor) any {
 // of statement expression AST nodes.
 type BlockStmt struct {
 	Statements []Stmt
+	Span
 }
 
 func (blockStmt BlockStmt) Accept(v StmtVisitor) any {
@@ -56,10 +60,13 @@ func (blockStmt BlockStmt) Accept(v StmtVisitor) any {
 // IfStmt represents an if statement containing the expression
 // to evaluate the statement to execute if the expression is true
 // or the statement to execute of the expression is false.
+// `Keyword` is the `if` token, used to report the position of the statement.
 type IfStmt struct {
+	Keyword   token.Token
 	Condition Expression
 	Then      Stmt
 	Else      Stmt
+	Span
 }
 
 func (stmt IfStmt) Accept(v StmtVisitor) any {
@@ -73,11 +80,80 @@ func (stmt IfStmt) Accept(v StmtVisitor) any {
 //     If this expression evaluates to true, the loop body executes;
 //     otherwise, the loop terminates.
 //   - Body: The block statement representing the loop body,
+//   - Keyword: The `while` token, used to report the position of the statement.
 type WhileStmt struct {
+	Keyword   token.Token
 	Condition Expression
 	Body      Stmt
+	Span
 }
 
 func (stmt WhileStmt) Accept(v StmtVisitor) any {
 	return v.VisitWhileStmt(stmt)
 }
+
+// TryStmt represents a `try` statement, which executes the statements in `Body` and
+// transfers control to the `Catch` block if an error is thrown while executing them.
+// The `Finally` block is always executed last, whether an error was thrown or not.
+//
+// Example:
+//
+//	try { throw "boom" } catch (e) { print e } finally { print "done" }
+//
+// Fields:
+//   - Keyword: The `try` token, used to report the line of the statement.
+//   - Body: The block of statements protected by the statement.
+//   - CatchName: The identifier the thrown value is bound to in the `Catch` block.
+//   - Catch: The block executed when an error is thrown, nil if there is no `catch` clause.
+//   - Finally: The block executed after `Body` and `Catch`, nil if there is no `finally` clause.
+type TryStmt struct {
+	Keyword   token.Token
+	Body      BlockStmt
+	CatchName token.Token
+	Catch     *BlockStmt
+	Finally   *BlockStmt
+	Span
+}
+
+func (stmt TryStmt) Accept(v StmtVisitor) any {
+	return v.VisitTryStmt(stmt)
+}
+
+// ThrowStmt represents a `throw` statement, which raises the value of `Value` as an error.
+// For example, `throw "invalid input"`.
+type ThrowStmt struct {
+	Keyword token.Token
+	Value   Expression
+	Span
+}
+
+func (stmt ThrowStmt) Accept(v StmtVisitor) any {
+	return v.VisitThrowStmt(stmt)
+}
+
+// ImportStmt represents an `import` statement, which executes the module at `Path` and binds
+// it to the global variable `Name`. For example, `import "lib/geometry.ni" as geometry`.
+type ImportStmt struct {
+	Keyword token.Token
+	// The string literal of the imported path.
+	Path token.Token
+	Name token.Token
+	Span
+}
+
+func (stmt ImportStmt) Accept(v StmtVisitor) any {
+	return v.VisitImportStmt(stmt)
+}
+
+// StructStmt represents a `struct` declaration, which binds `Name` to a struct whose instances
+// have the fields `Fields`, in order. For example, `struct Point { x, y }`.
+type StructStmt struct {
+	Keyword token.Token
+	Name    token.Token
+	Fields  []token.Token
+	Span
+}
+
+func (stmt StructStmt) Accept(v StmtVisitor) any {
+	return v.VisitStructStmt(stmt)
+}
@@ -0,0 +1,101 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DefaultBaselinePath is where the baseline is stored relative to the repository root.
+const DefaultBaselinePath = "benchmark/baseline.json"
+
+// Regression describes a measurement of a program that got worse compared to the baseline.
+type Regression struct {
+	Program  string
+	Engine   Engine
+	Metric   string
+	Baseline float64
+	Current  float64
+}
+
+func (r Regression) String() string {
+	change := 0.0
+	if r.Baseline != 0 {
+		change = (r.Current - r.Baseline) / r.Baseline * 100
+	}
+	return fmt.Sprintf("%s (%s): %s regressed from %.0f to %.0f (%+.1f%%)", r.Program, r.Engine, r.Metric, r.Baseline, r.Current, change)
+}
+
+// LoadBaseline reads the results stored in the baseline file at the given path.
+func LoadBaseline(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline: %s", err.Error())
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("error decoding baseline: %s", err.Error())
+	}
+	return results, nil
+}
+
+// SaveBaseline writes the results as prettified JSON to the baseline file at the given path.
+func SaveBaseline(path string, results []Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing baseline: %s", err.Error())
+	}
+	return nil
+}
+
+// Compare compares the current results against the baseline and returns the
+// measurements which regressed by more than the given tolerance. The tolerance is a
+// fraction, for example 0.1 allows a program to be 10% slower than its baseline.
+//
+// Programs that are not part of the baseline are ignored.
+func Compare(current []Result, baseline []Result, tolerance float64) []Regression {
+	type key struct {
+		program string
+		engine  Engine
+	}
+	previous := make(map[key]Result, len(baseline))
+	for _, result := range baseline {
+		previous[key{result.Program, result.Engine}] = result
+	}
+
+	regressions := []Regression{}
+	for _, result := range current {
+		base, ok := previous[key{result.Program, result.Engine}]
+		if !ok {
+			continue
+		}
+		check := func(metric string, baseValue float64, currentValue float64) {
+			if currentValue > baseValue*(1+tolerance) {
+				regressions = append(regressions, Regression{
+					Program:  result.Program,
+					Engine:   result.Engine,
+					Metric:   metric,
+					Baseline: baseValue,
+					Current:  currentValue,
+				})
+			}
+		}
+		check("ns/op", float64(base.NsPerOp), float64(result.NsPerOp))
+		check("allocs/op", float64(base.AllocsPerOp), float64(result.AllocsPerOp))
+		// The number of executed instructions is deterministic, so any increase
+		// is reported regardless of the tolerance.
+		if result.InstructionsPerOp > base.InstructionsPerOp {
+			regressions = append(regressions, Regression{
+				Program:  result.Program,
+				Engine:   result.Engine,
+				Metric:   "instructions/op",
+				Baseline: float64(base.InstructionsPerOp),
+				Current:  float64(result.InstructionsPerOp),
+			})
+		}
+	}
+	return regressions
+}
@@ -0,0 +1,166 @@
+// Package benchmark contains a corpus of Nilan programs and the tooling to measure how fast
+// they execute in the tree-walk interpreter and in the ASTCompiler + VM pipeline.
+//
+// The results can be stored as a baseline and later compared against, so performance
+// regressions introduced by changes to the VM can be spotted early.
+package benchmark
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"nilan/ast"
+	"nilan/compiler"
+	"nilan/interpreter"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/vm"
+)
+
+//go:embed corpus/*.ni
+var corpus embed.FS
+
+// Engine identifies the execution engine a program is benchmarked with.
+type Engine string
+
+const (
+	TreeWalk Engine = "tree-walk"
+	VM       Engine = "vm"
+)
+
+// Engines contains all the engines a program can be benchmarked with.
+var Engines = []Engine{TreeWalk, VM}
+
+// Program represents a Nilan program of the benchmark corpus.
+type Program struct {
+	// The name of the program, which is the file name without its extension.
+	Name   string
+	Source string
+}
+
+// Programs returns all the programs of the benchmark corpus sorted by name.
+func Programs() ([]Program, error) {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		return nil, err
+	}
+	programs := []Program{}
+	for _, entry := range entries {
+		data, err := corpus.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		programs = append(programs, Program{
+			Name:   strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())),
+			Source: string(data),
+		})
+	}
+	sort.Slice(programs, func(i, j int) bool { return programs[i].Name < programs[j].Name })
+	return programs, nil
+}
+
+// Prepared holds a program which has already been lexed, parsed and compiled, so only
+// its execution is measured.
+type Prepared struct {
+	Program    Program
+	statements []ast.Stmt
+	bytecode   compiler.Bytecode
+}
+
+// Prepare lexes, parses and compiles the program.
+func Prepare(program Program) (*Prepared, error) {
+	tokens, lexErrs := lexer.New(program.Source).Scan()
+	if len(lexErrs) > 0 {
+		return nil, fmt.Errorf("%s: %w", program.Name, lexErrs[0])
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		return nil, fmt.Errorf("%s: %w", program.Name, parseErrs[0])
+	}
+	bytecode, compileErrs := compiler.NewASTCompiler().CompileAST(statements)
+	if len(compileErrs) > 0 {
+		return nil, fmt.Errorf("%s: %w", program.Name, compileErrs[0])
+	}
+	return &Prepared{Program: program, statements: statements, bytecode: bytecode}, nil
+}
+
+// RunVM executes the compiled program once on a fresh VM, discarding its output.
+// It returns the number of instructions the VM executed.
+func (p *Prepared) RunVM() (uint64, error) {
+	machine := vm.New()
+	machine.SetOutput(io.Discard)
+	err := machine.Run(p.bytecode)
+	return machine.InstructionCount(), err
+}
+
+// RunTreeWalk executes the program once with a fresh tree-walk interpreter, discarding its output.
+func (p *Prepared) RunTreeWalk() {
+	interpreter := interpreter.Make()
+	interpreter.SetOutput(io.Discard)
+	interpreter.Interpret(p.statements)
+}
+
+// Result contains the measurements of a program executed by an engine.
+type Result struct {
+	Program    string `json:"program"`
+	Engine     Engine `json:"engine"`
+	Iterations int    `json:"iterations"`
+	NsPerOp    int64  `json:"nsPerOp"`
+	// InstructionsPerOp and InstructionsPerSec are only reported by the VM.
+	InstructionsPerOp  uint64  `json:"instructionsPerOp"`
+	InstructionsPerSec float64 `json:"instructionsPerSec"`
+	AllocsPerOp        uint64  `json:"allocsPerOp"`
+	BytesPerOp         uint64  `json:"bytesPerOp"`
+}
+
+// Measure executes the prepared program `iterations` times with the given engine and
+// returns the average time, instructions and allocations per execution.
+func Measure(p *Prepared, engine Engine, iterations int) (Result, error) {
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	var instructions uint64
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for i := 0; i < iterations; i++ {
+		switch engine {
+		case VM:
+			count, err := p.RunVM()
+			if err != nil {
+				return Result{}, fmt.Errorf("%s: %w", p.Program.Name, err)
+			}
+			instructions += count
+		case TreeWalk:
+			p.RunTreeWalk()
+		default:
+			return Result{}, fmt.Errorf("unknown engine '%s'", engine)
+		}
+	}
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	result := Result{
+		Program:           p.Program.Name,
+		Engine:            engine,
+		Iterations:        iterations,
+		NsPerOp:           elapsed.Nanoseconds() / int64(iterations),
+		InstructionsPerOp: instructions / uint64(iterations),
+		AllocsPerOp:       (after.Mallocs - before.Mallocs) / uint64(iterations),
+		BytesPerOp:        (after.TotalAlloc - before.TotalAlloc) / uint64(iterations),
+	}
+	if elapsed > 0 {
+		result.InstructionsPerSec = float64(instructions) / elapsed.Seconds()
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package benchmark
+
+import (
+	"testing"
+)
+
+func prepareAll(tb testing.TB) []*Prepared {
+	tb.Helper()
+	programs, err := Programs()
+	if err != nil {
+		tb.Fatalf("loading corpus: %v", err)
+	}
+	prepared := []*Prepared{}
+	for _, program := range programs {
+		p, err := Prepare(program)
+		if err != nil {
+			tb.Fatalf("preparing program: %v", err)
+		}
+		prepared = append(prepared, p)
+	}
+	return prepared
+}
+
+// BenchmarkVM runs every program of the corpus through the ASTCompiler + VM.
+func BenchmarkVM(b *testing.B) {
+	for _, p := range prepareAll(b) {
+		b.Run(p.Program.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			var instructions uint64
+			for i := 0; i < b.N; i++ {
+				count, err := p.RunVM()
+				if err != nil {
+					b.Fatalf("runtime error: %v", err)
+				}
+				instructions += count
+			}
+			b.ReportMetric(float64(instructions)/b.Elapsed().Seconds(), "instructions/sec")
+		})
+	}
+}
+
+// BenchmarkTreeWalk runs every program of the corpus through the tree-walk interpreter.
+func BenchmarkTreeWalk(b *testing.B) {
+	for _, p := range prepareAll(b) {
+		b.Run(p.Program.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p.RunTreeWalk()
+			}
+		})
+	}
+}
+
+// TestCorpusCompiles ensures every program of the corpus can be prepared and
+// executed by the VM, so the benchmarks don't silently measure failures.
+func TestCorpusCompiles(t *testing.T) {
+	for _, p := range prepareAll(t) {
+		if _, err := p.RunVM(); err != nil {
+			t.Errorf("%s: %v", p.Program.Name, err)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []Result{
+		{Program: "fib", Engine: VM, NsPerOp: 1000, AllocsPerOp: 10, InstructionsPerOp: 500},
+		{Program: "loops", Engine: TreeWalk, NsPerOp: 1000, AllocsPerOp: 10},
+	}
+
+	tests := []struct {
+		name    string
+		current []Result
+		metrics []string
+	}{
+		{
+			name: "within tolerance",
+			current: []Result{
+				{Program: "fib", Engine: VM, NsPerOp: 1050, AllocsPerOp: 10, InstructionsPerOp: 500},
+				{Program: "loops", Engine: TreeWalk, NsPerOp: 900, AllocsPerOp: 9},
+			},
+			metrics: []string{},
+		},
+		{
+			name: "slower and more allocations",
+			current: []Result{
+				{Program: "fib", Engine: VM, NsPerOp: 1200, AllocsPerOp: 20, InstructionsPerOp: 500},
+			},
+			metrics: []string{"ns/op", "allocs/op"},
+		},
+		{
+			name: "more instructions",
+			current: []Result{
+				{Program: "fib", Engine: VM, NsPerOp: 1000, AllocsPerOp: 10, InstructionsPerOp: 501},
+			},
+			metrics: []string{"instructions/op"},
+		},
+		{
+			name: "program not in baseline",
+			current: []Result{
+				{Program: "new", Engine: VM, NsPerOp: 99999},
+			},
+			metrics: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regressions := Compare(tt.current, baseline, 0.1)
+			if len(regressions) != len(tt.metrics) {
+				t.Fatalf("got %d regressions, want %d: %v", len(regressions), len(tt.metrics), regressions)
+			}
+			for i, metric := range tt.metrics {
+				if regressions[i].Metric != metric {
+					t.Errorf("regression %d - got metric: %s, want: %s", i, regressions[i].Metric, metric)
+				}
+			}
+		})
+	}
+}
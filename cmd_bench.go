@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"nilan/benchmark"
+
+	"github.com/google/subcommands"
+)
+
+// benchCmd runs the benchmark corpus through the tree-walk interpreter and the compiled version of nilan.
+type benchCmd struct {
+	iterations   int
+	engine       string
+	baselinePath string
+	update       bool
+	tolerance    float64
+}
+
+func (*benchCmd) Name() string { return "bench" }
+func (*benchCmd) Synopsis() string {
+	return "Benchmark the tree-walk interpreter and the VM against a stored baseline"
+}
+func (*benchCmd) Usage() string {
+	return `nilan bench [-n iterations] [-engine all|vm|tree-walk] [-baseline path] [-update] [-tolerance 0.1]
+`
+}
+
+func (cmd *benchCmd) SetFlags(f *flag.FlagSet) {
+	f.IntVar(&cmd.iterations, "n", 20, "The number of times each program is executed.")
+	f.StringVar(&cmd.engine, "engine", "all", "The engine to benchmark: all, vm or tree-walk.")
+	f.StringVar(&cmd.baselinePath, "baseline", benchmark.DefaultBaselinePath, "The file path of the baseline to compare against.")
+	f.BoolVar(&cmd.update, "update", false, "Overwrites the baseline with the results of this run.")
+	f.Float64Var(&cmd.tolerance, "tolerance", 0.1, "The fraction a measurement can exceed its baseline before it is reported as a regression.")
+}
+
+func (cmd *benchCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	engines := benchmark.Engines
+	if cmd.engine != "all" {
+		engines = []benchmark.Engine{benchmark.Engine(cmd.engine)}
+	}
+
+	programs, err := benchmark.Programs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "💥 Failed to load benchmark corpus:\n\t%v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	results := []benchmark.Result{}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PROGRAM\tENGINE\tNS/OP\tINSTRUCTIONS/SEC\tALLOCS/OP\tBYTES/OP")
+	for _, program := range programs {
+		prepared, err := benchmark.Prepare(program)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "💥 Failed to prepare program:\n\t%v\n", err)
+			return subcommands.ExitFailure
+		}
+		for _, engine := range engines {
+			result, err := benchmark.Measure(prepared, engine, cmd.iterations)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "💥 Benchmark error:\n\t%v\n", err)
+				return subcommands.ExitFailure
+			}
+			results = append(results, result)
+
+			instructionsPerSec := "-"
+			if engine == benchmark.VM {
+				instructionsPerSec = fmt.Sprintf("%.0f", result.InstructionsPerSec)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%d\t%s\t%d\t%d\n", result.Program, result.Engine, result.NsPerOp, instructionsPerSec, result.AllocsPerOp, result.BytesPerOp)
+		}
+	}
+	writer.Flush()
+
+	if cmd.update {
+		if err := benchmark.SaveBaseline(cmd.baselinePath, results); err != nil {
+			fmt.Fprintf(os.Stderr, "💥 %v\n", err)
+			return subcommands.ExitFailure
+		}
+		fmt.Printf("\nBaseline written to %s\n", cmd.baselinePath)
+		return subcommands.ExitSuccess
+	}
+
+	baseline, err := benchmark.LoadBaseline(cmd.baselinePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\n⚠️ Skipping baseline comparison: %v\n", err)
+		return subcommands.ExitSuccess
+	}
+
+	regressions := benchmark.Compare(results, baseline, cmd.tolerance)
+	if len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\n💥 Performance regressions compared to %s:\n", cmd.baselinePath)
+		for _, regression := range regressions {
+			fmt.Fprintf(os.Stderr, "\t%s\n", regression)
+		}
+		return subcommands.ExitFailure
+	}
+	fmt.Printf("\n✅ No regressions compared to %s\n", cmd.baselinePath)
+	return subcommands.ExitSuccess
+}
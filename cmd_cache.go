@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"nilan/modules"
+
+	"github.com/google/subcommands"
+)
+
+// cacheFlags are the flags which configure the cache of compiled programs.
+type cacheFlags struct {
+	dir     string
+	noCache bool
+}
+
+func (c *cacheFlags) setFlags(f *flag.FlagSet, withNoCache bool) {
+	f.StringVar(&c.dir, "cache-dir", "", fmt.Sprintf("The directory compiled programs are cached in. Defaults to the %s environment variable or the nilan directory of the user's cache directory.", modules.CacheDirEnv))
+	if withNoCache {
+		f.BoolVar(&c.noCache, "no-cache", false, "Compile the program and its modules without loading them from or storing them in the cache.")
+	}
+}
+
+// cache returns the cache of compiled programs, or nil if caching is disabled or the cache
+// directory can not be determined.
+func (c *cacheFlags) cache() *modules.Cache {
+	if c.noCache {
+		return nil
+	}
+	dir, err := c.directory()
+	if err != nil {
+		return nil
+	}
+	return modules.NewCache(dir)
+}
+
+// directory returns the directory of the cache set by the flag, or the default one.
+func (c *cacheFlags) directory() (string, error) {
+	if c.dir != "" {
+		return c.dir, nil
+	}
+	return modules.DefaultCacheDir()
+}
+
+// cacheCmd manages the cache of compiled programs.
+type cacheCmd struct {
+	flags cacheFlags
+}
+
+func (*cacheCmd) Name() string     { return "cache" }
+func (*cacheCmd) Synopsis() string { return "Manage the cache of compiled programs" }
+func (*cacheCmd) Usage() string {
+	return `cache [flags] clean:
+  The runC command caches the bytecode of the programs and modules it compiles, so they are only
+  compiled again when their source changes. clean removes every cached program.
+`
+}
+func (c *cacheCmd) SetFlags(f *flag.FlagSet) {
+	c.flags.setFlags(f, false)
+}
+
+func (c *cacheCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	args := f.Args()
+	if len(args) != 1 || args[0] != "clean" {
+		fmt.Fprintf(os.Stderr, "💥 Expected the 'clean' action\n")
+		return subcommands.ExitUsageError
+	}
+	dir, err := c.flags.directory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "💥 Failed to find the cache directory: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	removed, err := modules.NewCache(dir).Clean()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "💥 Failed to clean the cache: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	fmt.Printf("Removed %d cached programs from %s\n", removed, dir)
+	return subcommands.ExitSuccess
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"nilan/compiler"
+	"nilan/diagnostics"
 	"nilan/lexer"
 	"nilan/parser"
 	"os"
@@ -17,6 +18,7 @@ type emitBytecodeCmd struct {
 	diassemble   bool
 	dumpBytecode bool
 	filePath     string
+	errorFormat  string
 }
 
 func (*emitBytecodeCmd) Name() string { return "emit" }
@@ -31,6 +33,7 @@ func (cmd *emitBytecodeCmd) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.diassemble, "diassemble", true, "diassemble the bytecode and dump it to a text file.")
 	f.BoolVar(&cmd.dumpBytecode, "dumpBytecode", true, "Writes the encoded bytecode as hexadecimal to a .nic file")
 	f.StringVar(&cmd.filePath, "file path", "/", "The file path to write the diassembled bytecode to. If no file path is provided the file will be saved under the same directory where this command is executed from.")
+	f.StringVar(&cmd.errorFormat, "error-format", errorFormatText, "The format errors are reported in, 'text' or 'json'.")
 }
 
 func (r *emitBytecodeCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
@@ -40,34 +43,36 @@ func (r *emitBytecodeCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...int
 		return subcommands.ExitUsageError
 	}
 	nilanFile := args[0]
+	if !validErrorFormat(r.errorFormat) {
+		fmt.Fprintf(os.Stderr, "💥 Unknown error format: '%s'\n", r.errorFormat)
+		return subcommands.ExitUsageError
+	}
 	data, err := os.ReadFile(nilanFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "💥 Failed to read file:\n\t%v", err.Error())
 		return subcommands.ExitFailure
 	}
 
-	lex := lexer.New(string(data))
-	tokens, err := lex.Scan()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Lexing error: %v\n", err)
+	source := string(data)
+	lex := lexer.New(source)
+	tokens, lexErrs := lex.Scan()
+	if len(lexErrs) > 0 {
+		reportErrors(os.Stderr, r.errorFormat, nilanFile, source, diagnostics.CodeLexical, lexErrs...)
 		return subcommands.ExitFailure
 	}
 
 	parser := parser.Make(tokens)
 	statements, parseErrs := parser.Parse()
 	if len(parseErrs) > 0 {
-		fmt.Fprintf(os.Stderr, "💥 Parsing error:\n")
-		for _, pErr := range parseErrs {
-			fmt.Fprintf(os.Stderr, "\t%v\n", pErr)
-		}
+		reportErrors(os.Stderr, r.errorFormat, nilanFile, source, diagnostics.CodeSyntax, parseErrs...)
 		return subcommands.ExitFailure
 	}
 
 	astCompiler := compiler.NewASTCompiler()
-	_, cErr := astCompiler.CompileAST(statements)
+	_, cErrs := astCompiler.CompileAST(statements)
 
-	if cErr != nil {
-		fmt.Fprintf(os.Stderr, "💥 Compilation error:\n\t%v\n", cErr)
+	if len(cErrs) > 0 {
+		reportErrors(os.Stderr, r.errorFormat, nilanFile, source, diagnostics.CodeSemantic, cErrs...)
 		return subcommands.ExitFailure
 	}
 
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"nilan/formatter"
+
+	"github.com/google/subcommands"
+)
+
+// fmtCmd formats Nilan source files in the canonical style.
+type fmtCmd struct {
+	write bool
+	check bool
+}
+
+func (*fmtCmd) Name() string     { return "fmt" }
+func (*fmtCmd) Synopsis() string { return "Format Nilan source files" }
+func (*fmtCmd) Usage() string {
+	return `fmt [-w] [-check] <file>...:
+  Format Nilan source files in the canonical style. Comments are preserved.
+  By default the formatted source code is written to standard output.
+`
+}
+
+func (cmd *fmtCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.write, "w", false, "Write the formatted source code back to the files instead of standard output.")
+	f.BoolVar(&cmd.check, "check", false, "List the files that are not formatted and exit with a failure if there are any. No file is modified.")
+}
+
+func (cmd *fmtCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	files := f.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "💥 File not provided\n")
+		return subcommands.ExitUsageError
+	}
+
+	status := subcommands.ExitSuccess
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "💥 Failed to read file:\n\t%v\n", err)
+			status = subcommands.ExitFailure
+			continue
+		}
+		formatted, err := formatter.Format(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "💥 Failed to format %s:\n%v\n", file, err)
+			status = subcommands.ExitFailure
+			continue
+		}
+
+		switch {
+		case cmd.check:
+			if formatted != string(data) {
+				fmt.Println(file)
+				status = subcommands.ExitFailure
+			}
+		case cmd.write:
+			if formatted == string(data) {
+				continue
+			}
+			if err := os.WriteFile(file, []byte(formatted), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "💥 Failed to write file:\n\t%v\n", err)
+				status = subcommands.ExitFailure
+			}
+		default:
+			fmt.Print(formatted)
+		}
+	}
+	return status
+}
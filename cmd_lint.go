@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"nilan/analysis"
+
+	"github.com/google/subcommands"
+)
+
+// lintCmd reports suspicious code in Nilan source files.
+type lintCmd struct{}
+
+func (*lintCmd) Name() string     { return "lint" }
+func (*lintCmd) Synopsis() string { return "Report suspicious code in Nilan source files" }
+func (*lintCmd) Usage() string {
+	return `lint <file>...:
+  Statically analyse Nilan source files and report warnings, such as unused or shadowed variables,
+  unreachable code, constant loop conditions and self-assignments.
+  Exits with a failure if any warning is reported.
+`
+}
+func (*lintCmd) SetFlags(f *flag.FlagSet) {}
+
+func (*lintCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	files := f.Args()
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "💥 File not provided\n")
+		return subcommands.ExitUsageError
+	}
+
+	status := subcommands.ExitSuccess
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "💥 Failed to read file:\n\t%v\n", err)
+			status = subcommands.ExitFailure
+			continue
+		}
+		warnings, err := analysis.Lint(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "💥 Failed to lint %s:\n%v\n", file, err)
+			status = subcommands.ExitFailure
+			continue
+		}
+		for _, warning := range warnings {
+			fmt.Printf("%s:%s\n", file, warning)
+			status = subcommands.ExitFailure
+		}
+	}
+	return status
+}
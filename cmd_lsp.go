@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"nilan/lsp"
+
+	"github.com/google/subcommands"
+)
+
+// lspCmd runs the Nilan language server over stdio.
+type lspCmd struct{}
+
+func (*lspCmd) Name() string     { return "lsp" }
+func (*lspCmd) Synopsis() string { return "Run the Nilan language server over stdio" }
+func (*lspCmd) Usage() string {
+	return `lsp:
+  Run a Language Server Protocol (LSP) server over standard input and output.
+  It provides diagnostics, go-to-definition, hover and document symbols for Nilan source files.
+`
+}
+func (*lspCmd) SetFlags(f *flag.FlagSet) {}
+
+func (*lspCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	// NOTE: Standard output is used by the protocol, so errors are written to standard error.
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+		fmt.Fprintf(os.Stderr, "💥 Language server error: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
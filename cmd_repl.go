@@ -42,9 +42,11 @@ func repl(in io.Reader, out io.Writer) {
 			os.Exit(0)
 		}
 		lex := lexer.New(line)
-		tokens, err := lex.Scan()
-		if err != nil {
-			fmt.Println(err)
+		tokens, lexErrs := lex.Scan()
+		if len(lexErrs) > 0 {
+			for _, lexErr := range lexErrs {
+				fmt.Println(lexErr)
+			}
 			continue
 		}
 		parser := parser.Make(tokens)
@@ -57,7 +59,9 @@ func repl(in io.Reader, out io.Writer) {
 		}
 		parser.Print(ast)
 		interpreter.Interpret(ast)
-
+		if code, exited := interpreter.ExitCode(); exited {
+			os.Exit(code)
+		}
 	}
 }
 
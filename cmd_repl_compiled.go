@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"nilan/compiler"
+	"nilan/diagnostics"
 	"nilan/lexer"
+	"nilan/modules"
 	"nilan/parser"
+	"nilan/stdlib"
 	"nilan/token"
 	"nilan/vm"
 
@@ -64,6 +68,8 @@ func (cmd *replCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...i
 
 	astCompiler := compiler.NewASTCompiler()
 	vm := vm.New()
+	// NOTE: Modules imported in the REPL are resolved from the working directory.
+	vm.SetImporter(modules.NewLoader(modules.SearchPath("")), "")
 	var buffer strings.Builder
 
 	for {
@@ -94,14 +100,13 @@ func (cmd *replCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...i
 		source := buffer.String()
 
 		lex := lexer.New(source)
-		tokens, err := lex.Scan()
-		if err != nil {
-			fmt.Println(err)
-			buffer.Reset()
+		tokens, lexErrs := lex.Scan()
+		if !isInputReady(tokens, lexErrs) {
 			continue
 		}
-
-		if !isInputReady(tokens) {
+		if len(lexErrs) > 0 {
+			reportErrors(os.Stderr, errorFormatText, "", source, diagnostics.CodeLexical, lexErrs...)
+			buffer.Reset()
 			continue
 		}
 
@@ -114,19 +119,16 @@ func (cmd *replCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...i
 			if allParseErrorsAtEOF(parseErrs, tokens[len(tokens)-1]) {
 				continue
 			}
-			fmt.Fprintf(os.Stdout, "Parse error: ")
-			for _, pErr := range parseErrs {
-				fmt.Fprintf(os.Stdout, "%v\n", pErr)
-			}
+			reportErrors(os.Stderr, errorFormatText, "", source, diagnostics.CodeSyntax, parseErrs...)
 			buffer.Reset()
 			continue
 		}
 
 		// TODO/NOTE: Previous compiled code is going to be recompiled again in the REPL,
 		// but for now its fine
-		bytecode, err := astCompiler.CompileAST(statements)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
+		bytecode, compileErrs := astCompiler.CompileAST(statements)
+		if len(compileErrs) > 0 {
+			reportErrors(os.Stderr, errorFormatText, "", source, diagnostics.CodeSemantic, compileErrs...)
 			buffer.Reset()
 			continue
 		}
@@ -154,8 +156,11 @@ func (cmd *replCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...i
 		}
 
 		runtimeErr := vm.Run(bytecode)
+		if exit, ok := runtimeErr.(stdlib.ExitError); ok {
+			return subcommands.ExitStatus(exit.Code)
+		}
 		if runtimeErr != nil {
-			fmt.Fprintln(os.Stderr, runtimeErr.Error())
+			reportErrors(os.Stderr, errorFormatText, "", source, diagnostics.CodeRuntime, runtimeErr)
 			buffer.Reset()
 			continue
 		}
@@ -167,8 +172,18 @@ func (cmd *replCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...i
 // and also checks if the last non-EOF token is an operator or a keyword that expects more input.
 //
 // For example, if the user types `if (x > 5) {`, the REPL should wait for more input until the
-// user finishes the block with a `}`.
-func isInputReady(tokens []token.Token) bool {
+// user finishes the block with a `}`. Likewise, a string which is only unterminated because it continues
+// on the next line waits for more input, while any other lexing error makes the input ready so it is reported.
+func isInputReady(tokens []token.Token, lexErrs []error) bool {
+	for _, lexErr := range lexErrs {
+		var err lexer.LexError
+		if !errors.As(lexErr, &err) || err.Kind != lexer.UnterminatedString {
+			return true
+		}
+	}
+	if len(lexErrs) > 0 {
+		return false
+	}
 
 	braceBalance := 0
 	for _, tok := range tokens {
@@ -195,6 +210,19 @@ func isInputReady(tokens []token.Token) bool {
 		token.SUB,
 		token.MULT,
 		token.DIV,
+		token.FLOOR_DIV,
+		token.MOD,
+		token.POW,
+		token.BIT_AND,
+		token.BIT_OR,
+		token.BIT_XOR,
+		token.BIT_NOT,
+		token.SHIFT_LEFT,
+		token.SHIFT_RIGHT,
+		token.ADD_ASSIGN,
+		token.SUB_ASSIGN,
+		token.MULT_ASSIGN,
+		token.DIV_ASSIGN,
 		token.BANG,
 		token.EQUAL_EQUAL,
 		token.NOT_EQUAL,
@@ -203,6 +231,7 @@ func isInputReady(tokens []token.Token) bool {
 		token.LARGER,
 		token.LARGER_EQUAL,
 		token.COMMA,
+		token.DOT,
 		token.LPA,
 		token.LCUR,
 		token.IF,
@@ -240,7 +269,7 @@ func allParseErrorsAtEOF(parseErrs []error, eof token.Token) bool {
 		if !ok {
 			return false
 		}
-		if syntaxErr.Line != eof.Line || syntaxErr.Column != eof.Column {
+		if syntaxErr.Start != eof.Start {
 			return false
 		}
 	}
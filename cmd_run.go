@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"nilan/diagnostics"
 	"nilan/interpreter"
 	"nilan/lexer"
 	"nilan/parser"
@@ -14,16 +15,22 @@ import (
 )
 
 // replCmd implements the REPL command
-type runCmd struct{}
+type runCmd struct {
+	errorFormat  string
+	capabilities capabilityFlags
+}
 
 func (*runCmd) Name() string     { return "run" }
 func (*runCmd) Synopsis() string { return "Execute Nilan code from a source file" }
 func (*runCmd) Usage() string {
-	return `run:
-  Execute Nilan code.
+	return `run [flags] <file> [arguments...]:
+  Execute Nilan code. The arguments after the file are returned by args() in the program.
 `
 }
-func (r *runCmd) SetFlags(f *flag.FlagSet) {}
+func (r *runCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&r.errorFormat, "error-format", errorFormatText, "The format errors are reported in, 'text' or 'json'.")
+	r.capabilities.setFlags(f)
+}
 
 func (r *runCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	args := f.Args()
@@ -32,6 +39,10 @@ func (r *runCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{})
 		return subcommands.ExitUsageError
 	}
 	filename := args[0]
+	if !validErrorFormat(r.errorFormat) {
+		fmt.Fprintf(os.Stderr, "💥 Unknown error format: '%s'\n", r.errorFormat)
+		return subcommands.ExitUsageError
+	}
 
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -39,21 +50,28 @@ func (r *runCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{})
 		return subcommands.ExitFailure
 	}
 
-	interpreter := interpreter.Make()
-	lex := lexer.New(string(data))
-	tokens, err := lex.Scan()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Lexing error: %v\n", err)
+	source := string(data)
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeLexical, lexErrs...)
 		return subcommands.ExitFailure
 	}
-	parser := parser.Make(tokens)
-	ast, errors := parser.Parse()
+	ast, errors := parser.Make(tokens).Parse()
 	if len(errors) > 0 {
-		for _, error := range errors {
-			fmt.Fprintln(os.Stderr, error)
-		}
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeSyntax, errors...)
 		return subcommands.ExitFailure
 	}
+
+	interpreter := interpreter.Make()
+	interpreter.SetHost(r.capabilities.host(args[1:]))
+	status := subcommands.ExitSuccess
+	interpreter.SetErrorReporter(func(err error) {
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeRuntime, err)
+		status = subcommands.ExitFailure
+	})
 	interpreter.Interpret(ast)
-	return subcommands.ExitSuccess
+	if code, exited := interpreter.ExitCode(); exited {
+		return subcommands.ExitStatus(code)
+	}
+	return status
 }
@@ -5,26 +5,49 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"nilan/compiler"
+	"nilan/diagnostics"
 	"nilan/lexer"
+	"nilan/modules"
 	"nilan/parser"
+	"nilan/stdlib"
+	"nilan/value"
 	"nilan/vm"
 
 	"github.com/google/subcommands"
 )
 
 // replCmd implements the REPL command
-type runCompiledCmd struct{}
+type runCompiledCmd struct {
+	errorFormat     string
+	overflow        string
+	decimalRounding string
+	decimalPlaces   int
+	capabilities    capabilityFlags
+	modulePath      string
+	cache           cacheFlags
+}
 
 func (*runCompiledCmd) Name() string     { return "runC" }
 func (*runCompiledCmd) Synopsis() string { return "Execute Nilan code from a source file" }
 func (*runCompiledCmd) Usage() string {
-	return `run:
-  Execute Nilan code.
+	return `runC [flags] <file> [arguments...]:
+  Execute Nilan code from a source file (.ni) or a bytecode file (.nic) written by the emit command.
+  The arguments after the file are returned by args() in the program.
+  The bytecode of the program and its modules is cached, see the cache command.
 `
 }
-func (r *runCompiledCmd) SetFlags(f *flag.FlagSet) {}
+func (r *runCompiledCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&r.errorFormat, "error-format", errorFormatText, "The format errors are reported in, 'text' or 'json'.")
+	f.StringVar(&r.overflow, "overflow", vm.OverflowWrap.String(), "What integer operations do when their result does not fit in 64 bits: 'wrap' around, 'check' and raise an error or 'promote' to an arbitrary-precision integer.")
+	f.StringVar(&r.decimalRounding, "decimal-rounding", value.RoundHalfEven.String(), "How inexact decimal divisions are rounded: 'half-even', 'half-up', 'down', 'up', 'ceiling' or 'floor'.")
+	f.IntVar(&r.decimalPlaces, "decimal-places", vm.DefaultDecimalPlaces, "The number of digits after the decimal point inexact decimal divisions are rounded to.")
+	r.capabilities.setFlags(f)
+	f.StringVar(&r.modulePath, "module-path", "", fmt.Sprintf("Directories searched for imported modules after the directory of the importing file, separated by '%c'. The directories of the %s environment variable are searched next.", os.PathListSeparator, modules.SearchPathEnv))
+	r.cache.setFlags(f, true)
+}
 
 func (r *runCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	args := f.Args()
@@ -33,6 +56,50 @@ func (r *runCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...inte
 		return subcommands.ExitUsageError
 	}
 	filename := args[0]
+	if !validErrorFormat(r.errorFormat) {
+		fmt.Fprintf(os.Stderr, "💥 Unknown error format: '%s'\n", r.errorFormat)
+		return subcommands.ExitUsageError
+	}
+	overflow, err := vm.ParseIntegerOverflow(r.overflow)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "💥 %v\n", err)
+		return subcommands.ExitUsageError
+	}
+	rounding, err := value.ParseRounding(r.decimalRounding)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "💥 %v\n", err)
+		return subcommands.ExitUsageError
+	}
+	if r.decimalPlaces < 0 || r.decimalPlaces > value.MaxDecimalExponent {
+		fmt.Fprintf(os.Stderr, "💥 The number of decimal places must be between 0 and %d\n", value.MaxDecimalExponent)
+		return subcommands.ExitUsageError
+	}
+
+	if strings.HasSuffix(filename, ".nic") {
+		// NOTE: LoadBytecode verifies the bytecode before it is executed.
+		bytecode, err := vm.LoadBytecode(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "💥 Failed to load bytecode: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		machine := vm.New()
+		machine.SetIntegerOverflow(overflow)
+		machine.SetDecimalRounding(rounding, int32(r.decimalPlaces))
+		machine.SetHost(r.capabilities.host(args[1:]))
+		if err := r.setImporter(machine, filename); err != nil {
+			fmt.Fprintf(os.Stderr, "💥 %v\n", err)
+			return subcommands.ExitFailure
+		}
+		if err := machine.Run(bytecode); err != nil {
+			if exit, ok := err.(stdlib.ExitError); ok {
+				return subcommands.ExitStatus(exit.Code)
+			}
+			// NOTE: The source code is not available, only the line of the error is reported.
+			reportErrors(os.Stderr, r.errorFormat, filename, "", diagnostics.CodeRuntime, err)
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
 
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -40,33 +107,72 @@ func (r *runCompiledCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...inte
 		return subcommands.ExitFailure
 	}
 
-	compiler := compiler.NewASTCompiler()
+	source := string(data)
+	cache := r.cache.cache()
 	vm := vm.New()
-	lex := lexer.New(string(data))
-	tokens, err := lex.Scan()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
+	vm.SetIntegerOverflow(overflow)
+	vm.SetDecimalRounding(rounding, int32(r.decimalPlaces))
+	vm.SetHost(r.capabilities.host(args[1:]))
+	if err := r.setImporter(vm, filename); err != nil {
+		fmt.Fprintf(os.Stderr, "💥 %v\n", err)
 		return subcommands.ExitFailure
 	}
-	parser := parser.Make(tokens)
-	ast, errors := parser.Parse()
-	if len(errors) > 0 {
-		for _, error := range errors {
-			fmt.Fprintln(os.Stderr, error)
-		}
-		return subcommands.ExitFailure
+	bytecode, ok := compiler.Bytecode{}, false
+	if cache != nil {
+		bytecode, ok = cache.Load(source)
 	}
-	bytecode, err := compiler.CompileAST(ast)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
-		return subcommands.ExitFailure
+	if !ok {
+		var status subcommands.ExitStatus
+		if bytecode, status = r.compile(filename, source); status != subcommands.ExitSuccess {
+			return status
+		}
+		if cache != nil {
+			// NOTE: The cache only saves time, the program is executed even if it can not be stored.
+			_ = cache.Store(source, bytecode)
+		}
 	}
 
 	err = vm.Run(bytecode)
+	if exit, ok := err.(stdlib.ExitError); ok {
+		return subcommands.ExitStatus(exit.Code)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeRuntime, err)
 		return subcommands.ExitFailure
 	}
 
 	return subcommands.ExitSuccess
 }
+
+// compile compiles the source code of a file, reporting its errors.
+func (r *runCompiledCmd) compile(filename string, source string) (compiler.Bytecode, subcommands.ExitStatus) {
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeLexical, lexErrs...)
+		return compiler.Bytecode{}, subcommands.ExitFailure
+	}
+	ast, errors := parser.Make(tokens).Parse()
+	if len(errors) > 0 {
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeSyntax, errors...)
+		return compiler.Bytecode{}, subcommands.ExitFailure
+	}
+	bytecode, compileErrs := compiler.NewASTCompiler().CompileAST(ast)
+	if len(compileErrs) > 0 {
+		reportErrors(os.Stderr, r.errorFormat, filename, source, diagnostics.CodeSemantic, compileErrs...)
+		return compiler.Bytecode{}, subcommands.ExitFailure
+	}
+	return bytecode, subcommands.ExitSuccess
+}
+
+// setImporter lets the program executed by the VM import modules, which are resolved from the
+// directory of the program's file and the module search path, and loaded from the cache.
+func (r *runCompiledCmd) setImporter(machine *vm.VirtualMachine, filename string) error {
+	main, err := modules.Canonical(filename)
+	if err != nil {
+		return err
+	}
+	loader := modules.NewLoader(modules.SearchPath(r.modulePath))
+	loader.SetCache(r.cache.cache())
+	machine.SetImporter(loader, main)
+	return nil
+}
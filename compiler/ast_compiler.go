@@ -5,8 +5,12 @@ package compiler
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"nilan/ast"
+	"nilan/diagnostics"
+	"nilan/stdlib"
 	"nilan/token"
+	"nilan/value"
 	"os"
 	"strings"
 )
@@ -24,6 +28,8 @@ type Local struct {
 	initialized bool
 	// The slot index where the variable is stored. Used for local variable access in the VM.
 	slot uint16
+	// The identifier token of the variable's declaration.
+	declaration token.Token
 }
 
 // ASTCompiler is a visitor that compiles AST nodes directly to bytecode.
@@ -43,6 +49,20 @@ type ASTCompiler struct {
 	locals []Local
 	// The current depth of nested scopes. Used to determine when local variables go out of scope.
 	scopeDepth uint16
+	// Set when the last compiled statement was a top level expression statement whose value
+	// was left on the stack, so the VM can echo it when executing OP_END.
+	echoValue bool
+	// The span of the source code currently being compiled, e.g the operator of a binary
+	// expression, recorded in the bytecode's line table for every emitted instruction.
+	span ast.Span
+	// Maps the names of global variables to the identifier token of their declaration.
+	globals map[string]token.Token
+	// Records the declared variables and how each use of a variable was resolved,
+	// for tooling such as the language server.
+	symbols SymbolTable
+	// The semantic errors found while compiling, in the order they are found.
+	// Compilation continues after an error so all of them can be reported at once.
+	errors []error
 }
 
 // NewASTCompiler creates a new AST-to-bytecode compiler.
@@ -54,25 +74,31 @@ func NewASTCompiler() *ASTCompiler {
 			NameConstants: []string{},
 		},
 		initialized: make(map[string]bool),
+		globals:     make(map[string]token.Token),
 		locals:      []Local{},
 		scopeDepth:  0,
 	}
 }
 
 // DumpBytecode writes the compiled bytecode to a file with a `.nic` extension.
-// The bytecode is encoded as hexadecimal so it can be viewed in a text editor.
+// The bytecode is encoded with `Bytecode.MarshalBinary` and written as hexadecimal
+// so it can be viewed in a text editor. The file can be executed with `nilan runC <file>.nic`.
 func (ac *ASTCompiler) DumpBytecode(filePath string) error {
 	if filePath == "" {
 		filePath = "bytecode.nic"
 	} else {
 		filePath = filePath + ".nic"
 	}
+	data, err := ac.bytecode.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("error encoding nilan bytecode: %s", err.Error())
+	}
 	fDescriptor, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating nilan bytecode file: %s", err.Error())
 	}
 
-	encoded := fmt.Sprintf("%x", ac.bytecode.Instructions)
+	encoded := fmt.Sprintf("%x", data)
 	fDescriptor.Write([]byte(encoded))
 	defer fDescriptor.Close()
 	return nil
@@ -97,7 +123,9 @@ func (ac *ASTCompiler) DiassembleBytecode(saveToDisk bool, filePath string) (str
 		case OP_ADD, OP_LESS, OP_LARGER, OP_PRINT, OP_SUBTRACT, OP_DIVIDE,
 			OP_MULTIPLY, OP_NEGATE, OP_NOT, OP_AND, OP_OR,
 			OP_EQUALITY, OP_NOT_EQUAL, OP_LARGER_EQUAL, OP_LESS_EQUAL,
-			OP_END, OP_POP:
+			OP_END, OP_POP, OP_TRY_END, OP_THROW,
+			OP_MODULO, OP_POWER, OP_FLOOR_DIVIDE, OP_BITWISE_AND, OP_BITWISE_OR,
+			OP_BITWISE_XOR, OP_SHIFT_LEFT, OP_SHIFT_RIGHT, OP_BITWISE_NOT, OP_DUP:
 
 			result, err := DiassembleInstruction([]byte{ac.bytecode.Instructions[ip]})
 			if err != nil {
@@ -125,9 +153,37 @@ func (ac *ASTCompiler) DiassembleBytecode(saveToDisk bool, filePath string) (str
 			builder.WriteString("\n")
 			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
 
+		case OP_BUILD_STRING:
+			operand, dia := ac.diassemble3ByteInstruction(ip)
+			result := dia + fmt.Sprintf(", total values to concatenate: %d", operand)
+			builder.WriteString(result)
+			builder.WriteString("\n")
+			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
+
+		case OP_CALL:
+			operand, dia := ac.diassemble3ByteInstruction(ip)
+			result := dia + fmt.Sprintf(", total arguments: %d", operand)
+			builder.WriteString(result)
+			builder.WriteString("\n")
+			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
+
+		case OP_GET_PROPERTY, OP_SET_PROPERTY:
+			operand, dia := ac.diassemble3ByteInstruction(ip)
+			result := dia + fmt.Sprintf(", name: %s", ac.bytecode.ConstantsPool[operand])
+			builder.WriteString(result)
+			builder.WriteString("\n")
+			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
+
+		case OP_IMPORT:
+			operand, dia := ac.diassemble3ByteInstruction(ip)
+			result := dia + fmt.Sprintf(", path: %s", ac.bytecode.ConstantsPool[operand])
+			builder.WriteString(result)
+			builder.WriteString("\n")
+			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
+
 		// Handles all opcodes which store data in the constants pool.
 		// all these opcodes have an operand (index into constants pool) with a width of 2 bytes.
-		case OP_CONSTANT, OP_SET_GLOBAL, OP_GET_GLOBAL:
+		case OP_CONSTANT:
 
 			// The operand is the index into the constants pool where the actual value is stored.
 			operand, dia := ac.diassemble3ByteInstruction(ip)
@@ -137,6 +193,22 @@ func (ac *ASTCompiler) DiassembleBytecode(saveToDisk bool, filePath string) (str
 			builder.WriteString("\n")
 			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
 
+		// The operand of global variable opcodes is the index of the variable's name in the NameConstants pool.
+		case OP_SET_GLOBAL, OP_GET_GLOBAL:
+			operand, dia := ac.diassemble3ByteInstruction(ip)
+			result := dia + fmt.Sprintf(", name: %s", ac.bytecode.NameConstants[operand])
+			builder.WriteString(result)
+			builder.WriteString("\n")
+			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
+
+		case OP_TRY_BEGIN:
+			operand, dia := ac.diassemble3ByteInstruction(ip)
+			handler := ac.bytecode.Handlers[operand]
+			result := dia + fmt.Sprintf(", protects byte indices: %d-%d, handler byte index: %d", handler.Start, handler.End, handler.Target)
+			builder.WriteString(result)
+			builder.WriteString("\n")
+			instructionLength = THREE_BYTE_INSTRUCTION_LENGTH
+
 		case OP_JUMP, OP_JUMP_IF_FALSE:
 
 			operand, dia := ac.diassemble3ByteInstruction(ip)
@@ -166,15 +238,34 @@ func (ac *ASTCompiler) DiassembleBytecode(saveToDisk bool, filePath string) (str
 	return diassembledBytecode, nil
 }
 
-func (ac *ASTCompiler) CompileAST(statements []ast.Stmt) (b Bytecode, err error) {
+// CompileAST compiles the statements to bytecode.
+//
+// Semantic errors, such as using an undefined variable, do not stop the compilation,
+// so all of them are returned, in the order they are found. Errors which make it impossible
+// to continue, e.g exceeding the size of the constants pool, are returned last.
+// The bytecode must not be executed if any error is returned. The state of the compiler is
+// then restored to its state before the call, so a compiler which is reused, e.g by the REPL,
+// compiles the next statements as if the failed ones had never been compiled.
+func (ac *ASTCompiler) CompileAST(statements []ast.Stmt) (b Bytecode, errs []error) {
+	ac.errors = nil
+	before := ac.snapshot()
+	defer func() {
+		if len(errs) > 0 {
+			ac.restore(before)
+		}
+	}()
 	// Recover from any panic that may occur during compilation
 	defer func() {
 		if r := recover(); r != nil {
 			switch v := r.(type) {
 			case SemanticError:
-				err = v
+				errs = append(ac.errors, v)
 			case DeveloperError:
-				err = v
+				errs = append(ac.errors, v)
+			default:
+				// NOTE: Any other panic is a bug in the compiler, reporting it as an error
+				// instead of silently dropping it.
+				errs = append(ac.errors, DeveloperError{Message: fmt.Sprint(v)})
 			}
 		}
 	}()
@@ -185,8 +276,14 @@ func (ac *ASTCompiler) CompileAST(statements []ast.Stmt) (b Bytecode, err error)
 			ac.bytecode.Instructions = ac.bytecode.Instructions[:len(ac.bytecode.Instructions)-1]
 		}
 	}
+	// The value echoed by the previous compilation is still on the stack, so it is popped
+	// before executing the new statements.
+	if ac.echoValue {
+		ac.emit(OP_POP)
+		ac.echoValue = false
+	}
 
-	for _, stmt := range statements {
+	for i, stmt := range statements {
 		func() {
 			//NOTE: Catch panics per statement to avoid aborting the whole loop
 			defer func() {
@@ -194,22 +291,110 @@ func (ac *ASTCompiler) CompileAST(statements []ast.Stmt) (b Bytecode, err error)
 					panic(r)
 				}
 			}()
+			// NOTE: The value of the last top level expression statement is not popped,
+			// so OP_END can echo it. For example, evaluating `2 + 2` in the REPL prints `4`.
+			if exprStmt, ok := stmt.(ast.ExpressionStmt); ok && i == len(statements)-1 {
+				exprStmt.Expression.Accept(ac)
+				ac.echoValue = true
+				return
+			}
 			stmt.Accept(ac)
 		}()
 	}
 
 	ac.emit(OP_END)
+	if len(ac.errors) > 0 {
+		return ac.bytecode, ac.errors
+	}
 	return ac.bytecode, nil
 }
 
-// VisitBinary handles binary expressions (arithmetic operators: +, -, *, /)
+// compilerState is a snapshot of the state of the ASTCompiler which `CompileAST` restores
+// when the statements fail to compile.
+//
+// NOTE: The bytecode's slices are only ever appended to, so the length of each slice is enough
+// to restore it, except for the OP_END left by the previous compilation which is overwritten by
+// the next instruction, so the last instruction is kept. The symbol table is not restored, tools
+// such as the language server use the symbols resolved before an error.
+type compilerState struct {
+	instructions int
+	last         byte
+	constants    int
+	names        int
+	handlers     int
+	lines        int
+	initialized  map[string]bool
+	globals      map[string]token.Token
+	locals       []Local
+	scopeDepth   uint16
+	echoValue    bool
+	span         ast.Span
+}
+
+// snapshot returns the current state of the compiler.
+func (ac *ASTCompiler) snapshot() compilerState {
+	initialized := make(map[string]bool, len(ac.initialized))
+	for name, ok := range ac.initialized {
+		initialized[name] = ok
+	}
+	globals := make(map[string]token.Token, len(ac.globals))
+	for name, declaration := range ac.globals {
+		globals[name] = declaration
+	}
+	var last byte
+	if len(ac.bytecode.Instructions) > 0 {
+		last = ac.bytecode.Instructions[len(ac.bytecode.Instructions)-1]
+	}
+	return compilerState{
+		instructions: len(ac.bytecode.Instructions),
+		last:         last,
+		constants:    len(ac.bytecode.ConstantsPool),
+		names:        len(ac.bytecode.NameConstants),
+		handlers:     len(ac.bytecode.Handlers),
+		lines:        len(ac.bytecode.Lines),
+		initialized:  initialized,
+		globals:      globals,
+		locals:       append([]Local{}, ac.locals...),
+		scopeDepth:   ac.scopeDepth,
+		echoValue:    ac.echoValue,
+		span:         ac.span,
+	}
+}
+
+// restore restores the state of the compiler from a snapshot.
+func (ac *ASTCompiler) restore(state compilerState) {
+	ac.bytecode.Instructions = ac.bytecode.Instructions[:state.instructions]
+	if state.instructions > 0 {
+		ac.bytecode.Instructions[state.instructions-1] = state.last
+	}
+	ac.bytecode.ConstantsPool = ac.bytecode.ConstantsPool[:state.constants]
+	ac.bytecode.NameConstants = ac.bytecode.NameConstants[:state.names]
+	ac.bytecode.Handlers = ac.bytecode.Handlers[:state.handlers]
+	ac.bytecode.Lines = ac.bytecode.Lines[:state.lines]
+	ac.initialized = state.initialized
+	ac.globals = state.globals
+	ac.locals = state.locals
+	ac.scopeDepth = state.scopeDepth
+	ac.echoValue = state.echoValue
+	ac.span = state.span
+}
+
+// VisitBinary handles binary expressions (arithmetic operators: +, -, *, /, //, %, **,
+// bitwise operators: &, |, ^, <<, >> and comparisons)
 func (ac *ASTCompiler) VisitBinary(binary ast.Binary) any {
 
 	// NOTE: Left expression is compiled first to ensure correct evaluation order
 	binary.Left.Accept(ac)
 	binary.Right.Accept(ac)
+	ac.emitBinaryOperator(binary.Operator)
+	return nil
+}
 
-	switch binary.Operator.TokenType {
+// emitBinaryOperator emits the instruction computing a binary operation on the two values on
+// top of the stack.
+func (ac *ASTCompiler) emitBinaryOperator(operator token.Token) {
+	ac.setSpan(operator)
+	switch operator.TokenType {
 	case token.ADD:
 		ac.emit(OP_ADD)
 	case token.SUB:
@@ -218,6 +403,22 @@ func (ac *ASTCompiler) VisitBinary(binary ast.Binary) any {
 		ac.emit(OP_MULTIPLY)
 	case token.DIV:
 		ac.emit(OP_DIVIDE)
+	case token.FLOOR_DIV:
+		ac.emit(OP_FLOOR_DIVIDE)
+	case token.MOD:
+		ac.emit(OP_MODULO)
+	case token.POW:
+		ac.emit(OP_POWER)
+	case token.BIT_AND:
+		ac.emit(OP_BITWISE_AND)
+	case token.BIT_OR:
+		ac.emit(OP_BITWISE_OR)
+	case token.BIT_XOR:
+		ac.emit(OP_BITWISE_XOR)
+	case token.SHIFT_LEFT:
+		ac.emit(OP_SHIFT_LEFT)
+	case token.SHIFT_RIGHT:
+		ac.emit(OP_SHIFT_RIGHT)
 
 	case token.EQUAL_EQUAL:
 		ac.emit(OP_EQUALITY)
@@ -232,20 +433,21 @@ func (ac *ASTCompiler) VisitBinary(binary ast.Binary) any {
 	case token.NOT_EQUAL:
 		ac.emit(OP_NOT_EQUAL)
 	}
-
-	return nil
 }
 
-// VisitUnary handles unary expressions (operators: -, !)
+// VisitUnary handles unary expressions (operators: -, !, ~)
 func (ac *ASTCompiler) VisitUnary(unary ast.Unary) any {
 
 	unary.Right.Accept(ac)
 
+	ac.setSpan(unary.Operator)
 	switch unary.Operator.TokenType {
 	case token.SUB:
 		ac.emit(OP_NEGATE)
 	case token.BANG:
 		ac.emit(OP_NOT)
+	case token.BIT_NOT:
+		ac.emit(OP_BITWISE_NOT)
 	}
 	return nil
 }
@@ -257,6 +459,92 @@ func (ac *ASTCompiler) VisitLiteral(literal ast.Literal) any {
 	return nil
 }
 
+// VisitInterpolation compiles a string containing interpolated expressions by compiling its
+// parts in order and emitting an OP_BUILD_STRING instruction concatenating them. Empty text
+// between the interpolated expressions is skipped.
+//
+// For example, `"a${x}"` is compiled to:
+//
+//	OP_CONSTANT "a"
+//	OP_GET_GLOBAL x
+//	OP_BUILD_STRING 2
+func (ac *ASTCompiler) VisitInterpolation(interpolation ast.Interpolation) any {
+	total := 0
+	for _, part := range interpolation.Parts {
+		if literal, ok := part.(ast.Literal); ok && literal.Value == "" {
+			continue
+		}
+		part.Accept(ac)
+		total++
+	}
+	if total > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too many interpolated expressions, a string can only contain %d", math.MaxUint16/2),
+		})
+	}
+	ac.emit(OP_BUILD_STRING, total)
+	return nil
+}
+
+// VisitCall compiles a function call by compiling the callee and its arguments in order, and
+// emitting an OP_CALL instruction whose operand is the number of arguments.
+//
+// For example, `max(a, 1)` is compiled to:
+//
+//	OP_GET_GLOBAL max
+//	OP_GET_GLOBAL a
+//	OP_CONSTANT 1
+//	OP_CALL 2
+func (ac *ASTCompiler) VisitCall(call ast.Call) any {
+	call.Callee.Accept(ac)
+	for _, argument := range call.Arguments {
+		argument.Accept(ac)
+	}
+	ac.setSpan(call.Paren)
+	ac.emit(OP_CALL, len(call.Arguments))
+	return nil
+}
+
+// VisitGet compiles the access of a property, such as `math.pi`, by compiling the object and
+// emitting an OP_GET_PROPERTY instruction whose operand is the index of the property's name in
+// the constants pool.
+func (ac *ASTCompiler) VisitGet(get ast.Get) any {
+	get.Object.Accept(ac)
+	ac.setSpan(get.Name)
+	ac.emit(OP_GET_PROPERTY, ac.makeConstant(get.Name.Lexeme))
+	return nil
+}
+
+// VisitSet compiles the assignment of a field, such as `p.x = 3`, by compiling the object and
+// the value and emitting an OP_SET_PROPERTY instruction whose operand is the index of the field's
+// name in the constants pool.
+//
+// The object of a compound assignment is only evaluated once, `p.x += 1` compiles to:
+//
+//	OP_GET_GLOBAL p
+//	OP_DUP
+//	OP_GET_PROPERTY "x"
+//	OP_CONSTANT 1
+//	OP_ADD
+//	OP_SET_PROPERTY "x"
+func (ac *ASTCompiler) VisitSet(set ast.Set) any {
+	set.Object.Accept(ac)
+	name := ac.makeConstant(set.Name.Lexeme)
+	if operator, ok := set.BinaryOperator(); ok {
+		ac.setSpan(set.Name)
+		ac.emit(OP_DUP)
+		ac.emit(OP_GET_PROPERTY, name)
+		set.Value.Accept(ac)
+		ac.emitBinaryOperator(operator)
+	} else {
+		set.Value.Accept(ac)
+	}
+	ac.setSpan(set.Name)
+	ac.emit(OP_SET_PROPERTY, name)
+	return nil
+}
+
 // VisitGrouping handles parenthesized expressions
 func (ac *ASTCompiler) VisitGrouping(grouping ast.Grouping) any {
 	// Recursively compile the inner expression
@@ -276,12 +564,16 @@ func (ac *ASTCompiler) VisitGrouping(grouping ast.Grouping) any {
 func (ac *ASTCompiler) VisitVariableExpression(variable ast.Variable) any {
 
 	identifier := variable.Name.Lexeme
+	ac.setSpan(variable.Name)
 
 	slotIndex := ac.resolveLocal(identifier)
 	if slotIndex != -1 {
+		ac.addReference(variable.Name, ac.locals[slotIndex].declaration)
 		if !ac.locals[slotIndex].initialized {
-			panic(SemanticError{
+			ac.addError(SemanticError{
+				Code:    diagnostics.CodeUninitialised,
 				Message: fmt.Sprintf("Cant access uninitialised variable '%s'", identifier),
+				Token:   variable.Name,
 			})
 		}
 		ac.emit(OP_GET_LOCAL, slotIndex)
@@ -289,14 +581,27 @@ func (ac *ASTCompiler) VisitVariableExpression(variable ast.Variable) any {
 	}
 
 	globalIndex := ac.resolveGlobal(identifier)
+	if globalIndex == -1 && stdlib.IsGlobal(identifier) {
+		ac.emit(OP_GET_GLOBAL, ac.addBuiltinName(identifier))
+		return nil
+	}
 	if globalIndex == -1 {
-		panic(SemanticError{
+		ac.addError(SemanticError{
+			Code:    diagnostics.CodeUndefined,
 			Message: fmt.Sprintf("name '%s' is not defined", identifier),
+			Token:   variable.Name,
 		})
+		// NOTE: null is pushed in place of the variable's value so the rest of the
+		// program can still be compiled.
+		ac.addConstant(nil)
+		return nil
 	}
+	ac.addReference(variable.Name, ac.globals[identifier])
 	if !ac.initialized[identifier] {
-		panic(SemanticError{
+		ac.addError(SemanticError{
+			Code:    diagnostics.CodeUninitialised,
 			Message: fmt.Sprintf("Cant access uninitialised variable '%s'", identifier),
+			Token:   variable.Name,
 		})
 	}
 
@@ -320,10 +625,18 @@ func (ac *ASTCompiler) VisitAssignExpression(assign ast.Assign) any {
 	// compile the right hand side expression first.
 	// This ensures that the correct value is on top of the stack when the OP_SET_LOCAL
 	// or OP_SET_GLOBAL instruction is emitted.
-	assign.Value.Accept(ac)
+	// NOTE: The value of a compound assignment such as `a += 1` is the expression `a + 1`.
+	// Only `1` is compiled if `a` is not defined, so the error is reported once below.
+	value := assign.AssignedValue()
+	if ac.resolveLocal(name) == -1 && ac.resolveGlobal(name) == -1 {
+		value = assign.Value
+	}
+	value.Accept(ac)
 
+	ac.setSpan(assign.Name)
 	slotIndex := ac.resolveLocal(name)
 	if slotIndex != -1 {
+		ac.addReference(assign.Name, ac.locals[slotIndex].declaration)
 		ac.locals[slotIndex].initialized = true
 		ac.emit(OP_SET_LOCAL, slotIndex)
 		return nil
@@ -331,10 +644,15 @@ func (ac *ASTCompiler) VisitAssignExpression(assign ast.Assign) any {
 
 	globalIndex := ac.resolveGlobal(name)
 	if globalIndex == -1 {
-		panic(SemanticError{
+		// NOTE: The assigned value is left on the stack, as if the assignment succeeded.
+		ac.addError(SemanticError{
+			Code:    diagnostics.CodeUndefined,
 			Message: fmt.Sprintf("name '%s' is not defined", name),
+			Token:   assign.Name,
 		})
+		return nil
 	}
+	ac.addReference(assign.Name, ac.globals[name])
 
 	ac.initialized[name] = true
 	ac.emit(OP_SET_GLOBAL, globalIndex)
@@ -352,17 +670,23 @@ func (ac *ASTCompiler) VisitAssignExpression(assign ast.Assign) any {
 func (ac *ASTCompiler) VisitVarStmt(varStmt ast.VarStmt) any {
 
 	variableName := varStmt.Name.Lexeme
+	ac.setSpan(varStmt.Name)
 	if ac.scopeDepth == 0 {
 		// Handles global variable declaration.
-		index := ac.addNameConstant(variableName)
+		index := ac.addNameConstant(varStmt.Name)
+		ac.addSymbol(varStmt.Name, true)
 		if varStmt.Initializer != nil {
 			varStmt.Initializer.Accept(ac)
+			ac.setSpan(varStmt.Name)
 			ac.emit(OP_SET_GLOBAL, index)
+			// OP_SET_GLOBAL leaves the value on the stack as assignments are expressions.
+			ac.emit(OP_POP)
 		}
 		ac.initialized[variableName] = varStmt.Initializer != nil
 	} else {
 		// Handles local variable declaration.
-		ac.declareLocal(variableName)
+		ac.declareLocal(varStmt.Name)
+		ac.addSymbol(varStmt.Name, false)
 		if varStmt.Initializer != nil {
 			varStmt.Initializer.Accept(ac)
 		} else {
@@ -381,6 +705,7 @@ func (ac *ASTCompiler) VisitLogicalExpression(logical ast.Logical) any {
 
 	// left expression is compiled first to ensure correct evaluation order and short-circuiting behaviour.
 	logical.Left.Accept(ac)
+	ac.setSpan(logical.Operator)
 
 	switch logical.Operator.TokenType {
 	case token.OR:
@@ -412,9 +737,11 @@ func (ac *ASTCompiler) VisitLogicalExpression(logical ast.Logical) any {
 	return nil
 }
 
-// VisitExpressionStmt is not directly called; handled by CompileAST
+// VisitExpressionStmt compiles the expression and emits OP_POP to discard its value,
+// keeping the stack balanced. The last top level expression statement is handled by CompileAST.
 func (ac *ASTCompiler) VisitExpressionStmt(exprStmt ast.ExpressionStmt) any {
 	exprStmt.Expression.Accept(ac)
+	ac.emit(OP_POP)
 	return nil
 }
 
@@ -459,32 +786,30 @@ func (ac *ASTCompiler) VisitIfStmt(ifStmt ast.IfStmt) any {
 	// For example, the intructions would now be something like: [..., OP_JUMP_IF_FALSE,  0x00, 0x00]
 	// where `0x00, 0x0` are the placeholder operand bytes.
 
+	// Emits `OP_POP` so the VM pops the condition expression's value from the stack
+	// before executing the "then" branch. This keeps the stack slots of any local
+	// variables declared in the branch aligned with their slot indices.
+	ac.emit(OP_POP)
 	ifStmt.Then.Accept(ac)
 
-	if ifStmt.Else != nil {
-		// If there is an "else" branch, emit a jump instruction to skip over it after executing the "then" branch.
-		jumpPatch := ac.emitPlaceholderJump(OP_JUMP)
+	// Emit a jump instruction to skip over the "else" branch after executing the "then" branch.
+	jumpPatch := ac.emitPlaceholderJump(OP_JUMP)
 
-		// Patch the operand of the OP_JUMP_IF_FALSE instruction defined at the beginning.
-		// This allows the VM to correctly jump to the start of the "else" branch, if the "then"
-		// branch condition evaluates false.
-		elsePos := len(ac.bytecode.Instructions)
-		ac.patchJump(jumpIfFalsePatch, elsePos)
+	// Patch the operand of the OP_JUMP_IF_FALSE instruction defined at the beginning.
+	// This allows the VM to correctly jump to the start of the "else" branch, or to the
+	// end of the if statement if there is no "else" branch, if the condition evaluates false.
+	elsePos := len(ac.bytecode.Instructions)
+	ac.patchJump(jumpIfFalsePatch, elsePos)
 
+	// The condition expression's value also needs to be popped when the "then" branch is skipped.
+	ac.emit(OP_POP)
+	if ifStmt.Else != nil {
 		ifStmt.Else.Accept(ac)
-
-		endPos := len(ac.bytecode.Instructions)
-		// Patch the operand of `OP_JUMP` so the VM can jump to the end of the "else" branch.
-		ac.patchJump(jumpPatch, endPos)
-	} else {
-		// If there is no "else" branch, patch the OP_JUMP_IF_FALSE so that
-		// control jumps to the instruction after the "then" branch when
-		// the condition is false.
-		afterPos := len(ac.bytecode.Instructions)
-		ac.patchJump(jumpIfFalsePatch, afterPos)
 	}
-	// Emits `OP_POP` so the VM can pop the condition expression's value from the stack.
-	ac.emit(OP_POP)
+
+	endPos := len(ac.bytecode.Instructions)
+	// Patch the operand of `OP_JUMP` so the VM can jump to the end of the if statement.
+	ac.patchJump(jumpPatch, endPos)
 	return nil
 }
 
@@ -497,12 +822,14 @@ func (ac *ASTCompiler) VisitWhileStmt(whileStmt ast.WhileStmt) any {
 
 	jumpIfFalsePatch := ac.emitPlaceholderJump(OP_JUMP_IF_FALSE)
 
+	// pop the condition expression's value before executing the loop body.
+	ac.emit(OP_POP)
+
 	// compile the loop body
 	whileStmt.Body.Accept(ac)
 
 	// After compiling the loop body, we need to emit a jump instruction
 	// so the VM can jump back to the start of the loop condition.
-	ac.emit(OP_POP)
 	ac.emit(OP_JUMP, loopstartPos)
 
 	// if the while condition is false, the VM needs to jump to the end of the loop body,
@@ -514,6 +841,176 @@ func (ac *ASTCompiler) VisitWhileStmt(whileStmt ast.WhileStmt) any {
 	return nil
 }
 
+// VisitTryStmt compiles a try statement by registering exception handlers in the bytecode's
+// handler table. For example `try { A } catch (e) { B } finally { C }` is compiled to:
+//
+//	OP_TRY_BEGIN <finally handler>
+//	OP_TRY_BEGIN <catch handler>
+//	A
+//	OP_TRY_END
+//	OP_JUMP <end of catch>
+//	B                                 <- catch handler target, the thrown value is the local `e`
+//	OP_SCOPE_EXIT 1                   <- end of catch, pops `e`
+//	OP_TRY_END
+//	C
+//	OP_JUMP <end>
+//	C                                 <- finally handler target, the thrown value is a hidden local
+//	OP_GET_LOCAL <hidden local>
+//	OP_THROW                          <- re-throws the value after executing the finally block
+//
+// When an error is raised, the VM pops the handler registered last, removes everything
+// pushed onto its stack since the handler was registered (including local variables,
+// similar to OP_SCOPE_EXIT), pushes the thrown value and jumps to the handler's target.
+func (ac *ASTCompiler) VisitTryStmt(tryStmt ast.TryStmt) any {
+	ac.setSpan(tryStmt.Keyword)
+
+	finallyHandler := -1
+	if tryStmt.Finally != nil {
+		finallyHandler = ac.emitTryBegin()
+	}
+
+	if tryStmt.Catch != nil {
+		catchHandler := ac.emitTryBegin()
+		tryStmt.Body.Accept(ac)
+		ac.emitTryEnd(catchHandler)
+		jumpPatch := ac.emitPlaceholderJump(OP_JUMP)
+
+		ac.patchHandler(catchHandler, len(ac.bytecode.Instructions))
+		ac.compileHandlerScope(tryStmt.CatchName, func() {
+			ac.addSymbol(tryStmt.CatchName, false)
+			tryStmt.Catch.Accept(ac)
+		})
+		popped := ac.endScope()
+		ac.emit(OP_SCOPE_EXIT, popped)
+		ac.patchJump(jumpPatch, len(ac.bytecode.Instructions))
+	} else {
+		tryStmt.Body.Accept(ac)
+	}
+
+	if tryStmt.Finally != nil {
+		ac.setSpan(tryStmt.Keyword)
+		ac.emitTryEnd(finallyHandler)
+		tryStmt.Finally.Accept(ac)
+		jumpPatch := ac.emitPlaceholderJump(OP_JUMP)
+
+		ac.patchHandler(finallyHandler, len(ac.bytecode.Instructions))
+		// NOTE: The name can not be referenced from Nilan code, as it is not a valid identifier.
+		ac.compileHandlerScope(token.Token{TokenType: token.IDENTIFIER, Lexeme: "<exception>"}, func() {
+			tryStmt.Finally.Accept(ac)
+			ac.setSpan(tryStmt.Keyword)
+			ac.emit(OP_GET_LOCAL, ac.resolveLocal("<exception>"))
+			ac.emit(OP_THROW)
+		})
+		// NOTE: OP_SCOPE_EXIT is not needed as OP_THROW never continues to the next instruction.
+		ac.endScope()
+		ac.patchJump(jumpPatch, len(ac.bytecode.Instructions))
+	}
+	return nil
+}
+
+// VisitThrowStmt compiles a throw statement by compiling the thrown expression
+// and emitting OP_THROW.
+func (ac *ASTCompiler) VisitThrowStmt(throwStmt ast.ThrowStmt) any {
+	throwStmt.Value.Accept(ac)
+	ac.setSpan(throwStmt.Keyword)
+	ac.emit(OP_THROW)
+	return nil
+}
+
+// VisitImportStmt compiles an import statement by emitting an OP_IMPORT instruction, whose operand
+// is the index of the module's path in the constants pool, and storing the module in a global variable.
+//
+// For example, `import "lib.ni" as lib` compiles to:
+//
+//	OP_IMPORT 0
+//	OP_SET_GLOBAL 0
+//	OP_POP
+//
+// Modules can only be imported at the top level of a file.
+func (ac *ASTCompiler) VisitImportStmt(importStmt ast.ImportStmt) any {
+	if ac.scopeDepth > 0 {
+		ac.addError(SemanticError{
+			Code:    diagnostics.CodeSemantic,
+			Message: "Modules can only be imported at the top level of a file",
+			Token:   importStmt.Keyword,
+		})
+		return nil
+	}
+	ac.setSpan(importStmt.Keyword)
+	index := ac.addNameConstant(importStmt.Name)
+	ac.addSymbol(importStmt.Name, true)
+	ac.emit(OP_IMPORT, ac.makeConstant(importStmt.Path.Literal))
+	ac.emit(OP_SET_GLOBAL, index)
+	ac.emit(OP_POP)
+	ac.initialized[importStmt.Name.Lexeme] = true
+	return nil
+}
+
+// VisitStructStmt compiles a struct declaration like the declaration of a variable whose value
+// is the struct, which is stored in the constants pool.
+//
+// For example, `struct Point { x, y }` compiles to:
+//
+//	OP_CONSTANT <struct Point>
+//	OP_SET_GLOBAL Point
+//	OP_POP
+func (ac *ASTCompiler) VisitStructStmt(structStmt ast.StructStmt) any {
+	fields := make([]string, len(structStmt.Fields))
+	for i, field := range structStmt.Fields {
+		fields[i] = field.Lexeme
+	}
+	structure := &value.Struct{Name: structStmt.Name.Lexeme, Fields: fields}
+	return ac.VisitVarStmt(ast.VarStmt{
+		Name:        structStmt.Name,
+		Initializer: ast.Literal{Value: structure, Span: structStmt.Span},
+		Span:        structStmt.Span,
+	})
+}
+
+// compileHandlerScope begins a new scope for the target of an exception handler, where the
+// thrown value pushed by the VM is bound to a local variable with the provided name, and calls
+// compileBody. The caller must end the scope.
+func (ac *ASTCompiler) compileHandlerScope(name token.Token, compileBody func()) {
+	ac.beginScope()
+	ac.declareLocal(name)
+	ac.defineLocal()
+	compileBody()
+}
+
+// emitTryBegin adds an exception handler to the handler table and emits an OP_TRY_BEGIN
+// instruction registering it. It returns the index of the handler, which can later be passed to
+// `emitTryEnd` and `patchHandler` once the end of the protected instructions and the handler's
+// target are known.
+func (ac *ASTCompiler) emitTryBegin() int {
+	if len(ac.bytecode.Handlers) > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too many try statements, only %d can be declared", math.MaxUint16+1),
+		})
+	}
+	index := len(ac.bytecode.Handlers)
+	ac.emit(OP_TRY_BEGIN, index)
+	ac.bytecode.Handlers = append(ac.bytecode.Handlers, ExceptionHandler{Start: len(ac.bytecode.Instructions)})
+	return index
+}
+
+// emitTryEnd emits an OP_TRY_END instruction which ends the instructions protected by the handler.
+func (ac *ASTCompiler) emitTryEnd(handler int) {
+	ac.bytecode.Handlers[handler].End = len(ac.bytecode.Instructions)
+	ac.emit(OP_TRY_END)
+}
+
+// patchHandler sets the target the VM jumps to when an error is caught by the handler.
+func (ac *ASTCompiler) patchHandler(handler int, targetPos int) {
+	if targetPos > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too much code to jump over, jump target %d exceeds %d bytes", targetPos, math.MaxUint16),
+		})
+	}
+	ac.bytecode.Handlers[handler].Target = targetPos
+}
+
 // patchjump overwrites a jump instruction's operand with the actual correct byte offset.
 // When compiling if statements, its not possible to know the else branch (or the statement after
 // the if) will be until the then-branch is compiled. Jump instructions are emmited with placeholder operands,
@@ -530,6 +1027,13 @@ func (ac *ASTCompiler) VisitWhileStmt(whileStmt ast.WhileStmt) any {
 // After patching: [..., OP_JUMP_IF_FALSE, 0x00, 0x0A, ...] (jump instruction now correctly jumps to index 20)
 func (ac *ASTCompiler) patchJump(jumpPos int, targetPos int) {
 
+	if targetPos > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too much code to jump over, jump target %d exceeds %d bytes", targetPos, math.MaxUint16),
+		})
+	}
+
 	operandPos := jumpPos + OPCODE_TOTAL_BYTES
 
 	instruction := make([]byte, 2)
@@ -545,26 +1049,76 @@ func (ac *ASTCompiler) patchJump(jumpPos int, targetPos int) {
 // addConstant appends a value to the constant pool and emits an OP_CONSTANT instruction.
 // The operand of the instruction will be its index in the constants pool.
 func (ac *ASTCompiler) addConstant(value any) {
+	ac.emit(OP_CONSTANT, ac.makeConstant(value))
+}
+
+// makeConstant appends a value to the constant pool and returns its index.
+func (ac *ASTCompiler) makeConstant(value any) int {
+	if len(ac.bytecode.ConstantsPool) > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too many constants, the constants pool can only hold %d values", math.MaxUint16+1),
+		})
+	}
 	ac.bytecode.ConstantsPool = append(ac.bytecode.ConstantsPool, value)
-	index := len(ac.bytecode.ConstantsPool) - 1
-	ac.emit(OP_CONSTANT, index)
+	return len(ac.bytecode.ConstantsPool) - 1
 }
 
-// addNameConstant adds a variable name to the NameConstants pool
+// addNameConstant adds the name of a global variable to the NameConstants pool
 // and returns its index.
-func (ac *ASTCompiler) addNameConstant(value string) int {
-
-	for _, name := range ac.bytecode.NameConstants {
+func (ac *ASTCompiler) addNameConstant(variable token.Token) int {
+	value := variable.Lexeme
+	for i, name := range ac.bytecode.NameConstants {
+		if _, declared := ac.globals[value]; name == value && !declared {
+			// NOTE: The name of a builtin which was used before, it is redefined by the declaration.
+			ac.globals[value] = variable
+			return i
+		}
 		if name == value {
-			panic(SemanticError{
+			// NOTE: The redefinition is compiled as an assignment to the existing variable.
+			ac.addError(SemanticError{
+				Code:    diagnostics.CodeRedefinition,
 				Message: fmt.Sprintf("Redefinition of variable '%s'", value),
+				Token:   variable,
 			})
+			return i
 		}
 	}
+	if len(ac.bytecode.NameConstants) > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too many global variables, only %d can be declared", math.MaxUint16+1),
+		})
+	}
 	ac.bytecode.NameConstants = append(ac.bytecode.NameConstants, value)
+	ac.globals[value] = variable
+	return len(ac.bytecode.NameConstants) - 1
+}
+
+// addBuiltinName adds the name of a global variable defined by the standard library, such as `sqrt`,
+// to the NameConstants pool and returns its index. Unlike addNameConstant, the name is not declared,
+// so a later declaration of a variable with the same name is not a redefinition.
+func (ac *ASTCompiler) addBuiltinName(name string) int {
+	for i, n := range ac.bytecode.NameConstants {
+		if n == name {
+			return i
+		}
+	}
+	if len(ac.bytecode.NameConstants) > math.MaxUint16 {
+		panic(SemanticError{
+			Code:    diagnostics.CodeLimit,
+			Message: fmt.Sprintf("Too many global variables, only %d can be declared", math.MaxUint16+1),
+		})
+	}
+	ac.bytecode.NameConstants = append(ac.bytecode.NameConstants, name)
 	return len(ac.bytecode.NameConstants) - 1
 }
 
+// addError records a semantic error after which compilation can continue.
+func (ac *ASTCompiler) addError(err SemanticError) {
+	ac.errors = append(ac.errors, err)
+}
+
 // emit constructs a bytecode instruction and appends it to the instruction stream
 func (ac *ASTCompiler) emit(opcode Opcode, operands ...int) {
 	instruction, err := AssembleInstruction(opcode, operands...)
@@ -574,9 +1128,28 @@ func (ac *ASTCompiler) emit(opcode Opcode, operands ...int) {
 		// which would only be raised during development.
 		panic(err.Error())
 	}
+	ac.addLine()
 	ac.bytecode.Instructions = append(ac.bytecode.Instructions, instruction...)
 }
 
+// setSpan sets the source code of the instructions emitted next to the token.
+func (ac *ASTCompiler) setSpan(tok token.Token) {
+	ac.span = ast.TokenSpan(tok)
+}
+
+// addLine adds an entry to the line table if the source code of the next instruction differs
+// from the source code of the previous instruction.
+func (ac *ASTCompiler) addLine() {
+	if ac.span.Start.Line == 0 {
+		return
+	}
+	lines := ac.bytecode.Lines
+	if len(lines) > 0 && lines[len(lines)-1].Start == ac.span.Start && lines[len(lines)-1].End == ac.span.End {
+		return
+	}
+	ac.bytecode.Lines = append(lines, LineEntry{Offset: len(ac.bytecode.Instructions), Start: ac.span.Start, End: ac.span.End})
+}
+
 // emitPlaceholderJump emits a jump instruction with the specified opcode and a placeholder operand (0).
 // It returns the position in the bytecode where the jump instruction was emitted,
 // which can later be passed to `patchJump` to update the operand with
@@ -607,10 +1180,12 @@ func (ac *ASTCompiler) endScope() int {
 	return count
 }
 
-// declareLocal adds a local variable name, checking for same-scope duplicates
-// and assigns it a slot index for the VM to access it.
-// It panics if there is a duplicate variable declaration in the same scope.
-func (ac *ASTCompiler) declareLocal(name string) {
+// declareLocal adds a local variable declared by the identifier token, checking for same-scope
+// duplicates and assigns it a slot index for the VM to access it.
+// A duplicate variable declaration in the same scope is reported as an error, but the variable is
+// still declared, so the stack slots of the following variables are correct.
+func (ac *ASTCompiler) declareLocal(identifier token.Token) {
+	name := identifier.Lexeme
 
 	for i := len(ac.locals) - 1; i >= 0; i-- {
 
@@ -620,9 +1195,12 @@ func (ac *ASTCompiler) declareLocal(name string) {
 			break
 		}
 		if ac.locals[i].name == name {
-			panic(SemanticError{
+			ac.addError(SemanticError{
+				Code:    diagnostics.CodeRedefinition,
 				Message: fmt.Sprintf("Redefinition of variable '%s'", name),
+				Token:   identifier,
 			})
+			break
 		}
 	}
 
@@ -632,6 +1210,7 @@ func (ac *ASTCompiler) declareLocal(name string) {
 		depth:       ac.scopeDepth,
 		initialized: false,
 		slot:        slot,
+		declaration: identifier,
 	}
 	ac.locals = append(ac.locals, local)
 
@@ -658,6 +1237,11 @@ func (ac *ASTCompiler) resolveLocal(name string) int {
 // resolveGlobal checks if a variable name exists in the global scope and returns its index in the NameConstants pool.
 // It returns -1 if the variable is not found in the global scope.
 func (ac ASTCompiler) resolveGlobal(name string) int {
+	// NOTE: The names of builtins are added to the NameConstants pool when they are used,
+	// but they are not declared variables.
+	if _, declared := ac.globals[name]; !declared {
+		return -1
+	}
 	for i, n := range ac.bytecode.NameConstants {
 		if n == name {
 			return i
@@ -3,6 +3,7 @@ package compiler
 import (
 	"encoding/binary"
 	"fmt"
+	"nilan/token"
 )
 
 // Represents the definition of the `Bytecode`
@@ -17,6 +18,55 @@ type Bytecode struct {
 
 	// an array containing all the identifier names; varibale names, function names... etc
 	NameConstants []string
+
+	// The exception handlers of all `try` statements. The operand of an OP_TRY_BEGIN
+	// instruction is the index of its handler in this table.
+	Handlers []ExceptionHandler
+
+	// Maps byte offsets in the instructions array to the source code they were compiled from,
+	// so errors raised by the VM can report their location. Entries are sorted by their offset.
+	Lines []LineEntry
+}
+
+// ExceptionHandler represents an entry in the handler table of the `Bytecode`.
+// The instructions between `Start` and `End` are protected by the handler. If an error
+// is raised while executing them, the VM unwinds the stack and jumps to `Target`.
+type ExceptionHandler struct {
+	// The byte offset of the first protected instruction, which follows OP_TRY_BEGIN.
+	Start int
+	// The byte offset of the OP_TRY_END instruction which ends the protected instructions.
+	End int
+	// The byte offset of the first instruction of the handler, for example the `catch` block.
+	Target int
+}
+
+// LineEntry records that the instructions starting at the byte offset `Offset`, up to the
+// offset of the next entry, were compiled from the source code between `Start` and `End`,
+// e.g the operator of a binary expression.
+type LineEntry struct {
+	Offset int
+	Start  token.Position
+	End    token.Position
+}
+
+// SpanAt returns the span of the source code the instruction at the byte offset was compiled
+// from, or empty positions if it is unknown.
+func (b Bytecode) SpanAt(offset int) (token.Position, token.Position) {
+	var start, end token.Position
+	for _, entry := range b.Lines {
+		if entry.Offset > offset {
+			break
+		}
+		start, end = entry.Start, entry.End
+	}
+	return start, end
+}
+
+// LineAt returns the source line the instruction at the byte offset was compiled from,
+// or 0 if it is unknown.
+func (b Bytecode) LineAt(offset int) int {
+	start, _ := b.SpanAt(offset)
+	return start.Line
 }
 
 type Opcode byte
@@ -95,6 +145,57 @@ const (
 	// This opcode is emitted at the end of a block statement, and its operand is the number of
 	// local variables to pop.
 	OP_SCOPE_EXIT Opcode = iota
+
+	// OP_TRY_BEGIN registers an exception handler when entering a `try` block. Its operand is the index of the
+	// handler in the `Handlers` table of the bytecode.
+	OP_TRY_BEGIN Opcode = iota
+
+	// OP_TRY_END removes the most recently registered exception handler when leaving a `try` block.
+	OP_TRY_END Opcode = iota
+
+	// OP_THROW pops a value from the VM's stack and raises it as an error.
+	OP_THROW Opcode = iota
+
+	// OP_BUILD_STRING pops the number of values given by its operand from the VM's stack and pushes
+	// the string concatenating them in order, each converted to a string the same way OP_PRINT does.
+	// This opcode is emitted for strings containing interpolated expressions.
+	OP_BUILD_STRING Opcode = iota
+
+	// Arithmetic opcodes pop two numbers and push the result. The result of OP_MODULO and
+	// OP_FLOOR_DIVIDE is an integer if both operands are integers, rounded towards negative
+	// infinity like Python. OP_POWER returns an integer if both operands are integers and the
+	// exponent is not negative.
+	OP_MODULO       Opcode = iota
+	OP_POWER        Opcode = iota
+	OP_FLOOR_DIVIDE Opcode = iota
+
+	// Bitwise opcodes pop two integers, or one for OP_BITWISE_NOT, and push the result.
+	OP_BITWISE_AND Opcode = iota
+	OP_BITWISE_OR  Opcode = iota
+	OP_BITWISE_XOR Opcode = iota
+	OP_SHIFT_LEFT  Opcode = iota
+	OP_SHIFT_RIGHT Opcode = iota
+	OP_BITWISE_NOT Opcode = iota
+
+	// OP_CALL calls a function with the number of arguments given by its operand. The function is
+	// below its arguments on the VM's stack, they are all popped and the result of the call is pushed.
+	OP_CALL Opcode = iota
+
+	// OP_GET_PROPERTY pops a value from the VM's stack, such as a module, and pushes its property
+	// whose name is the string at the index of its operand in the constants pool.
+	OP_GET_PROPERTY Opcode = iota
+
+	// OP_IMPORT pushes the module whose path is the string at the index of its operand in the
+	// constants pool. The module is executed the first time it is imported.
+	OP_IMPORT Opcode = iota
+
+	// OP_SET_PROPERTY pops a value and an object from the VM's stack, such as a struct instance,
+	// assigns the value to the object's property whose name is the string at the index of its
+	// operand in the constants pool, and pushes the value back.
+	OP_SET_PROPERTY Opcode = iota
+
+	// OP_DUP pushes the value on top of the VM's stack again.
+	OP_DUP Opcode = iota
 )
 
 // Represents a definition of an opcode.
@@ -163,6 +264,58 @@ var definitions = map[Opcode]*OpCodeDefinition{
 	// The OP_SCOPE_EXIT opcode has a single operand which takes two bytes of memory.
 	// The operand represents the number of local variables to pop from the stack when exiting a scope.
 	OP_SCOPE_EXIT: {Name: "OP_SCOPE_EXIT", OperandWidths: []int{2}},
+
+	// The operand of OP_TRY_BEGIN is the index of the exception handler in the handler table.
+	OP_TRY_BEGIN: {Name: "OP_TRY_BEGIN", OperandWidths: []int{2}},
+	OP_TRY_END:   {Name: "OP_TRY_END"},
+	OP_THROW:     {Name: "OP_THROW"},
+
+	OP_BUILD_STRING: {Name: "OP_BUILD_STRING", OperandWidths: []int{2}},
+
+	OP_MODULO:       {Name: "OP_MODULO"},
+	OP_POWER:        {Name: "OP_POWER"},
+	OP_FLOOR_DIVIDE: {Name: "OP_FLOOR_DIVIDE"},
+	OP_BITWISE_AND:  {Name: "OP_BITWISE_AND"},
+	OP_BITWISE_OR:   {Name: "OP_BITWISE_OR"},
+	OP_BITWISE_XOR:  {Name: "OP_BITWISE_XOR"},
+	OP_SHIFT_LEFT:   {Name: "OP_SHIFT_LEFT"},
+	OP_SHIFT_RIGHT:  {Name: "OP_SHIFT_RIGHT"},
+	OP_BITWISE_NOT:  {Name: "OP_BITWISE_NOT"},
+
+	// The operand of OP_CALL is the number of arguments.
+	OP_CALL: {Name: "OP_CALL", OperandWidths: []int{2}},
+
+	// The operand of OP_GET_PROPERTY is the index of the property's name in the constants pool.
+	OP_GET_PROPERTY: {Name: "OP_GET_PROPERTY", OperandWidths: []int{2}},
+
+	// The operand of OP_IMPORT is the index of the module's path in the constants pool.
+	OP_IMPORT: {Name: "OP_IMPORT", OperandWidths: []int{2}},
+
+	// The operand of OP_SET_PROPERTY is the index of the property's name in the constants pool.
+	OP_SET_PROPERTY: {Name: "OP_SET_PROPERTY", OperandWidths: []int{2}},
+	OP_DUP:          {Name: "OP_DUP"},
+}
+
+// instructionWidths caches the total number of bytes (opcode + operands) of each
+// defined opcode's instruction, so it can be looked up without accessing the `definitions` map.
+// Undefined opcodes have a width of 0.
+var instructionWidths [256]int
+
+func init() {
+	for op, def := range definitions {
+		width := OPCODE_TOTAL_BYTES
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		instructionWidths[op] = width
+	}
+}
+
+// InstructionWidth returns the total number of bytes an instruction with the given opcode
+// takes up, which includes the opcode byte and all its operands.
+// It returns 0 if the opcode is not defined.
+func InstructionWidth(op Opcode) int {
+	return instructionWidths[op]
 }
 
 func Get(op Opcode) (*OpCodeDefinition, error) {
@@ -1,6 +1,7 @@
 package compiler
 
 import (
+	"fmt"
 	"nilan/ast"
 	"nilan/token"
 	"strings"
@@ -10,7 +11,7 @@ import (
 func assertBytecodeEquals(t *testing.T, got Bytecode, want Bytecode) {
 
 	if len(got.Instructions) != len(want.Instructions) {
-		t.Errorf("computed instructions has a different length than the expected instructions - got: %d, want: %d", len(got.Instructions), len(want.Instructions))
+		t.Fatalf("computed instructions has a different length than the expected instructions - got: %d, want: %d", len(got.Instructions), len(want.Instructions))
 	}
 
 	for i, instruction := range got.Instructions {
@@ -20,7 +21,7 @@ func assertBytecodeEquals(t *testing.T, got Bytecode, want Bytecode) {
 	}
 
 	if len(got.ConstantsPool) != len(want.ConstantsPool) {
-		t.Errorf("computed constants pool has a different length than the expected constants pool - got: %d, want: %d", len(got.ConstantsPool), len(want.ConstantsPool))
+		t.Fatalf("computed constants pool has a different length than the expected constants pool - got: %d, want: %d", len(got.ConstantsPool), len(want.ConstantsPool))
 	}
 	for i, constant := range got.ConstantsPool {
 		if constant != want.ConstantsPool[i] {
@@ -47,13 +48,14 @@ func TestASTCompilerVisitIfStmt(t *testing.T) {
 			want: Bytecode{
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // true
-					byte(OP_JUMP_IF_FALSE), 0, 13, // jump to else (offset 13)
+					byte(OP_JUMP_IF_FALSE), 0, 14, // jump to else (offset 14)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 1, // 1
 					byte(OP_PRINT),
-					byte(OP_JUMP), 0, 17, // jump to end (offset 17)
+					byte(OP_JUMP), 0, 19, // jump to end (offset 19)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 2, // 2
 					byte(OP_PRINT),
-					byte(OP_POP),
 					byte(OP_END),
 				},
 				ConstantsPool: []any{true, int64(1), int64(2)},
@@ -71,13 +73,14 @@ func TestASTCompilerVisitIfStmt(t *testing.T) {
 			want: Bytecode{
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // false
-					byte(OP_JUMP_IF_FALSE), 0, 13, // jump to else (offset 13)
+					byte(OP_JUMP_IF_FALSE), 0, 14, // jump to else (offset 14)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 1, // 1
 					byte(OP_PRINT),
-					byte(OP_JUMP), 0, 17, // jump to end (offset 17)
+					byte(OP_JUMP), 0, 19, // jump to end (offset 19)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 2, // 2
 					byte(OP_PRINT),
-					byte(OP_POP),
 					byte(OP_END),
 				},
 				ConstantsPool: []any{false, int64(1), int64(2)},
@@ -96,10 +99,12 @@ func TestASTCompilerVisitIfStmt(t *testing.T) {
 			want: Bytecode{
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // true
-					byte(OP_JUMP_IF_FALSE), 0, 10, // jump past then (offset 10)
+					byte(OP_JUMP_IF_FALSE), 0, 14, // jump past then (offset 14)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 1, // 42
 					byte(OP_PRINT),
-					byte(OP_POP),
+					byte(OP_JUMP), 0, 15, // jump to end (offset 15)
+					byte(OP_POP), // pop condition
 					byte(OP_END),
 				},
 				ConstantsPool: []any{true, int64(42)},
@@ -118,10 +123,12 @@ func TestASTCompilerVisitIfStmt(t *testing.T) {
 			want: Bytecode{
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // false
-					byte(OP_JUMP_IF_FALSE), 0, 10, // jump past then (offset 10)
+					byte(OP_JUMP_IF_FALSE), 0, 14, // jump past then (offset 14)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 1, // 42
 					byte(OP_PRINT),
-					byte(OP_POP),
+					byte(OP_JUMP), 0, 15, // jump to end (offset 15)
+					byte(OP_POP), // pop condition
 					byte(OP_END),
 				},
 				ConstantsPool: []any{false, int64(42)},
@@ -131,9 +138,9 @@ func TestASTCompilerVisitIfStmt(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -227,9 +234,9 @@ func TestASTCompilerLocalVariableDeclaration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -270,6 +277,7 @@ func TestASTCompilerLocalVariableAccess(t *testing.T) {
 					byte(OP_GET_LOCAL), 0, 0, // load x
 					byte(OP_CONSTANT), 0, 1, // 3
 					byte(OP_ADD),
+					byte(OP_POP),              // discard the value of the expression statement
 					byte(OP_SCOPE_EXIT), 0, 1, // exit scope, pop 1 local variable
 					byte(OP_END),
 				},
@@ -309,6 +317,7 @@ func TestASTCompilerLocalVariableAccess(t *testing.T) {
 					byte(OP_GET_LOCAL), 0, 0, // load x
 					byte(OP_GET_LOCAL), 0, 1, // load y
 					byte(OP_ADD),
+					byte(OP_POP),              // discard the value of the expression statement
 					byte(OP_SCOPE_EXIT), 0, 2, // exit scope, pop 2 local variables
 					byte(OP_END),
 				},
@@ -320,9 +329,9 @@ func TestASTCompilerLocalVariableAccess(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -361,6 +370,7 @@ func TestASTCompilerLocalVariableAssignment(t *testing.T) {
 					byte(OP_SET_LOCAL), 0, 0, // set x in slot 0
 					byte(OP_CONSTANT), 0, 1, // 10
 					byte(OP_SET_LOCAL), 0, 0, // reassign x (same slot 0)
+					byte(OP_POP),              // discard the value of the assignment expression
 					byte(OP_SCOPE_EXIT), 0, 1, // exit scope, pop 1 local variable
 					byte(OP_END),
 				},
@@ -398,6 +408,7 @@ func TestASTCompilerLocalVariableAssignment(t *testing.T) {
 					byte(OP_CONSTANT), 0, 1, // 3
 					byte(OP_ADD),
 					byte(OP_SET_LOCAL), 0, 0, // reassign x (same slot 0)
+					byte(OP_POP),              // discard the value of the assignment expression
 					byte(OP_SCOPE_EXIT), 0, 1, // exit scope, pop 1 local variable
 					byte(OP_END),
 				},
@@ -409,9 +420,9 @@ func TestASTCompilerLocalVariableAssignment(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -532,9 +543,11 @@ func TestASTCompilerNestedScopes(t *testing.T) {
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // declare 5
 					byte(OP_SET_GLOBAL), 0, 0, // assign 5 to x in global scope
+					byte(OP_POP),              // discard the value of the declaration
 					byte(OP_GET_GLOBAL), 0, 0, // load x from global scope
 					byte(OP_CONSTANT), 0, 1, // declare 3
 					byte(OP_ADD),
+					byte(OP_POP), // discard the value of the expression statement
 					byte(OP_END),
 				},
 				ConstantsPool: []any{int64(5), int64(3)},
@@ -545,9 +558,9 @@ func TestASTCompilerNestedScopes(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -592,13 +605,16 @@ func TestASTCompilerScopeWithIfStatement(t *testing.T) {
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // 5
 					byte(OP_SET_GLOBAL), 0, 0, // set x
+					byte(OP_POP),              // discard the value of the declaration
 					byte(OP_GET_GLOBAL), 0, 0, // load x
 					byte(OP_CONSTANT), 0, 1, // declare 3
 					byte(OP_LARGER),
-					byte(OP_JUMP_IF_FALSE), 0, 25, // jump if false to end
+					byte(OP_JUMP_IF_FALSE), 0, 30, // jump if false to end
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 2, // 10
 					byte(OP_SET_LOCAL), 0, 0, // set y
 					byte(OP_SCOPE_EXIT), 0, 1, // exit if block scope, pop 1 (y)
+					byte(OP_JUMP), 0, 31, // jump to end
 					byte(OP_POP), // pop condition
 					byte(OP_END),
 				},
@@ -610,9 +626,9 @@ func TestASTCompilerScopeWithIfStatement(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -659,6 +675,7 @@ func TestASTCompilerLocalVariableShadowing(t *testing.T) {
 					byte(OP_CONSTANT), 0, 1, // 10
 					byte(OP_SET_LOCAL), 0, 1, // set x in slot 1 (inner, shadows outer)
 					byte(OP_GET_LOCAL), 0, 1, // load x from slot 1 (shadowed, inner)
+					byte(OP_POP),              // discard the value of the expression statement
 					byte(OP_SCOPE_EXIT), 0, 1, // exit inner scope, pop 1 (inner x)
 					byte(OP_SCOPE_EXIT), 0, 1, // exit outer scope, pop 1 (outer x)
 					byte(OP_END),
@@ -671,9 +688,9 @@ func TestASTCompilerLocalVariableShadowing(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
@@ -725,7 +742,7 @@ func TestCompilerPrintStatement(t *testing.T) {
 					Expression: ast.Grouping{
 						Expression: ast.Binary{
 							Left:     ast.Literal{Value: int64(2)},
-							Operator: token.CreateToken(token.ADD, 0, 0),
+							Operator: token.CreateToken(token.ADD),
 							Right:    ast.Literal{Value: int64(10)},
 						},
 					},
@@ -739,9 +756,9 @@ func TestCompilerPrintStatement(t *testing.T) {
 	}
 	for _, tt := range tests {
 		compiler := NewASTCompiler()
-		bytecode, err := compiler.CompileAST(tt.statements)
-		if err != nil {
-			t.Errorf("compilation error occurred: %s", err.Error())
+		bytecode, errs := compiler.CompileAST(tt.statements)
+		if len(errs) > 0 {
+			t.Errorf("compilation error occurred: %s", errs)
 		}
 		assertBytecodeEquals(t, bytecode, tt.expectedBytecode)
 	}
@@ -757,7 +774,7 @@ func TestCompileNumericTokens_BinaryExpressions(t *testing.T) {
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.ADD, 0, 0),
+						Operator: token.CreateToken(token.ADD),
 						Right:    ast.Literal{Value: int64(1)},
 					},
 				},
@@ -772,7 +789,7 @@ func TestCompileNumericTokens_BinaryExpressions(t *testing.T) {
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.MULT, 0, 0),
+						Operator: token.CreateToken(token.MULT),
 						Right:    ast.Literal{Value: int64(1)},
 					},
 				},
@@ -787,7 +804,7 @@ func TestCompileNumericTokens_BinaryExpressions(t *testing.T) {
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.DIV, 0, 0),
+						Operator: token.CreateToken(token.DIV),
 						Right:    ast.Literal{Value: int64(1)},
 					},
 				},
@@ -802,7 +819,7 @@ func TestCompileNumericTokens_BinaryExpressions(t *testing.T) {
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.SUB, 0, 0),
+						Operator: token.CreateToken(token.SUB),
 						Right:    ast.Literal{Value: int64(1)},
 					},
 				},
@@ -816,9 +833,9 @@ func TestCompileNumericTokens_BinaryExpressions(t *testing.T) {
 
 	for _, tt := range tests {
 		compiler := NewASTCompiler()
-		bytecode, err := compiler.CompileAST(tt.statements)
-		if err != nil {
-			t.Errorf("compilation error occurred: %s", err.Error())
+		bytecode, errs := compiler.CompileAST(tt.statements)
+		if len(errs) > 0 {
+			t.Errorf("compilation error occurred: %s", errs)
 		}
 		assertBytecodeEquals(t, bytecode, tt.expectedBytecode)
 	}
@@ -833,7 +850,7 @@ func TestCompileNumericTokens_UnaryExpressions(t *testing.T) {
 			statements: []ast.Stmt{
 				ast.ExpressionStmt{
 					Expression: ast.Unary{
-						Operator: token.CreateToken(token.SUB, 0, 0),
+						Operator: token.CreateToken(token.SUB),
 						Right:    ast.Literal{Value: int64(5)},
 					},
 				},
@@ -847,9 +864,9 @@ func TestCompileNumericTokens_UnaryExpressions(t *testing.T) {
 
 	for _, tt := range tests {
 		compiler := NewASTCompiler()
-		bytecode, err := compiler.CompileAST(tt.statements)
-		if err != nil {
-			t.Errorf("compilation error occurred: %s", err.Error())
+		bytecode, errs := compiler.CompileAST(tt.statements)
+		if len(errs) > 0 {
+			t.Errorf("compilation error occurred: %s", errs)
 		}
 		assertBytecodeEquals(t, bytecode, tt.expectedBytecode)
 	}
@@ -868,7 +885,7 @@ func TestASTCompileArithmetic(t *testing.T) {
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.ADD, 0, 0),
+						Operator: token.CreateToken(token.ADD),
 						Right:    ast.Literal{Value: int64(1)},
 					},
 				},
@@ -884,7 +901,7 @@ func TestASTCompileArithmetic(t *testing.T) {
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.MULT, 0, 0),
+						Operator: token.CreateToken(token.MULT),
 						Right:    ast.Literal{Value: int64(3)},
 					},
 				},
@@ -899,7 +916,7 @@ func TestASTCompileArithmetic(t *testing.T) {
 			statements: []ast.Stmt{
 				ast.ExpressionStmt{
 					Expression: ast.Unary{
-						Operator: token.CreateToken(token.SUB, 0, 0),
+						Operator: token.CreateToken(token.SUB),
 						Right:    ast.Literal{Value: int64(5)},
 					},
 				},
@@ -914,9 +931,9 @@ func TestASTCompileArithmetic(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.statements)
-			if err != nil {
-				t.Errorf("compilation error occurred: %s", err.Error())
+			bytecode, errs := compiler.CompileAST(tt.statements)
+			if len(errs) > 0 {
+				t.Errorf("compilation error occurred: %s", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.expectedBytecode)
 		})
@@ -938,14 +955,14 @@ func TestASTCompilerDisassembleBytecode(t *testing.T) {
 					Expression: ast.Binary{
 						Left: ast.Binary{
 							Left:     ast.Literal{Value: int64(1)},
-							Operator: token.CreateToken(token.ADD, 0, 0),
+							Operator: token.CreateToken(token.ADD),
 							Right: ast.Binary{
 								Left:     ast.Literal{Value: int64(2)},
-								Operator: token.CreateToken(token.MULT, 0, 0),
+								Operator: token.CreateToken(token.MULT),
 								Right:    ast.Literal{Value: int64(4)},
 							},
 						},
-						Operator: token.CreateToken(token.ADD, 0, 0),
+						Operator: token.CreateToken(token.ADD),
 						Right:    ast.Literal{Value: int64(3)},
 					},
 				},
@@ -965,7 +982,7 @@ opcode: OP_END, operand: None, operand widths: 0 bytes`,
 				ast.ExpressionStmt{
 					Expression: ast.Binary{
 						Left:     ast.Literal{Value: int64(5)},
-						Operator: token.CreateToken(token.ADD, 0, 0),
+						Operator: token.CreateToken(token.ADD),
 						Right:    ast.Literal{Value: int64(3)},
 					},
 				},
@@ -982,13 +999,13 @@ opcode: OP_END, operand: None, operand widths: 0 bytes`,
 					Expression: ast.Binary{
 						Left: ast.Binary{
 							Left:     ast.Literal{Value: int64(1)},
-							Operator: token.CreateToken(token.ADD, 0, 0),
+							Operator: token.CreateToken(token.ADD),
 							Right:    ast.Literal{Value: int64(2)},
 						},
-						Operator: token.CreateToken(token.MULT, 0, 0),
+						Operator: token.CreateToken(token.MULT),
 						Right: ast.Binary{
 							Left:     ast.Literal{Value: int64(4)},
-							Operator: token.CreateToken(token.ADD, 0, 0),
+							Operator: token.CreateToken(token.ADD),
 							Right:    ast.Literal{Value: int64(3)},
 						},
 					},
@@ -1010,10 +1027,10 @@ opcode: OP_END, operand: None, operand widths: 0 bytes`,
 					Expression: ast.Binary{
 						Left: ast.Binary{
 							Left:     ast.Literal{Value: int64(10)},
-							Operator: token.CreateToken(token.DIV, 0, 0),
+							Operator: token.CreateToken(token.DIV),
 							Right:    ast.Literal{Value: int64(2)},
 						},
-						Operator: token.CreateToken(token.SUB, 0, 0),
+						Operator: token.CreateToken(token.SUB),
 						Right:    ast.Literal{Value: int64(1)},
 					},
 				},
@@ -1031,9 +1048,9 @@ opcode: OP_END, operand: None, operand widths: 0 bytes`,
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
 			if tt.statements != nil {
-				_, err := compiler.CompileAST(tt.statements)
-				if err != nil {
-					t.Errorf("compilation error occurred: %s", err.Error())
+				_, errs := compiler.CompileAST(tt.statements)
+				if len(errs) > 0 {
+					t.Errorf("compilation error occurred: %s", errs)
 				}
 			}
 
@@ -1076,9 +1093,9 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // true (loop start)
 					byte(OP_JUMP_IF_FALSE), 0, 14, // jump to end if false (offset 14)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 1, // 1
 					byte(OP_PRINT),
-					byte(OP_POP),        // pop condition
 					byte(OP_JUMP), 0, 0, // jump back to loop start (offset 0)
 					byte(OP_POP), // pop condition at end
 					byte(OP_END),
@@ -1104,9 +1121,9 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // false (loop start)
 					byte(OP_JUMP_IF_FALSE), 0, 14, // jump to end if false (offset 14)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 1, // 1
 					byte(OP_PRINT),
-					byte(OP_POP),        // pop condition
 					byte(OP_JUMP), 0, 0, // jump back to loop start (offset 0)
 					byte(OP_POP), // pop condition at end
 					byte(OP_END),
@@ -1120,7 +1137,7 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 				ast.WhileStmt{
 					Condition: ast.Binary{
 						Left:     ast.Literal{Value: int64(1)},
-						Operator: token.CreateToken(token.LESS, 0, 0),
+						Operator: token.CreateToken(token.LESS),
 						Right:    ast.Literal{Value: int64(5)},
 					},
 					Body: ast.BlockStmt{
@@ -1135,11 +1152,11 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 					byte(OP_CONSTANT), 0, 0, // 1
 					byte(OP_CONSTANT), 0, 1, // 5
 					byte(OP_LESS),                 // 1 < 5
-					byte(OP_JUMP_IF_FALSE), 0, 18, // jump to end if false (offset 25)
+					byte(OP_JUMP_IF_FALSE), 0, 18, // jump to end if false (offset 18)
+					byte(OP_POP),            // pop condition
 					byte(OP_CONSTANT), 0, 2, // "true"
 					byte(OP_PRINT),
-					byte(OP_POP),        // pop condition
-					byte(OP_JUMP), 0, 0, // jump back to loop start (offset 6)
+					byte(OP_JUMP), 0, 0, // jump back to loop start (offset 0)
 					byte(OP_POP), // pop condition at end
 					byte(OP_END),
 				},
@@ -1151,14 +1168,14 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 			name: "var x = 1 while(x < 5){print(x)}",
 			stmts: []ast.Stmt{
 				ast.VarStmt{
-					Name:        token.CreateLiteralToken(token.IDENTIFIER, "x", "x", 0, 0),
+					Name:        token.CreateLiteralToken(token.IDENTIFIER, "x", "x"),
 					Initializer: ast.Literal{Value: int64(1)},
 				},
 				ast.WhileStmt{
 					Condition: ast.Grouping{
 						Expression: ast.Binary{
-							Left:     ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, "x", "x", 0, 0)},
-							Operator: token.CreateToken(token.LESS, 0, 0),
+							Left:     ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, "x", "x")},
+							Operator: token.CreateToken(token.LESS),
 							Right:    ast.Literal{Value: int64(5)},
 						},
 					},
@@ -1166,7 +1183,7 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 						Statements: []ast.Stmt{
 							ast.PrintStmt{
 								Expression: ast.Grouping{
-									Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, "x", "x", 0, 0)},
+									Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, "x", "x")},
 								},
 							},
 						},
@@ -1177,14 +1194,15 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // 1
 					byte(OP_SET_GLOBAL), 0, 0, // 1
+					byte(OP_POP),              // discard the value of the declaration
 					byte(OP_GET_GLOBAL), 0, 0, // 1
 					byte(OP_CONSTANT), 0, 1, // 5
 					byte(OP_LESS),                 // 1 < 5
-					byte(OP_JUMP_IF_FALSE), 0, 24, // jump to end if false (offset 25)
+					byte(OP_JUMP_IF_FALSE), 0, 25, // jump to end if false (offset 25)
+					byte(OP_POP),              // pop condition
 					byte(OP_GET_GLOBAL), 0, 0, // 1
 					byte(OP_PRINT),
-					byte(OP_POP),        // pop condition
-					byte(OP_JUMP), 0, 6, // jump back to loop start (offset 6)
+					byte(OP_JUMP), 0, 7, // jump back to loop start (offset 7)
 					byte(OP_POP), // pop condition at end
 					byte(OP_END),
 				},
@@ -1217,15 +1235,15 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 				Instructions: []byte{
 					byte(OP_CONSTANT), 0, 0, // true (outer loop start)
 					byte(OP_JUMP_IF_FALSE), 0, 25, // jump to end of outer loop if false
+					byte(OP_POP), // pop condition (outer)
 					// Inner loop
 					byte(OP_CONSTANT), 0, 1, // false (inner loop start)
-					byte(OP_JUMP_IF_FALSE), 0, 20, // jump to end of inner loop if false
+					byte(OP_JUMP_IF_FALSE), 0, 21, // jump to end of inner loop if false
+					byte(OP_POP),            // pop condition (inner)
 					byte(OP_CONSTANT), 0, 2, // 1
 					byte(OP_PRINT),
-					byte(OP_POP),        // pop condition
-					byte(OP_JUMP), 0, 6, // jump back to inner loop start
+					byte(OP_JUMP), 0, 7, // jump back to inner loop start
 					byte(OP_POP),        // pop condition at end of inner loop
-					byte(OP_POP),        // pop condition (outer)
 					byte(OP_JUMP), 0, 0, // jump back to outer loop start
 					byte(OP_POP), // pop condition at end of outer loop
 					byte(OP_END),
@@ -1238,11 +1256,353 @@ func TestASTCompilerVisitWhileStmt(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(tt.stmts)
-			if err != nil {
-				t.Fatalf("compilation error: %v", err)
+			bytecode, errs := compiler.CompileAST(tt.stmts)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
 			}
 			assertBytecodeEquals(t, bytecode, tt.want)
 		})
 	}
 }
+
+func TestASTCompilerVisitTryStmt(t *testing.T) {
+	catchBlock := ast.BlockStmt{Statements: []ast.Stmt{
+		ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, "e", "e")}},
+	}}
+	finallyBlock := ast.BlockStmt{Statements: []ast.Stmt{
+		ast.PrintStmt{Expression: ast.Literal{Value: int64(2)}},
+	}}
+	// try { throw 1 } catch (e) { print e } finally { print 2 }
+	stmts := []ast.Stmt{
+		ast.TryStmt{
+			Keyword: token.CreateToken(token.TRY),
+			Body: ast.BlockStmt{Statements: []ast.Stmt{
+				ast.ThrowStmt{Keyword: token.CreateToken(token.THROW), Value: ast.Literal{Value: int64(1)}},
+			}},
+			CatchName: token.CreateLiteralToken(token.IDENTIFIER, "e", "e"),
+			Catch:     &catchBlock,
+			Finally:   &finallyBlock,
+		},
+	}
+	want := Bytecode{
+		Instructions: []byte{
+			byte(OP_TRY_BEGIN), 0, 0, // register the finally handler
+			byte(OP_TRY_BEGIN), 0, 1, // register the catch handler
+			byte(OP_CONSTANT), 0, 0, // 1
+			byte(OP_THROW),
+			byte(OP_TRY_END),     // remove the catch handler
+			byte(OP_JUMP), 0, 21, // jump over the catch block
+			byte(OP_GET_LOCAL), 0, 0, // catch handler target, load e
+			byte(OP_PRINT),
+			byte(OP_SCOPE_EXIT), 0, 1, // pop e
+			byte(OP_TRY_END),        // remove the finally handler
+			byte(OP_CONSTANT), 0, 1, // 2
+			byte(OP_PRINT),
+			byte(OP_JUMP), 0, 37, // jump over the re-throwing finally block
+			byte(OP_CONSTANT), 0, 2, // finally handler target, 2
+			byte(OP_PRINT),
+			byte(OP_GET_LOCAL), 0, 0, // load the caught value
+			byte(OP_THROW), // re-throw it
+			byte(OP_END),
+		},
+		ConstantsPool: []any{int64(1), int64(2), int64(2)},
+	}
+
+	bytecode, errs := NewASTCompiler().CompileAST(stmts)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	assertBytecodeEquals(t, bytecode, want)
+
+	wantHandlers := []ExceptionHandler{
+		{Start: 3, End: 21, Target: 29},
+		{Start: 6, End: 10, Target: 14},
+	}
+	if len(bytecode.Handlers) != len(wantHandlers) {
+		t.Fatalf("got %d handlers, want %d", len(bytecode.Handlers), len(wantHandlers))
+	}
+	for i, handler := range bytecode.Handlers {
+		if handler != wantHandlers[i] {
+			t.Errorf("handler %d - got: %+v, want: %+v", i, handler, wantHandlers[i])
+		}
+	}
+	if err := Verify(bytecode); err != nil {
+		t.Errorf("verification error: %v", err)
+	}
+}
+
+func TestASTCompilerVisitInterpolation(t *testing.T) {
+	// { var x = 2 print "a${x}${1}" }
+	stmts := []ast.Stmt{
+		ast.BlockStmt{Statements: []ast.Stmt{
+			ast.VarStmt{
+				Name:        token.Token{Lexeme: "x", TokenType: token.IDENTIFIER},
+				Initializer: ast.Literal{Value: int64(2)},
+			},
+			ast.PrintStmt{Expression: ast.Interpolation{Parts: []ast.Expression{
+				ast.Literal{Value: "a"},
+				ast.Variable{Name: token.Token{Lexeme: "x", TokenType: token.IDENTIFIER}},
+				ast.Literal{Value: ""},
+				ast.Literal{Value: int64(1)},
+				ast.Literal{Value: ""},
+			}}},
+		}},
+	}
+	want := Bytecode{
+		Instructions: []byte{
+			byte(OP_CONSTANT), 0, 0, // 2
+			byte(OP_SET_LOCAL), 0, 0, // set x in slot 0
+			byte(OP_CONSTANT), 0, 1, // "a"
+			byte(OP_GET_LOCAL), 0, 0, // x
+			byte(OP_CONSTANT), 0, 2, // 1, the empty text is skipped
+			byte(OP_BUILD_STRING), 0, 3,
+			byte(OP_PRINT),
+			byte(OP_SCOPE_EXIT), 0, 1,
+			byte(OP_END),
+		},
+		ConstantsPool: []any{int64(2), "a", int64(1)},
+	}
+
+	compiler := NewASTCompiler()
+	bytecode, errs := compiler.CompileAST(stmts)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	assertBytecodeEquals(t, bytecode, want)
+
+	disassembled, err := compiler.DiassembleBytecode(false, "")
+	if err != nil {
+		t.Fatalf("bytecode disassembly error: %v", err)
+	}
+	wantLine := "opcode: OP_BUILD_STRING, operand: 3, operand widths: 2 bytes, total values to concatenate: 3"
+	if !strings.Contains(disassembled, wantLine) {
+		t.Errorf("got disassembly:\n%s\nwant it to contain: %s", disassembled, wantLine)
+	}
+}
+
+func TestASTCompilerCompoundAssignment(t *testing.T) {
+	name := token.Token{Lexeme: "x", TokenType: token.IDENTIFIER}
+	// { var x = 2 x += 3 }
+	stmts := []ast.Stmt{
+		ast.BlockStmt{Statements: []ast.Stmt{
+			ast.VarStmt{Name: name, Initializer: ast.Literal{Value: int64(2)}},
+			ast.ExpressionStmt{Expression: ast.Assign{
+				Name:     name,
+				Value:    ast.Literal{Value: int64(3)},
+				Operator: token.CreateToken(token.ADD_ASSIGN),
+			}},
+		}},
+	}
+	want := Bytecode{
+		Instructions: []byte{
+			byte(OP_CONSTANT), 0, 0, // 2
+			byte(OP_SET_LOCAL), 0, 0, // set x in slot 0
+			byte(OP_GET_LOCAL), 0, 0, // x
+			byte(OP_CONSTANT), 0, 1, // 3
+			byte(OP_ADD),
+			byte(OP_SET_LOCAL), 0, 0, // set x to x + 3
+			byte(OP_POP),
+			byte(OP_SCOPE_EXIT), 0, 1,
+			byte(OP_END),
+		},
+		ConstantsPool: []any{int64(2), int64(3)},
+	}
+
+	bytecode, errs := NewASTCompiler().CompileAST(stmts)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	assertBytecodeEquals(t, bytecode, want)
+}
+
+func TestASTCompilerVisitCall(t *testing.T) {
+	// print math.max(sqrt(4), 1)
+	stmts := []ast.Stmt{
+		ast.PrintStmt{Expression: ast.Call{
+			Callee: ast.Get{
+				Object: ast.Variable{Name: token.Token{Lexeme: "math", TokenType: token.IDENTIFIER}},
+				Name:   token.Token{Lexeme: "max", TokenType: token.IDENTIFIER},
+			},
+			Arguments: []ast.Expression{
+				ast.Call{
+					Callee:    ast.Variable{Name: token.Token{Lexeme: "sqrt", TokenType: token.IDENTIFIER}},
+					Arguments: []ast.Expression{ast.Literal{Value: int64(4)}},
+				},
+				ast.Literal{Value: int64(1)},
+			},
+		}},
+	}
+	want := Bytecode{
+		Instructions: []byte{
+			byte(OP_GET_GLOBAL), 0, 0, // math
+			byte(OP_GET_PROPERTY), 0, 0, // "max"
+			byte(OP_GET_GLOBAL), 0, 1, // sqrt
+			byte(OP_CONSTANT), 0, 1, // 4
+			byte(OP_CALL), 0, 1,
+			byte(OP_CONSTANT), 0, 2, // 1
+			byte(OP_CALL), 0, 2,
+			byte(OP_PRINT),
+			byte(OP_END),
+		},
+		ConstantsPool: []any{"max", int64(4), int64(1)},
+	}
+
+	compiler := NewASTCompiler()
+	bytecode, errs := compiler.CompileAST(stmts)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	assertBytecodeEquals(t, bytecode, want)
+	if strings.Join(bytecode.NameConstants, ",") != "math,sqrt" {
+		t.Errorf("got name constants: %v, want: [math sqrt]", bytecode.NameConstants)
+	}
+
+	disassembled, err := compiler.DiassembleBytecode(false, "")
+	if err != nil {
+		t.Fatalf("bytecode disassembly error: %v", err)
+	}
+	for _, wantLine := range []string{
+		"opcode: OP_GET_PROPERTY, operand: 0, operand widths: 2 bytes, name: max",
+		"opcode: OP_CALL, operand: 2, operand widths: 2 bytes, total arguments: 2",
+	} {
+		if !strings.Contains(disassembled, wantLine) {
+			t.Errorf("got disassembly:\n%s\nwant it to contain: %s", disassembled, wantLine)
+		}
+	}
+}
+
+func TestASTCompilerVisitImportStmt(t *testing.T) {
+	// import "lib.ni" as lib
+	// print lib.x
+	lib := token.Token{Lexeme: "lib", TokenType: token.IDENTIFIER}
+	stmts := []ast.Stmt{
+		ast.ImportStmt{
+			Keyword: token.Token{Lexeme: "import", TokenType: token.IMPORT},
+			Path:    token.Token{Lexeme: `"lib.ni"`, Literal: "lib.ni", TokenType: token.STRING},
+			Name:    lib,
+		},
+		ast.PrintStmt{Expression: ast.Get{
+			Object: ast.Variable{Name: lib},
+			Name:   token.Token{Lexeme: "x", TokenType: token.IDENTIFIER},
+		}},
+	}
+	want := Bytecode{
+		Instructions: []byte{
+			byte(OP_IMPORT), 0, 0, // "lib.ni"
+			byte(OP_SET_GLOBAL), 0, 0, // lib
+			byte(OP_POP),
+			byte(OP_GET_GLOBAL), 0, 0, // lib
+			byte(OP_GET_PROPERTY), 0, 1, // "x"
+			byte(OP_PRINT),
+			byte(OP_END),
+		},
+		ConstantsPool: []any{"lib.ni", "x"},
+	}
+
+	compiler := NewASTCompiler()
+	bytecode, errs := compiler.CompileAST(stmts)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	assertBytecodeEquals(t, bytecode, want)
+	if err := Verify(bytecode); err != nil {
+		t.Errorf("verification error: %v", err)
+	}
+	disassembled, err := compiler.DiassembleBytecode(false, "")
+	if err != nil {
+		t.Fatalf("bytecode disassembly error: %v", err)
+	}
+	if wantLine := "path: lib.ni"; !strings.Contains(disassembled, wantLine) {
+		t.Errorf("got disassembly:\n%s\nwant it to contain: %s", disassembled, wantLine)
+	}
+
+	// { import "lib.ni" as lib }
+	_, errs = NewASTCompiler().CompileAST([]ast.Stmt{ast.BlockStmt{Statements: stmts[:1]}})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Modules can only be imported at the top level of a file") {
+		t.Errorf("got errors: %v, want: Modules can only be imported at the top level of a file", errs)
+	}
+}
+
+func TestASTCompilerVisitStructStmtAndSet(t *testing.T) {
+	// struct Point { x, y }
+	// var p = Point(1, 2)
+	// p.x = 3
+	// p.y += 4
+	point := token.Token{Lexeme: "Point", TokenType: token.IDENTIFIER}
+	p := token.Token{Lexeme: "p", TokenType: token.IDENTIFIER}
+	stmts := []ast.Stmt{
+		ast.StructStmt{
+			Keyword: token.Token{Lexeme: "struct", TokenType: token.STRUCT},
+			Name:    point,
+			Fields:  []token.Token{{Lexeme: "x", TokenType: token.IDENTIFIER}, {Lexeme: "y", TokenType: token.IDENTIFIER}},
+		},
+		ast.VarStmt{Name: p, Initializer: ast.Call{
+			Callee:    ast.Variable{Name: point},
+			Arguments: []ast.Expression{ast.Literal{Value: int64(1)}, ast.Literal{Value: int64(2)}},
+		}},
+		ast.ExpressionStmt{Expression: ast.Set{
+			Object:   ast.Variable{Name: p},
+			Name:     token.Token{Lexeme: "x", TokenType: token.IDENTIFIER},
+			Value:    ast.Literal{Value: int64(3)},
+			Operator: token.Token{Lexeme: "=", TokenType: token.ASSIGN},
+		}},
+		ast.ExpressionStmt{Expression: ast.Set{
+			Object:   ast.Variable{Name: p},
+			Name:     token.Token{Lexeme: "y", TokenType: token.IDENTIFIER},
+			Value:    ast.Literal{Value: int64(4)},
+			Operator: token.Token{Lexeme: "+=", TokenType: token.ADD_ASSIGN},
+		}},
+	}
+	want := Bytecode{
+		Instructions: []byte{
+			byte(OP_CONSTANT), 0, 0, // <struct Point>
+			byte(OP_SET_GLOBAL), 0, 0, // Point
+			byte(OP_POP),
+			byte(OP_GET_GLOBAL), 0, 0, // Point
+			byte(OP_CONSTANT), 0, 1, // 1
+			byte(OP_CONSTANT), 0, 2, // 2
+			byte(OP_CALL), 0, 2,
+			byte(OP_SET_GLOBAL), 0, 1, // p
+			byte(OP_POP),
+			byte(OP_GET_GLOBAL), 0, 1, // p
+			byte(OP_CONSTANT), 0, 4, // 3
+			byte(OP_SET_PROPERTY), 0, 3, // "x"
+			byte(OP_POP),
+			byte(OP_GET_GLOBAL), 0, 1, // p
+			byte(OP_DUP),
+			byte(OP_GET_PROPERTY), 0, 5, // "y"
+			byte(OP_CONSTANT), 0, 6, // 4
+			byte(OP_ADD),
+			byte(OP_SET_PROPERTY), 0, 5, // "y", the value of the last expression is echoed by OP_END
+			byte(OP_END),
+		},
+	}
+
+	compiler := NewASTCompiler()
+	bytecode, errs := compiler.CompileAST(stmts)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	if string(bytecode.Instructions) != string(want.Instructions) {
+		t.Errorf("got instructions: %v, want: %v", bytecode.Instructions, want.Instructions)
+	}
+	wantConstants := "[<struct Point> 1 2 x 3 y 4]"
+	if got := fmt.Sprint(bytecode.ConstantsPool); got != wantConstants {
+		t.Errorf("got constants pool: %s, want: %s", got, wantConstants)
+	}
+	if err := Verify(bytecode); err != nil {
+		t.Errorf("verification error: %v", err)
+	}
+	disassembled, err := compiler.DiassembleBytecode(false, "")
+	if err != nil {
+		t.Fatalf("bytecode disassembly error: %v", err)
+	}
+	for _, wantLine := range []string{
+		"opcode: OP_SET_PROPERTY, operand: 3, operand widths: 2 bytes, name: x",
+		"opcode: OP_DUP",
+	} {
+		if !strings.Contains(disassembled, wantLine) {
+			t.Errorf("got disassembly:\n%s\nwant it to contain: %s", disassembled, wantLine)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package compiler
+
+// This file implements the binary encoding of `Bytecode`, which is used to store
+// compiled Nilan programs on disk.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"nilan/token"
+	"nilan/value"
+)
+
+// bytecodeMagic identifies an encoded Nilan bytecode file.
+var bytecodeMagic = []byte("NILC")
+
+// BytecodeFormatVersion is incremented whenever the encoding of `Bytecode` changes,
+// so bytecode encoded by an older version of Nilan is rejected instead of misinterpreted.
+const BytecodeFormatVersion byte = 4
+
+// Tags identifying the Go type of each value in the encoded constants pool.
+const (
+	constantNil byte = iota
+	constantBool
+	constantInt
+	constantFloat
+	constantString
+	constantDecimal
+	constantStruct
+)
+
+// MarshalBinary encodes the bytecode in the following format, where all integers are
+// encoded in Big-Endian order:
+//
+//	"NILC" | version (1 byte)
+//	number of instruction bytes (uint32) | instructions
+//	number of constants (uint32) | constants, each encoded as a type tag (1 byte) followed by its value,
+//	  decimals are encoded as their text like strings, structs as their name followed by
+//	  their number of fields (uint32) and their fields, like strings
+//	number of name constants (uint32) | name constants, each encoded as a length (uint32) followed by its bytes
+//	number of exception handlers (uint32) | handlers, each encoded as its start, end and target (uint32 each)
+//	number of line table entries (uint32) | entries, each encoded as its offset followed by the line,
+//	  column and offset of its start and of its end (uint32 each)
+//
+// An error is returned if the constants pool contains a value which can not be encoded.
+func (b Bytecode) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(bytecodeMagic)
+	buf.WriteByte(BytecodeFormatVersion)
+
+	writeUint32(&buf, len(b.Instructions))
+	buf.Write(b.Instructions)
+
+	writeUint32(&buf, len(b.ConstantsPool))
+	for i, constant := range b.ConstantsPool {
+		switch v := constant.(type) {
+		case nil:
+			buf.WriteByte(constantNil)
+		case bool:
+			buf.WriteByte(constantBool)
+			if v {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+		case int64:
+			buf.WriteByte(constantInt)
+			binary.Write(&buf, binary.BigEndian, v)
+		case float64:
+			buf.WriteByte(constantFloat)
+			binary.Write(&buf, binary.BigEndian, math.Float64bits(v))
+		case string:
+			buf.WriteByte(constantString)
+			writeString(&buf, v)
+		case value.Decimal:
+			buf.WriteByte(constantDecimal)
+			writeString(&buf, v.String())
+		case *value.Struct:
+			buf.WriteByte(constantStruct)
+			writeString(&buf, v.Name)
+			writeUint32(&buf, len(v.Fields))
+			for _, field := range v.Fields {
+				writeString(&buf, field)
+			}
+		default:
+			return nil, fmt.Errorf("cannot encode constant %d of type %T", i, constant)
+		}
+	}
+
+	writeUint32(&buf, len(b.NameConstants))
+	for _, name := range b.NameConstants {
+		writeString(&buf, name)
+	}
+
+	writeUint32(&buf, len(b.Handlers))
+	for _, handler := range b.Handlers {
+		writeUint32(&buf, handler.Start)
+		writeUint32(&buf, handler.End)
+		writeUint32(&buf, handler.Target)
+	}
+
+	writeUint32(&buf, len(b.Lines))
+	for _, entry := range b.Lines {
+		writeUint32(&buf, entry.Offset)
+		for _, position := range []token.Position{entry.Start, entry.End} {
+			writeUint32(&buf, position.Line)
+			writeUint32(&buf, position.Column)
+			writeUint32(&buf, position.Offset)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes bytecode encoded by MarshalBinary.
+//
+// NOTE: Decoding only checks the encoding is well formed. The decoded bytecode should be
+// checked with Verify before it is executed.
+func (b *Bytecode) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+
+	header := make([]byte, len(bytecodeMagic)+1)
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.Equal(header[:len(bytecodeMagic)], bytecodeMagic) {
+		return errors.New("not a nilan bytecode file")
+	}
+	if version := header[len(bytecodeMagic)]; version != BytecodeFormatVersion {
+		return fmt.Errorf("unsupported bytecode format version %d, expected %d", version, BytecodeFormatVersion)
+	}
+
+	instructions, err := readBytes(reader)
+	if err != nil {
+		return fmt.Errorf("reading instructions: %w", err)
+	}
+
+	count, err := readUint32(reader)
+	if err != nil {
+		return fmt.Errorf("reading constants pool: %w", err)
+	}
+	constants := []any{}
+	for i := 0; i < count; i++ {
+		tag, err := reader.ReadByte()
+		if err != nil {
+			return fmt.Errorf("reading constant %d: %w", i, err)
+		}
+		var constant any
+		switch tag {
+		case constantNil:
+			constant = nil
+		case constantBool:
+			value, err := reader.ReadByte()
+			if err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			constant = value == 1
+		case constantInt:
+			var value int64
+			if err := binary.Read(reader, binary.BigEndian, &value); err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			constant = value
+		case constantFloat:
+			var bits uint64
+			if err := binary.Read(reader, binary.BigEndian, &bits); err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			constant = math.Float64frombits(bits)
+		case constantString:
+			value, err := readBytes(reader)
+			if err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			constant = string(value)
+		case constantDecimal:
+			text, err := readBytes(reader)
+			if err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			decimal, err := value.ParseDecimal(string(text))
+			if err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			constant = decimal
+		case constantStruct:
+			structure, err := readStruct(reader)
+			if err != nil {
+				return fmt.Errorf("reading constant %d: %w", i, err)
+			}
+			constant = structure
+		default:
+			return fmt.Errorf("reading constant %d: unknown constant type %d", i, tag)
+		}
+		constants = append(constants, constant)
+	}
+
+	count, err = readUint32(reader)
+	if err != nil {
+		return fmt.Errorf("reading name constants: %w", err)
+	}
+	names := []string{}
+	for i := 0; i < count; i++ {
+		name, err := readBytes(reader)
+		if err != nil {
+			return fmt.Errorf("reading name constant %d: %w", i, err)
+		}
+		names = append(names, string(name))
+	}
+
+	count, err = readUint32(reader)
+	if err != nil {
+		return fmt.Errorf("reading exception handlers: %w", err)
+	}
+	handlers := []ExceptionHandler{}
+	for i := 0; i < count; i++ {
+		fields, err := readUint32s(reader, 3)
+		if err != nil {
+			return fmt.Errorf("reading exception handler %d: %w", i, err)
+		}
+		handlers = append(handlers, ExceptionHandler{Start: fields[0], End: fields[1], Target: fields[2]})
+	}
+
+	count, err = readUint32(reader)
+	if err != nil {
+		return fmt.Errorf("reading line table: %w", err)
+	}
+	lines := []LineEntry{}
+	for i := 0; i < count; i++ {
+		fields, err := readUint32s(reader, 7)
+		if err != nil {
+			return fmt.Errorf("reading line table entry %d: %w", i, err)
+		}
+		lines = append(lines, LineEntry{
+			Offset: fields[0],
+			Start:  token.Position{Line: fields[1], Column: fields[2], Offset: fields[3]},
+			End:    token.Position{Line: fields[4], Column: fields[5], Offset: fields[6]},
+		})
+	}
+
+	if reader.Len() > 0 {
+		return fmt.Errorf("%d unexpected trailing bytes", reader.Len())
+	}
+
+	b.Instructions = instructions
+	b.ConstantsPool = constants
+	b.NameConstants = names
+	b.Handlers = handlers
+	b.Lines = lines
+	return nil
+}
+
+// readStruct decodes a struct constant, after its tag.
+func readStruct(reader *bytes.Reader) (*value.Struct, error) {
+	name, err := readBytes(reader)
+	if err != nil {
+		return nil, err
+	}
+	count, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	fields := []string{}
+	for i := 0; i < count; i++ {
+		field, err := readBytes(reader)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, string(field))
+	}
+	return &value.Struct{Name: string(name), Fields: fields}, nil
+}
+
+func writeUint32(buf *bytes.Buffer, value int) {
+	binary.Write(buf, binary.BigEndian, uint32(value))
+}
+
+func writeString(buf *bytes.Buffer, value string) {
+	writeUint32(buf, len(value))
+	buf.WriteString(value)
+}
+
+func readUint32(reader *bytes.Reader) (int, error) {
+	var value uint32
+	if err := binary.Read(reader, binary.BigEndian, &value); err != nil {
+		return 0, err
+	}
+	return int(value), nil
+}
+
+// readUint32s reads `n` consecutive uint32 values.
+func readUint32s(reader *bytes.Reader, n int) ([]int, error) {
+	values := make([]int, n)
+	for i := range values {
+		value, err := readUint32(reader)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// readBytes reads a length prefixed byte array.
+func readBytes(reader *bytes.Reader) ([]byte, error) {
+	length, err := readUint32(reader)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: Guards against allocating huge arrays for corrupted lengths.
+	if length > reader.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -1,15 +1,54 @@
 package compiler
 
-import "fmt"
+import (
+	"fmt"
+	"nilan/diagnostics"
+	"nilan/token"
+)
 
 type SemanticError struct {
 	Message string
+	// The diagnostic code of the error, for example diagnostics.CodeUndefined.
+	Code string
+	// The token the error was raised at, for example the name of an undefined variable.
+	// Its TokenType is empty when the error is not caused by a specific token.
+	Token token.Token
 }
 
 func (e SemanticError) Error() string {
 	return fmt.Sprintf("💥 SemanticError: %s", e.Message)
 }
 
+// help suggests a fix for the errors which have an obvious one.
+func (e SemanticError) help() string {
+	switch e.Code {
+	case diagnostics.CodeUndefined:
+		return fmt.Sprintf("declare the variable with `var %s = ...` before using it", e.Token.Lexeme)
+	case diagnostics.CodeUninitialised:
+		return fmt.Sprintf("assign a value to '%s' before reading it", e.Token.Lexeme)
+	case diagnostics.CodeRedefinition:
+		return fmt.Sprintf("use an assignment such as `%s = ...` to change the existing variable", e.Token.Lexeme)
+	}
+	return ""
+}
+
+// Diagnostic describes the error for rendering. The span is the span of Token, empty
+// when the error is not caused by a specific token.
+func (e SemanticError) Diagnostic() diagnostics.Diagnostic {
+	code := e.Code
+	if code == "" {
+		code = diagnostics.CodeSemantic
+	}
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     code,
+		Message:  e.Message,
+		Help:     e.help(),
+		Start:    e.Token.Start,
+		End:      e.Token.End,
+	}
+}
+
 type DeveloperError struct {
 	Message string
 }
@@ -17,3 +56,23 @@ type DeveloperError struct {
 func (e DeveloperError) Error() string {
 	return fmt.Sprintf("🤖 DeveloperError: %s", e.Message)
 }
+
+func (e DeveloperError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeInternal,
+		Message:  e.Message,
+		Help:     "this is a bug in the Nilan compiler, please report it",
+	}
+}
+
+// VerificationError is returned by Verify when bytecode is malformed.
+// Offset is the byte offset of the offending instruction.
+type VerificationError struct {
+	Offset  int
+	Message string
+}
+
+func (e VerificationError) Error() string {
+	return fmt.Sprintf("💥 VerificationError at offset %d: %s", e.Offset, e.Message)
+}
@@ -0,0 +1,46 @@
+package compiler
+
+import (
+	"nilan/lexer"
+	"nilan/parser"
+	"testing"
+)
+
+// FuzzCompileAST checks that the ASTCompiler never panics and reports every failure as an error.
+// Any bytecode it compiles successfully must pass verification.
+func FuzzCompileAST(f *testing.F) {
+	seeds := []string{
+		"1 + 2 * 3",
+		"var a = 1\nprint a",
+		"var a\nprint a",
+		"print b",
+		"var a = 1\nvar a = 2",
+		"{ var a = 1 { var a = a } }",
+		"if a > 1 { print a } else { print b }",
+		"var n = 0\nwhile n < 10 { n = n + 1 }",
+		"true and false or null",
+		"try { throw 1 } catch (e) { print e } finally { print 2 }",
+		"{ var a = 1 try { var b = a / 0 } finally { print a } }",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, source string) {
+		tokens, lexErrs := lexer.New(source).Scan()
+		if len(lexErrs) > 0 {
+			return
+		}
+		statements, parseErrs := parser.Make(tokens).Parse()
+		if len(parseErrs) > 0 {
+			return
+		}
+		bytecode, errs := NewASTCompiler().CompileAST(statements)
+		if len(errs) > 0 {
+			return
+		}
+		if err := Verify(bytecode); err != nil {
+			t.Fatalf("compiled bytecode failed verification: %v", err)
+		}
+	})
+}
@@ -55,9 +55,9 @@ func TestFullPipeline(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			lex := lexer.New(tt.source)
-			tokens, err := lex.Scan()
-			if err != nil {
-				t.Fatalf("lexing failed: %v", err)
+			tokens, lexErrs := lex.Scan()
+			if len(lexErrs) > 0 {
+				t.Fatalf("lexing failed: %v", lexErrs)
 			}
 
 			parser := parser.Make(tokens)
@@ -67,9 +67,9 @@ func TestFullPipeline(t *testing.T) {
 			}
 
 			compiler := NewASTCompiler()
-			bytecode, err := compiler.CompileAST(statements)
-			if err != nil {
-				t.Fatalf("compilation failed: %v", err)
+			bytecode, errs := compiler.CompileAST(statements)
+			if len(errs) > 0 {
+				t.Fatalf("compilation failed: %v", errs)
 			}
 
 			// Verify the bytecode matches expected
@@ -105,7 +105,7 @@ func TestPipelineWithParser(t *testing.T) {
 
 	binaryExpr := ast.Binary{
 		Left:     five,
-		Operator: token.CreateToken(token.MULT, 0, 0),
+		Operator: token.CreateToken(token.MULT),
 		Right:    three,
 	}
 
@@ -117,9 +117,9 @@ func TestPipelineWithParser(t *testing.T) {
 
 	// Compile the AST to bytecode
 	compiler := NewASTCompiler()
-	bytecode, err := compiler.CompileAST(statements)
-	if err != nil {
-		t.Fatalf("compilation failed: %v", err)
+	bytecode, errs := compiler.CompileAST(statements)
+	if len(errs) > 0 {
+		t.Fatalf("compilation failed: %v", errs)
 	}
 
 	// Verify the bytecode is correct for 5 * 3
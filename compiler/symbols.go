@@ -0,0 +1,47 @@
+package compiler
+
+import "nilan/token"
+
+// Symbol is a variable declared in the compiled program.
+type Symbol struct {
+	// The identifier token of the variable's declaration.
+	Name token.Token
+	// Whether the variable is declared in the global scope.
+	Global bool
+}
+
+// Reference is a use of a variable, e.g reading or assigning it, resolved to the variable's declaration.
+type Reference struct {
+	// The identifier token where the variable is used.
+	Name token.Token
+	// The identifier token of the variable's declaration.
+	Declaration token.Token
+}
+
+// SymbolTable records the variables declared in a program and how every use of a variable
+// was resolved by the ASTCompiler's scope resolution. It is used by tooling such as the
+// language server to implement go-to-definition.
+type SymbolTable struct {
+	// Declared variables, in the order they are declared.
+	Symbols []Symbol
+	// Resolved uses of variables, in the order they are compiled.
+	References []Reference
+}
+
+// Symbols returns the symbol table of everything compiled so far.
+//
+// NOTE: When compilation fails with a SemanticError the symbol table is still populated
+// with the symbols resolved before the error was raised.
+func (ac *ASTCompiler) Symbols() SymbolTable {
+	return ac.symbols
+}
+
+// addSymbol records the declaration of a variable.
+func (ac *ASTCompiler) addSymbol(name token.Token, global bool) {
+	ac.symbols.Symbols = append(ac.symbols.Symbols, Symbol{Name: name, Global: global})
+}
+
+// addReference records that the identifier `name` was resolved to the variable declared by `declaration`.
+func (ac *ASTCompiler) addReference(name token.Token, declaration token.Token) {
+	ac.symbols.References = append(ac.symbols.References, Reference{Name: name, Declaration: declaration})
+}
@@ -0,0 +1,62 @@
+package compiler
+
+import (
+	"nilan/lexer"
+	"nilan/parser"
+	"testing"
+)
+
+func TestASTCompilerSymbols(t *testing.T) {
+	source := "var a = 1\n{ var b = a + 1 b = 2 }\ntry { print a } catch (e) { print e }"
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("lexing error: %v", lexErrs)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("parsing errors: %v", parseErrs)
+	}
+	astCompiler := NewASTCompiler()
+	if _, errs := astCompiler.CompileAST(statements); len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	symbols := astCompiler.Symbols()
+
+	wantSymbols := []struct {
+		name   string
+		line   int
+		global bool
+	}{
+		{name: "a", line: 1, global: true},
+		{name: "b", line: 2, global: false},
+		{name: "e", line: 3, global: false},
+	}
+	if len(symbols.Symbols) != len(wantSymbols) {
+		t.Fatalf("got %d symbols, want %d", len(symbols.Symbols), len(wantSymbols))
+	}
+	for i, symbol := range symbols.Symbols {
+		want := wantSymbols[i]
+		if symbol.Name.Lexeme != want.name || symbol.Name.Start.Line != want.line || symbol.Global != want.global {
+			t.Errorf("symbol %d - got: %+v, want: %+v", i, symbol, want)
+		}
+	}
+
+	wantReferences := []struct {
+		line            int
+		declarationLine int
+	}{
+		{line: 2, declarationLine: 1},
+		{line: 2, declarationLine: 2},
+		{line: 3, declarationLine: 1},
+		{line: 3, declarationLine: 3},
+	}
+	if len(symbols.References) != len(wantReferences) {
+		t.Fatalf("got %d references, want %d", len(symbols.References), len(wantReferences))
+	}
+	for i, reference := range symbols.References {
+		want := wantReferences[i]
+		if reference.Name.Start.Line != want.line || reference.Declaration.Start.Line != want.declarationLine {
+			t.Errorf("reference %d - got: %+v, want: %+v", i, reference, want)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package compiler
 
 import (
 	"nilan/ast"
+	"nilan/diagnostics"
+	"nilan/lexer"
+	"nilan/parser"
 	"nilan/token"
 	"testing"
 )
@@ -15,39 +18,55 @@ func TestCompilerVariableBehavior(t *testing.T) {
 		{
 			name: "var declared without initializer then accessed -> error",
 			statements: []ast.Stmt{
-				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0)},
-				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0)}},
+				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a")},
+				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a")}},
 			},
 			hasError: true,
 		},
 		{
 			name: "var declared with initializer then accessed -> success",
 			statements: []ast.Stmt{
-				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0), Initializer: ast.Literal{Value: int64(0)}},
-				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0)}},
+				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a"), Initializer: ast.Literal{Value: int64(0)}},
+				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a")}},
 			},
 			hasError: false,
 		},
 		{
 			name: "access undeclared variable -> error",
 			statements: []ast.Stmt{
-				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "c", 0, 0)}},
+				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "c")}},
 			},
 			hasError: true,
 		},
 		{
 			name: "redeclaration of variable -> error",
 			statements: []ast.Stmt{
-				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0)},
-				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0), Initializer: ast.Literal{Value: int64(9)}},
+				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a")},
+				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a"), Initializer: ast.Literal{Value: int64(9)}},
+			},
+			hasError: true,
+		},
+		{
+			name: "builtin used then redeclared -> success",
+			statements: []ast.Stmt{
+				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "abs")}},
+				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "abs"), Initializer: ast.Literal{Value: int64(1)}},
+				ast.PrintStmt{Expression: ast.Variable{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "abs")}},
+			},
+			hasError: false,
+		},
+		{
+			name: "assignment to builtin -> error",
+			statements: []ast.Stmt{
+				ast.ExpressionStmt{Expression: ast.Assign{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "abs"), Value: ast.Literal{Value: int64(1)}}},
 			},
 			hasError: true,
 		},
 		{
 			name: "assignment to existing variable -> success",
 			statements: []ast.Stmt{
-				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0)},
-				ast.ExpressionStmt{Expression: ast.Assign{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a", 0, 0), Value: ast.Literal{Value: int64(1)}}},
+				ast.VarStmt{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a")},
+				ast.ExpressionStmt{Expression: ast.Assign{Name: token.CreateLiteralToken(token.IDENTIFIER, nil, "a"), Value: ast.Literal{Value: int64(1)}}},
 			},
 			hasError: false,
 		},
@@ -56,13 +75,72 @@ func TestCompilerVariableBehavior(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			compiler := NewASTCompiler()
-			_, err := compiler.CompileAST(tt.statements)
-			if tt.hasError && err == nil {
+			_, errs := compiler.CompileAST(tt.statements)
+			if tt.hasError && len(errs) == 0 {
 				t.Errorf("expected error but got nil")
 			}
-			if !tt.hasError && err != nil {
-				t.Errorf("unexpected compilation error: %s", err.Error())
+			if !tt.hasError && len(errs) > 0 {
+				t.Errorf("unexpected compilation error: %s", errs)
 			}
 		})
 	}
 }
+
+func TestCompilerReportsAllSemanticErrors(t *testing.T) {
+	source := `print a
+var b
+print b
+{
+    var c = 1
+    var c = 2
+    d = c
+}
+var e = 1
+var e = 2
+print f + g
+h += 1
+b -= 1`
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("lexing error: %v", lexErrs)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("parsing errors: %v", parseErrs)
+	}
+
+	_, errs := NewASTCompiler().CompileAST(statements)
+	want := []struct {
+		message string
+		name    string
+		line    int
+		code    string
+	}{
+		{message: "name 'a' is not defined", name: "a", line: 1, code: diagnostics.CodeUndefined},
+		{message: "Cant access uninitialised variable 'b'", name: "b", line: 3, code: diagnostics.CodeUninitialised},
+		{message: "Redefinition of variable 'c'", name: "c", line: 6, code: diagnostics.CodeRedefinition},
+		{message: "name 'd' is not defined", name: "d", line: 7, code: diagnostics.CodeUndefined},
+		{message: "Redefinition of variable 'e'", name: "e", line: 10, code: diagnostics.CodeRedefinition},
+		{message: "name 'f' is not defined", name: "f", line: 11, code: diagnostics.CodeUndefined},
+		{message: "name 'g' is not defined", name: "g", line: 11, code: diagnostics.CodeUndefined},
+		{message: "name 'h' is not defined", name: "h", line: 12, code: diagnostics.CodeUndefined},
+		{message: "Cant access uninitialised variable 'b'", name: "b", line: 13, code: diagnostics.CodeUninitialised},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors: %v, want: %d", len(errs), errs, len(want))
+	}
+	for i, w := range want {
+		semanticErr, ok := errs[i].(SemanticError)
+		if !ok {
+			t.Fatalf("expected a SemanticError, got %T", errs[i])
+		}
+		if semanticErr.Message != w.message || semanticErr.Token.Lexeme != w.name || semanticErr.Token.Start.Line != w.line {
+			t.Errorf("got error: %q at '%s' on line %d, want: %q at '%s' on line %d",
+				semanticErr.Message, semanticErr.Token.Lexeme, semanticErr.Token.Start.Line, w.message, w.name, w.line)
+		}
+		diagnostic := semanticErr.Diagnostic()
+		if diagnostic.Code != w.code || diagnostic.Start != semanticErr.Token.Start || diagnostic.Help == "" {
+			t.Errorf("got diagnostic: %+v, want code: %s at %s with help", diagnostic, w.code, semanticErr.Token.Start)
+		}
+	}
+}
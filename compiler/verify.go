@@ -0,0 +1,219 @@
+package compiler
+
+// This file implements the bytecode verifier, which checks that bytecode is well formed
+// before it is executed by the VM.
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Verify checks that the bytecode can be safely executed by the VM.
+// Bytecode produced by the ASTCompiler always passes verification, however
+// bytecode loaded from disk could have been corrupted or crafted by hand.
+//
+// The following properties are checked:
+//   - Every opcode is defined in `definitions` and its operands are not truncated.
+//   - Operands referencing the `ConstantsPool` or `NameConstants` are in range.
+//   - Jump targets and the targets of exception handlers land on the first byte of an instruction.
+//   - OP_TRY_BEGIN operands reference a handler in `Handlers`.
+//   - The stack depth never drops below zero, is the same on every path reaching an instruction,
+//     local variable slots are within the stack and execution cannot run past the last instruction
+//     without reaching OP_END.
+//
+// It returns a VerificationError describing the first problem found.
+func Verify(bytecode Bytecode) error {
+	instructions := bytecode.Instructions
+	if len(instructions) == 0 {
+		return VerificationError{Offset: 0, Message: "bytecode is empty, expected OP_END"}
+	}
+
+	// isBoundary[ip] is true when an instruction starts at the byte offset `ip`.
+	isBoundary := make([]bool, len(instructions))
+	jumps := []int{}
+
+	for ip := 0; ip < len(instructions); {
+		op := Opcode(instructions[ip])
+		width := InstructionWidth(op)
+		if width == 0 {
+			return VerificationError{Offset: ip, Message: fmt.Sprintf("unknown opcode %d", op)}
+		}
+		if ip+width > len(instructions) {
+			return VerificationError{Offset: ip, Message: fmt.Sprintf("truncated %s instruction", definitions[op].Name)}
+		}
+		isBoundary[ip] = true
+
+		switch op {
+		case OP_CONSTANT:
+			if index := readOperand(instructions, ip); index >= len(bytecode.ConstantsPool) {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("constant index %d is out of range, the constants pool has %d values", index, len(bytecode.ConstantsPool))}
+			}
+		case OP_GET_PROPERTY, OP_SET_PROPERTY:
+			index := readOperand(instructions, ip)
+			if index >= len(bytecode.ConstantsPool) {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("constant index %d is out of range, the constants pool has %d values", index, len(bytecode.ConstantsPool))}
+			}
+			if _, ok := bytecode.ConstantsPool[index].(string); !ok {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("constant %d is not a property name", index)}
+			}
+		case OP_IMPORT:
+			index := readOperand(instructions, ip)
+			if index >= len(bytecode.ConstantsPool) {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("constant index %d is out of range, the constants pool has %d values", index, len(bytecode.ConstantsPool))}
+			}
+			if _, ok := bytecode.ConstantsPool[index].(string); !ok {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("constant %d is not a module path", index)}
+			}
+		case OP_GET_GLOBAL, OP_SET_GLOBAL:
+			if index := readOperand(instructions, ip); index >= len(bytecode.NameConstants) {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("name constant index %d is out of range, there are %d name constants", index, len(bytecode.NameConstants))}
+			}
+		case OP_JUMP, OP_JUMP_IF_FALSE:
+			jumps = append(jumps, ip)
+		case OP_TRY_BEGIN:
+			if index := readOperand(instructions, ip); index >= len(bytecode.Handlers) {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("handler index %d is out of range, there are %d handlers", index, len(bytecode.Handlers))}
+			}
+		}
+		ip += width
+	}
+
+	for _, ip := range jumps {
+		target := readOperand(instructions, ip)
+		if target >= len(instructions) || !isBoundary[target] {
+			return VerificationError{Offset: ip, Message: fmt.Sprintf("jump target %d is not the start of an instruction", target)}
+		}
+	}
+
+	for i, handler := range bytecode.Handlers {
+		if handler.Target >= len(instructions) || !isBoundary[handler.Target] {
+			return VerificationError{Offset: handler.Target, Message: fmt.Sprintf("target %d of handler %d is not the start of an instruction", handler.Target, i)}
+		}
+	}
+
+	return verifyStackDepth(bytecode)
+}
+
+// verifyStackDepth follows every path through the instructions, tracking the number of values
+// on the stack before each instruction is executed.
+// It assumes every instruction and exception handler has already been decoded successfully.
+func verifyStackDepth(bytecode Bytecode) error {
+	instructions := bytecode.Instructions
+	// depths[ip] is the stack depth before executing the instruction at `ip`,
+	// or -1 if the instruction has not been reached yet.
+	depths := make([]int, len(instructions))
+	for i := range depths {
+		depths[i] = -1
+	}
+	depths[0] = 0
+	worklist := []int{0}
+
+	// visit records the stack depth an instruction is reached with.
+	visit := func(next int, depth int) error {
+		switch depths[next] {
+		case -1:
+			depths[next] = depth
+			worklist = append(worklist, next)
+		case depth:
+		default:
+			return VerificationError{Offset: next, Message: fmt.Sprintf("stack is not balanced, the instruction is reached with %d and %d values on the stack", depths[next], depth)}
+		}
+		return nil
+	}
+
+	for len(worklist) > 0 {
+		ip := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+
+		op := Opcode(instructions[ip])
+		depth := depths[ip]
+		operand := 0
+		if InstructionWidth(op) == THREE_BYTE_INSTRUCTION_LENGTH {
+			operand = readOperand(instructions, ip)
+		}
+
+		pops, pushes, ok := stackEffect(op, operand)
+		if !ok {
+			return VerificationError{Offset: ip, Message: fmt.Sprintf("%s cannot be executed by the VM", definitions[op].Name)}
+		}
+		if depth < pops {
+			return VerificationError{Offset: ip, Message: fmt.Sprintf("stack underflow, %s needs %d values but the stack has %d", definitions[op].Name, pops, depth)}
+		}
+		if (op == OP_GET_LOCAL || op == OP_SET_LOCAL) && operand >= depth {
+			return VerificationError{Offset: ip, Message: fmt.Sprintf("local variable slot %d is out of range, the stack has %d values", operand, depth)}
+		}
+		depth = depth - pops + pushes
+
+		var successors []int
+		switch op {
+		case OP_END:
+			// NOTE: The value of the last top level expression statement is left on the stack,
+			// so it can be echoed by the VM.
+			if depth > 1 {
+				return VerificationError{Offset: ip, Message: fmt.Sprintf("stack is not balanced, %d values are left on the stack", depth)}
+			}
+		case OP_JUMP:
+			successors = []int{operand}
+		case OP_JUMP_IF_FALSE:
+			successors = []int{ip + THREE_BYTE_INSTRUCTION_LENGTH, operand}
+		case OP_TRY_BEGIN:
+			// When an error is caught, the VM restores the stack to its depth when the handler was
+			// registered and pushes the thrown value before jumping to the handler's target.
+			successors = []int{ip + THREE_BYTE_INSTRUCTION_LENGTH}
+			if err := visit(bytecode.Handlers[operand].Target, depth+1); err != nil {
+				return err
+			}
+		case OP_THROW:
+			// Execution continues at the target of a handler, which is verified by OP_TRY_BEGIN.
+		default:
+			successors = []int{ip + InstructionWidth(op)}
+		}
+
+		for _, next := range successors {
+			if next >= len(instructions) {
+				return VerificationError{Offset: ip, Message: "execution runs past the end of the bytecode without reaching OP_END"}
+			}
+			if err := visit(next, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stackEffect returns the number of values an instruction pops from the stack and the number
+// of values it pushes afterwards. Instructions which only peek at the top of the stack are
+// modelled as popping and pushing the value back.
+// It returns false if the VM does not implement the opcode.
+func stackEffect(op Opcode, operand int) (pops int, pushes int, ok bool) {
+	switch op {
+	case OP_END, OP_JUMP, OP_TRY_BEGIN, OP_TRY_END:
+		return 0, 0, true
+	case OP_CONSTANT, OP_GET_GLOBAL, OP_GET_LOCAL, OP_IMPORT:
+		return 0, 1, true
+	case OP_ADD, OP_SUBTRACT, OP_MULTIPLY, OP_DIVIDE, OP_MODULO, OP_POWER, OP_FLOOR_DIVIDE,
+		OP_BITWISE_AND, OP_BITWISE_OR, OP_BITWISE_XOR, OP_SHIFT_LEFT, OP_SHIFT_RIGHT,
+		OP_EQUALITY, OP_NOT_EQUAL, OP_LARGER, OP_LESS, OP_LARGER_EQUAL, OP_LESS_EQUAL:
+		return 2, 1, true
+	case OP_NEGATE, OP_NOT, OP_BITWISE_NOT, OP_SET_GLOBAL, OP_SET_LOCAL, OP_JUMP_IF_FALSE, OP_GET_PROPERTY:
+		return 1, 1, true
+	case OP_PRINT, OP_POP, OP_THROW:
+		return 1, 0, true
+	case OP_SET_PROPERTY:
+		return 2, 1, true
+	case OP_DUP:
+		return 1, 2, true
+	case OP_SCOPE_EXIT:
+		return operand, 0, true
+	case OP_BUILD_STRING:
+		return operand, 1, true
+	case OP_CALL:
+		return operand + 1, 1, true
+	}
+	return 0, 0, false
+}
+
+// readOperand decodes the 2 byte operand of the instruction starting at `ip`.
+func readOperand(instructions Instructions, ip int) int {
+	return int(binary.BigEndian.Uint16(instructions[ip+OPCODE_TOTAL_BYTES:]))
+}
@@ -0,0 +1,250 @@
+package compiler
+
+import (
+	"fmt"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/token"
+	"nilan/value"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCompiledPrograms(t *testing.T) {
+	programs := []string{
+		"1 + 2",
+		"var a = 1\nprint a\na = a + 1",
+		"var a\nvar b\na = b = 10\nprint a",
+		"{ var x = 1 { var y = x + 1 print y } x = 3 }",
+		"var i = 0\nwhile i < 10 { var j = i * 2 i = i + 1 }",
+		"if 1 > 2 { print 1 } else { var z = 2 print z }",
+		"print true and false or !null",
+		"try { throw 1 } catch (e) { print e }",
+		"{ var a = 1 try { var b = 2 } catch (e) { var c = e } finally { var d = a } }",
+		"var i = 0\nwhile i < 3 { try { i = i + 1 } finally { print i } }",
+		"var a = 1\nprint \"${a} and ${\"${a + 1}\"}\"",
+		"print max(1, sqrt(4), math.pi)\n{ var f = math.floor print f(2.5) }",
+		"struct P { x, y }\nvar p = P(1, 2)\np.x = 3\np.y += p.x\n{ struct Q {} print Q() }",
+	}
+	for _, source := range programs {
+		t.Run(source, func(t *testing.T) {
+			tokens, lexErrs := lexer.New(source).Scan()
+			if len(lexErrs) > 0 {
+				t.Fatalf("lexing error: %v", lexErrs)
+			}
+			statements, parseErrs := parser.Make(tokens).Parse()
+			if len(parseErrs) > 0 {
+				t.Fatalf("parsing errors: %v", parseErrs)
+			}
+			bytecode, errs := NewASTCompiler().CompileAST(statements)
+			if len(errs) > 0 {
+				t.Fatalf("compilation error: %v", errs)
+			}
+			if err := Verify(bytecode); err != nil {
+				t.Errorf("got error: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformedBytecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytecode Bytecode
+		want     string
+	}{
+		{
+			name:     "empty",
+			bytecode: Bytecode{},
+			want:     "bytecode is empty",
+		},
+		{
+			name:     "unknown opcode",
+			bytecode: Bytecode{Instructions: []byte{255, byte(OP_END)}},
+			want:     "unknown opcode 255",
+		},
+		{
+			name:     "truncated instruction",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0}},
+			want:     "truncated OP_CONSTANT",
+		},
+		{
+			name:     "constant out of range",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0, 1, byte(OP_END)}, ConstantsPool: []any{int64(1)}},
+			want:     "constant index 1 is out of range",
+		},
+		{
+			name:     "property name is not a string",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0, 0, byte(OP_GET_PROPERTY), 0, 0, byte(OP_END)}, ConstantsPool: []any{int64(1)}},
+			want:     "constant 0 is not a property name",
+		},
+		{
+			name:     "call without a callee",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0, 0, byte(OP_CALL), 0, 1, byte(OP_END)}, ConstantsPool: []any{int64(1)}},
+			want:     "stack underflow, OP_CALL needs 2 values but the stack has 1",
+		},
+		{
+			name:     "name constant out of range",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_GET_GLOBAL), 0, 0, byte(OP_END)}},
+			want:     "name constant index 0 is out of range",
+		},
+		{
+			name: "jump into the middle of an instruction",
+			bytecode: Bytecode{
+				Instructions:  []byte{byte(OP_JUMP), 0, 4, byte(OP_CONSTANT), 0, 0, byte(OP_END)},
+				ConstantsPool: []any{int64(1)},
+			},
+			want: "jump target 4 is not the start of an instruction",
+		},
+		{
+			name:     "jump past the end",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_JUMP), 0, 9, byte(OP_END)}},
+			want:     "jump target 9 is not the start of an instruction",
+		},
+		{
+			name:     "missing OP_END",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0, 0}, ConstantsPool: []any{int64(1)}},
+			want:     "without reaching OP_END",
+		},
+		{
+			name:     "stack underflow",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0, 0, byte(OP_ADD), byte(OP_END)}, ConstantsPool: []any{int64(1)}},
+			want:     "stack underflow",
+		},
+		{
+			name:     "local slot out of range",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_GET_LOCAL), 0, 0, byte(OP_END)}},
+			want:     "local variable slot 0 is out of range",
+		},
+		{
+			// The loop pushes a value on every iteration.
+			name: "unbalanced loop",
+			bytecode: Bytecode{
+				Instructions:  []byte{byte(OP_CONSTANT), 0, 0, byte(OP_JUMP_IF_FALSE), 0, 9, byte(OP_JUMP), 0, 0, byte(OP_END)},
+				ConstantsPool: []any{true},
+			},
+			want: "stack is not balanced",
+		},
+		{
+			name:     "values left on the stack",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_CONSTANT), 0, 0, byte(OP_CONSTANT), 0, 0, byte(OP_END)}, ConstantsPool: []any{int64(1)}},
+			want:     "2 values are left on the stack",
+		},
+		{
+			name:     "handler out of range",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_TRY_BEGIN), 0, 0, byte(OP_TRY_END), byte(OP_END)}},
+			want:     "handler index 0 is out of range",
+		},
+		{
+			name: "handler target in the middle of an instruction",
+			bytecode: Bytecode{
+				Instructions: []byte{byte(OP_TRY_BEGIN), 0, 0, byte(OP_TRY_END), byte(OP_END)},
+				Handlers:     []ExceptionHandler{{Start: 3, End: 3, Target: 1}},
+			},
+			want: "target 1 of handler 0 is not the start of an instruction",
+		},
+		{
+			// The handler target is reached with the thrown value on the stack, which is never popped.
+			name: "unbalanced handler",
+			bytecode: Bytecode{
+				Instructions: []byte{byte(OP_TRY_BEGIN), 0, 0, byte(OP_TRY_END), byte(OP_JUMP), 0, 7, byte(OP_END)},
+				Handlers:     []ExceptionHandler{{Start: 3, End: 3, Target: 7}},
+			},
+			want: "stack is not balanced",
+		},
+		{
+			name:     "opcode not implemented by the VM",
+			bytecode: Bytecode{Instructions: []byte{byte(OP_AND), byte(OP_END)}},
+			want:     "OP_AND cannot be executed by the VM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.bytecode)
+			if err == nil {
+				t.Fatalf("expected an error containing %q, got nil", tt.want)
+			}
+			if _, ok := err.(VerificationError); !ok {
+				t.Errorf("expected a VerificationError, got %T", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error: %v, want it to contain: %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBytecodeMarshalRoundTrip(t *testing.T) {
+	want := Bytecode{
+		Instructions:  []byte{byte(OP_CONSTANT), 0, 0, byte(OP_SET_GLOBAL), 0, 0, byte(OP_POP), byte(OP_END)},
+		ConstantsPool: []any{int64(-42), 1.5, true, false, "nilan", nil},
+		NameConstants: []string{"a", "b"},
+		Handlers:      []ExceptionHandler{{Start: 3, End: 6, Target: 7}},
+		Lines: []LineEntry{
+			{Offset: 0, Start: token.Position{Line: 1, Column: 1, Offset: 0}, End: token.Position{Line: 1, Column: 2, Offset: 1}},
+			{Offset: 6, Start: token.Position{Line: 2, Column: 5, Offset: 14}, End: token.Position{Line: 2, Column: 8, Offset: 17}},
+		},
+	}
+	data, err := want.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	var got Bytecode
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	assertBytecodeEquals(t, got, want)
+	if strings.Join(got.NameConstants, ",") != strings.Join(want.NameConstants, ",") {
+		t.Errorf("got name constants: %v, want: %v", got.NameConstants, want.NameConstants)
+	}
+	if fmt.Sprint(got.Handlers) != fmt.Sprint(want.Handlers) {
+		t.Errorf("got handlers: %v, want: %v", got.Handlers, want.Handlers)
+	}
+	if fmt.Sprint(got.Lines) != fmt.Sprint(want.Lines) {
+		t.Errorf("got line table: %v, want: %v", got.Lines, want.Lines)
+	}
+
+	// Every truncation of the encoded bytecode must be rejected.
+	for i := 0; i < len(data); i++ {
+		var truncated Bytecode
+		if err := truncated.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("expected an error decoding %d of %d bytes", i, len(data))
+		}
+	}
+}
+
+func TestBytecodeMarshalDecimalConstant(t *testing.T) {
+	decimal, _ := value.ParseDecimal("-12.50")
+	data, err := Bytecode{Instructions: []byte{byte(OP_END)}, ConstantsPool: []any{decimal}}.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var got Bytecode
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	// NOTE: Decimals are compared by their text, as they hold a pointer.
+	if constant, ok := got.ConstantsPool[0].(value.Decimal); !ok || constant.String() != "-12.50" {
+		t.Errorf("got constant: %v (%T), want the decimal -12.50", got.ConstantsPool[0], got.ConstantsPool[0])
+	}
+}
+
+func TestBytecodeMarshalStructConstant(t *testing.T) {
+	structure := &value.Struct{Name: "Point", Fields: []string{"x", "y"}}
+	data, err := Bytecode{Instructions: []byte{byte(OP_END)}, ConstantsPool: []any{structure, &value.Struct{Name: "Empty"}}}.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var got Bytecode
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	for i, want := range []string{"Point(x,y)", "Empty()"} {
+		constant, ok := got.ConstantsPool[i].(*value.Struct)
+		if !ok || fmt.Sprintf("%s(%s)", constant.Name, strings.Join(constant.Fields, ",")) != want {
+			t.Errorf("got constant %d: %v (%T), want the struct %s", i, got.ConstantsPool[i], got.ConstantsPool[i], want)
+		}
+	}
+}
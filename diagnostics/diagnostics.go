@@ -0,0 +1,196 @@
+// Package diagnostics describes errors found in Nilan source code and renders them for humans,
+// with the offending source line and a caret underlining the code, or as JSON for tooling.
+//
+// Errors raised by the lexer, parser, ASTCompiler and VM implement the `Reporter` interface
+// to describe themselves as a Diagnostic.
+package diagnostics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nilan/token"
+	"strconv"
+	"strings"
+)
+
+// Severity is how serious a diagnostic is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Error codes identify the kind of a diagnostic. The first digit is the stage of the
+// pipeline which reports it: 1 for the lexer, 2 for the parser, 3 for the ASTCompiler,
+// 4 for the VM and 9 for bugs in Nilan itself.
+const (
+	CodeLexical             = "E0100"
+	CodeUnexpectedCharacter = "E0101"
+	CodeInvalidNumber       = "E0102"
+	CodeUnterminatedString  = "E0103"
+	CodeInvalidEscape       = "E0104"
+	CodeEmptyInterpolation  = "E0105"
+	CodeNumberOutOfRange    = "E0106"
+	CodeSyntax              = "E0200"
+	CodeSemantic            = "E0300"
+	CodeUndefined           = "E0301"
+	CodeUninitialised       = "E0302"
+	CodeRedefinition        = "E0303"
+	CodeLimit               = "E0304"
+	CodeRuntime             = "E0400"
+	CodeUncaught            = "E0401"
+	CodeInternal            = "E0900"
+)
+
+// Diagnostic is a problem found in the source code.
+//
+// Fields:
+//   - Severity: Whether the diagnostic is an error or a warning.
+//   - Code: Identifies the kind of problem, one of the `Code` constants.
+//   - Message: Describes the problem.
+//   - Help: Optionally suggests how to fix the problem.
+//   - Start: The position of the first character of the offending code. Its Line is 0 when the
+//     position is unknown and its Column is 0 when only the line is known.
+//   - End: The position after the last character of the offending code, it can be equal to Start.
+type Diagnostic struct {
+	Severity Severity       `json:"severity"`
+	Code     string         `json:"code"`
+	Message  string         `json:"message"`
+	Help     string         `json:"help,omitempty"`
+	Start    token.Position `json:"start"`
+	End      token.Position `json:"end"`
+}
+
+// Reporter is implemented by errors which can describe themselves as a Diagnostic.
+type Reporter interface {
+	Diagnostic() Diagnostic
+}
+
+// FromError returns the Diagnostic describing the error. Errors which do not implement
+// `Reporter` are described by their message, without a position.
+func FromError(err error) Diagnostic {
+	var reporter Reporter
+	if errors.As(err, &reporter) {
+		return reporter.Diagnostic()
+	}
+	return Diagnostic{Severity: SeverityError, Code: CodeInternal, Message: err.Error()}
+}
+
+// FromErrors returns the diagnostics describing the errors.
+func FromErrors(errs []error) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0, len(errs))
+	for _, err := range errs {
+		diagnostics = append(diagnostics, FromError(err))
+	}
+	return diagnostics
+}
+
+// Render formats the diagnostic for humans. The source line containing the diagnostic is shown
+// with the offending code underlined. `file` is the name shown for the source code, it can be empty.
+//
+// Example:
+//
+//	error[E0301]: name 'b' is not defined
+//	 --> main.ni:2:7
+//	  |
+//	2 | print b + 1
+//	  |       ^
+//	  = help: declare the variable with `var b = ...` before using it
+func (d Diagnostic) Render(file string, source string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s[%s]: %s\n", d.Severity, d.Code, d.Message)
+
+	lines := strings.Split(source, "\n")
+	if d.Start.Line < 1 || d.Start.Line > len(lines) {
+		if file != "" {
+			fmt.Fprintf(&builder, " --> %s\n", file)
+		}
+		d.renderHelp(&builder, "")
+		return builder.String()
+	}
+
+	location := strconv.Itoa(d.Start.Line)
+	if d.Start.Column > 0 {
+		location += ":" + strconv.Itoa(d.Start.Column)
+	}
+	if file != "" {
+		location = file + ":" + location
+	}
+	line := []rune(strings.TrimRight(lines[d.Start.Line-1], "\r"))
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Start.Line)))
+	fmt.Fprintf(&builder, "%s--> %s\n", gutter, location)
+	fmt.Fprintf(&builder, "%s |\n", gutter)
+	fmt.Fprintf(&builder, "%d | %s\n", d.Start.Line, string(line))
+	fmt.Fprintf(&builder, "%s | %s\n", gutter, underline(line, d.Start, d.End))
+	d.renderHelp(&builder, gutter)
+	return builder.String()
+}
+
+func (d Diagnostic) renderHelp(builder *strings.Builder, gutter string) {
+	if d.Help != "" {
+		fmt.Fprintf(builder, "%s = help: %s\n", gutter, d.Help)
+	}
+}
+
+// underline returns the carets marking the code between `start` and `end` on the line.
+// The code is underlined until the end of the line when it spans several lines, and the whole
+// line is underlined when the column is unknown.
+func underline(line []rune, start token.Position, end token.Position) string {
+	first, last := start.Column-1, len(line)
+	switch {
+	case start.Column < 1:
+		first = len(line) - len([]rune(strings.TrimLeft(string(line), " \t")))
+	case end.Line > start.Line:
+		last = len(line)
+	case end.Column > start.Column:
+		last = end.Column - 1
+	default:
+		last = first + 1
+	}
+	// NOTE: At least one caret is shown, even past the end of the line, e.g for a missing '}'.
+	first = min(first, len(line))
+	last = max(last, first+1)
+
+	var builder strings.Builder
+	for _, char := range line[:first] {
+		// NOTE: Tabs are kept so the carets line up with the code.
+		if char == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	builder.WriteString(strings.Repeat("^", last-first))
+	return builder.String()
+}
+
+// RenderAll formats the diagnostics for humans, separated by blank lines.
+func RenderAll(file string, source string, diagnostics []Diagnostic) string {
+	rendered := make([]string, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		rendered = append(rendered, diagnostic.Render(file, source))
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// fileDiagnostic is the JSON representation of a diagnostic, including the file it was found in.
+type fileDiagnostic struct {
+	File string `json:"file,omitempty"`
+	Diagnostic
+}
+
+// JSON formats the diagnostics as a JSON array for tooling. `file` is added to every
+// diagnostic, it can be empty.
+func JSON(file string, diagnostics []Diagnostic) (string, error) {
+	entries := make([]fileDiagnostic, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		entries = append(entries, fileDiagnostic{File: file, Diagnostic: diagnostic})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,216 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nilan/token"
+	"testing"
+)
+
+func position(line int, column int) token.Position {
+	return token.Position{Line: line, Column: column}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		source     string
+		diagnostic Diagnostic
+		want       string
+	}{
+		{
+			name:   "span on one line",
+			file:   "main.ni",
+			source: "var a = 1\nprint b + a",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeUndefined,
+				Message:  "name 'b' is not defined",
+				Start:    position(2, 7),
+				End:      position(2, 8),
+			},
+			want: "error[E0301]: name 'b' is not defined\n" +
+				" --> main.ni:2:7\n" +
+				"  |\n" +
+				"2 | print b + a\n" +
+				"  |       ^\n",
+		},
+		{
+			name:   "help and a wide span",
+			file:   "main.ni",
+			source: "var abc\nvar abc = 2",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeRedefinition,
+				Message:  "Redefinition of variable 'abc'",
+				Help:     "use an assignment instead",
+				Start:    position(2, 5),
+				End:      position(2, 8),
+			},
+			want: "error[E0303]: Redefinition of variable 'abc'\n" +
+				" --> main.ni:2:5\n" +
+				"  |\n" +
+				"2 | var abc = 2\n" +
+				"  |     ^^^\n" +
+				"  = help: use an assignment instead\n",
+		},
+		{
+			name:   "tabs are kept in the underline",
+			source: "{\n\tprint x\n}",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeUndefined,
+				Message:  "name 'x' is not defined",
+				Start:    position(2, 8),
+				End:      position(2, 9),
+			},
+			want: "error[E0301]: name 'x' is not defined\n" +
+				" --> 2:8\n" +
+				"  |\n" +
+				"2 | \tprint x\n" +
+				"  | \t      ^\n",
+		},
+		{
+			name:   "unknown column underlines the line",
+			file:   "main.ni",
+			source: "var a = 1\n  print a / 0",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeRuntime,
+				Message:  "division by zero",
+				Start:    position(2, 0),
+				End:      position(2, 0),
+			},
+			want: "error[E0400]: division by zero\n" +
+				" --> main.ni:2\n" +
+				"  |\n" +
+				"2 |   print a / 0\n" +
+				"  |   ^^^^^^^^^^^\n",
+		},
+		{
+			name:   "multi-line span is underlined until the end of the line",
+			file:   "main.ni",
+			source: "print 1 +\n2",
+			diagnostic: Diagnostic{
+				Severity: SeverityWarning,
+				Code:     CodeSyntax,
+				Message:  "expression",
+				Start:    position(1, 7),
+				End:      position(2, 2),
+			},
+			want: "warning[E0200]: expression\n" +
+				" --> main.ni:1:7\n" +
+				"  |\n" +
+				"1 | print 1 +\n" +
+				"  |       ^^^\n",
+		},
+		{
+			name:   "span at the end of the line",
+			file:   "main.ni",
+			source: "{",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeSyntax,
+				Message:  "Expected '}' after block.",
+				Start:    position(1, 2),
+				End:      position(1, 2),
+			},
+			want: "error[E0200]: Expected '}' after block.\n" +
+				" --> main.ni:1:2\n" +
+				"  |\n" +
+				"1 | {\n" +
+				"  |  ^\n",
+		},
+		{
+			name:   "unknown position",
+			file:   "main.ni",
+			source: "print 1",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeInternal,
+				Message:  "something went wrong",
+				Help:     "report it",
+			},
+			want: "error[E0900]: something went wrong\n" +
+				" --> main.ni\n" +
+				" = help: report it\n",
+		},
+		{
+			name:   "gutter is as wide as the line number",
+			source: "\n\n\n\n\n\n\n\n\nprint x",
+			diagnostic: Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeUndefined,
+				Message:  "name 'x' is not defined",
+				Start:    position(10, 7),
+				End:      position(10, 8),
+			},
+			want: "error[E0301]: name 'x' is not defined\n" +
+				"  --> 10:7\n" +
+				"   |\n" +
+				"10 | print x\n" +
+				"   |       ^\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.diagnostic.Render(tt.file, tt.source)
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+type reportingError struct{}
+
+func (reportingError) Error() string { return "reporting" }
+
+func (reportingError) Diagnostic() Diagnostic {
+	return Diagnostic{Severity: SeverityError, Code: CodeSyntax, Message: "reported", Start: position(1, 1)}
+}
+
+func TestFromError(t *testing.T) {
+	got := FromError(fmt.Errorf("wrapped: %w", reportingError{}))
+	if got.Code != CodeSyntax || got.Message != "reported" || got.Start != position(1, 1) {
+		t.Errorf("got: %+v, want the diagnostic of the wrapped error", got)
+	}
+
+	got = FromError(errors.New("plain"))
+	if got.Code != CodeInternal || got.Message != "plain" || got.Start.Line != 0 {
+		t.Errorf("got: %+v, want an internal diagnostic without a position", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	diagnostics := []Diagnostic{
+		{Severity: SeverityError, Code: CodeUndefined, Message: "name 'b' is not defined", Start: position(2, 7), End: position(2, 8)},
+		{Severity: SeverityWarning, Code: CodeSyntax, Message: "no help", Help: "", Start: position(1, 1), End: position(1, 1)},
+	}
+	out, err := JSON("main.ni", diagnostics)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("got %d diagnostics, want: 2", len(decoded))
+	}
+	first := decoded[0]
+	if first["file"] != "main.ni" || first["severity"] != "error" || first["code"] != "E0301" {
+		t.Errorf("got: %v", first)
+	}
+	start, ok := first["start"].(map[string]any)
+	if !ok || start["line"] != float64(2) || start["column"] != float64(7) {
+		t.Errorf("got start: %v, want line 2, column 7", first["start"])
+	}
+	if _, ok := decoded[1]["help"]; ok {
+		t.Errorf("empty help should be omitted, got: %v", decoded[1])
+	}
+}
@@ -0,0 +1,127 @@
+// Package differential executes Nilan programs with both the tree-walk interpreter and the
+// ASTCompiler + VM, and records what each engine printed, so their behaviour can be compared
+// against each other and against golden files.
+package differential
+
+import (
+	"fmt"
+	"strings"
+
+	"nilan/compiler"
+	"nilan/interpreter"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/stdlib"
+	"nilan/vm"
+)
+
+// RunVM compiles and executes the source code with the ASTCompiler + VM. It returns a
+// transcript containing everything the program printed followed by the error which
+// terminated it, if any.
+func RunVM(source string) string {
+	var out strings.Builder
+
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		return transcript(&out, lexErrs...)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		return transcript(&out, parseErrs...)
+	}
+	bytecode, compileErrs := compiler.NewASTCompiler().CompileAST(statements)
+	if len(compileErrs) > 0 {
+		return transcript(&out, compileErrs...)
+	}
+	// NOTE: Bytecode emitted by the compiler must always pass verification.
+	if err := compiler.Verify(bytecode); err != nil {
+		return transcript(&out, err)
+	}
+
+	machine := vm.New()
+	machine.SetOutput(&out)
+	if err := machine.Run(bytecode); err != nil {
+		return transcript(&out, err)
+	}
+	return transcript(&out)
+}
+
+// RunTreeWalk executes the source code with the tree-walk interpreter. It returns a
+// transcript containing everything the program printed followed by the error which
+// terminated it, if any.
+//
+// NOTE: The tree-walk interpreter reports runtime errors by printing them, so they are
+// part of the printed output rather than a terminating error.
+func RunTreeWalk(source string) string {
+	var out strings.Builder
+
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		return transcript(&out, lexErrs...)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		return transcript(&out, parseErrs...)
+	}
+
+	interpreter := interpreter.Make()
+	interpreter.SetOutput(&out)
+	interpreter.Interpret(statements)
+	if code, exited := interpreter.ExitCode(); exited {
+		return transcript(&out, stdlib.ExitError{Code: code})
+	}
+	return transcript(&out)
+}
+
+// transcript appends the errors to the printed output.
+func transcript(out *strings.Builder, errs ...error) string {
+	for _, err := range errs {
+		fmt.Fprintf(out, "error: %v\n", err)
+	}
+	return out.String()
+}
+
+// Diff returns a line by line comparison of two transcripts. Lines only present in `want`
+// are prefixed with `-` and lines only present in `got` are prefixed with `+`.
+// It returns an empty string if both transcripts are equal.
+func Diff(want string, got string) string {
+	if want == got {
+		return ""
+	}
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	// lengths[i][j] holds the length of the longest common subsequence
+	// of wantLines[i:] and gotLines[j:].
+	lengths := make([][]int, len(wantLines)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(gotLines)+1)
+	}
+	for i := len(wantLines) - 1; i >= 0; i-- {
+		for j := len(gotLines) - 1; j >= 0; j-- {
+			if wantLines[i] == gotLines[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var builder strings.Builder
+	i, j := 0, 0
+	for i < len(wantLines) || j < len(gotLines) {
+		switch {
+		case i < len(wantLines) && j < len(gotLines) && wantLines[i] == gotLines[j]:
+			builder.WriteString("  " + wantLines[i] + "\n")
+			i++
+			j++
+		case j < len(gotLines) && (i == len(wantLines) || lengths[i][j+1] >= lengths[i+1][j]):
+			builder.WriteString("+ " + gotLines[j] + "\n")
+			j++
+		default:
+			builder.WriteString("- " + wantLines[i] + "\n")
+			i++
+		}
+	}
+	return builder.String()
+}
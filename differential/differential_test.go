@@ -0,0 +1,125 @@
+package differential
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files with the current output of both engines:
+//
+//	go test ./differential -update
+var update = flag.Bool("update", false, "update the golden files")
+
+// divergences lists the programs the tree-walk interpreter is known to run differently from the
+// VM, with the reason of each divergence. Their expected tree-walk transcript is recorded in
+// `<name>.treewalk.golden`. Any other difference between both engines is a bug, which fails the
+// test and is not recorded by -update.
+var divergences = map[string]string{
+	"json":                   "the tree-walk interpreter prints runtime errors in its own format",
+	"structs":                "the tree-walk interpreter prints runtime errors in its own format",
+	"redeclaration":          "the tree-walk interpreter has no semantic analysis, a redefined variable is assigned instead of reported",
+	"uncaught_exception":     "the tree-walk interpreter prints uncaught exceptions in its own format",
+	"undefined_variable":     "the tree-walk interpreter has no semantic analysis, undefined variables are reported when they are evaluated",
+	"uninitialised_variable": "the tree-walk interpreter has no semantic analysis, uninitialised variables are reported when they are evaluated",
+}
+
+// TestEnginesAgree runs every program in `testdata` through the tree-walk interpreter and the
+// ASTCompiler + VM and compares their transcripts against the golden files.
+//
+// `<name>.golden` holds the expected VM transcript. The tree-walk interpreter is expected to
+// produce the same transcript, unless a `<name>.treewalk.golden` file exists which records
+// a known divergence between both engines, listed in `divergences`.
+func TestEnginesAgree(t *testing.T) {
+	programs, err := filepath.Glob(filepath.Join("testdata", "*.ni"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(programs) == 0 {
+		t.Fatal("no programs found in testdata")
+	}
+
+	for _, program := range programs {
+		name := strings.TrimSuffix(filepath.Base(program), ".ni")
+		t.Run(name, func(t *testing.T) {
+			source, err := os.ReadFile(program)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vmOut := RunVM(string(source))
+			treeWalkOut := RunTreeWalk(string(source))
+
+			goldenPath := filepath.Join("testdata", name+".golden")
+			treeWalkGoldenPath := filepath.Join("testdata", name+".treewalk.golden")
+
+			if *update {
+				writeGolden(t, goldenPath, vmOut)
+				_, diverges := divergences[name]
+				if treeWalkOut != vmOut && diverges {
+					writeGolden(t, treeWalkGoldenPath, treeWalkOut)
+				} else if err := os.Remove(treeWalkGoldenPath); err != nil && !os.IsNotExist(err) {
+					t.Fatal(err)
+				}
+			}
+
+			want := readGolden(t, goldenPath)
+			if diff := Diff(want, vmOut); diff != "" {
+				t.Errorf("VM output differs from %s:\n%s", goldenPath, diff)
+			}
+
+			treeWalkWant := want
+			data, err := os.ReadFile(treeWalkGoldenPath)
+			_, diverges := divergences[name]
+			switch {
+			case err == nil && !diverges:
+				t.Errorf("%s records a divergence without a reason, fix the divergence or list it in divergences", treeWalkGoldenPath)
+			case err == nil:
+				treeWalkWant = string(data)
+			case diverges:
+				t.Errorf("%s is listed in divergences, but %s does not exist", name, treeWalkGoldenPath)
+			}
+			if diff := Diff(treeWalkWant, treeWalkOut); diff != "" {
+				t.Errorf("tree-walk interpreter output differs from the expected output:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{name: "equal", want: "1\n2\n", got: "1\n2\n", diff: ""},
+		{name: "changed line", want: "1\n2\n", got: "1\n3\n", diff: "  1\n+ 3\n- 2\n  \n"},
+		{name: "missing line", want: "1\n2\n", got: "1\n", diff: "  1\n- 2\n  \n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := Diff(tt.want, tt.got); diff != tt.diff {
+				t.Errorf("got:\n%q\nwant:\n%q", diff, tt.diff)
+			}
+		})
+	}
+}
+
+func readGolden(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run `go test ./differential -update` to create it)", err)
+	}
+	return string(data)
+}
+
+func writeGolden(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing golden file: %v", err)
+	}
+}
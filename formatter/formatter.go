@@ -0,0 +1,470 @@
+// Package formatter pretty-prints Nilan source code in a canonical style.
+//
+// The source code is parsed and the AST is printed back to source. Comments are not part of the AST,
+// they are kept as trivia by the lexer and re-inserted before the token they precede, or at the
+// end of the line they trail, so formatting never loses a comment.
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"nilan/ast"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/token"
+	"strings"
+)
+
+// indentation is the string used for each level of indentation.
+const indentation = "    "
+
+// Format parses the source code and prints it in the canonical style:
+//   - one statement per line, indented by four spaces per block,
+//   - opening braces on the same line as their statement,
+//   - a single space around binary operators, assignments and keywords,
+//   - at most one blank line between statements, and none at the start or end of a block,
+//   - a single trailing newline.
+//
+// An error is returned if the source code can not be lexed or parsed.
+func Format(source string) (string, error) {
+	lex := lexer.New(source)
+	tokens, lexErrs := lex.Scan()
+	if len(lexErrs) > 0 {
+		return "", errors.Join(lexErrs...)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		return "", errors.Join(parseErrs...)
+	}
+
+	p := &printer{source: source, tokens: tokens, comments: lex.Comments()}
+	return p.print(statements)
+}
+
+// printer prints the AST back to source. It walks the AST in source order, consuming the
+// source tokens as it prints them. The position of the source tokens is used to place
+// comments and preserve blank lines between statements.
+type printer struct {
+	out strings.Builder
+
+	// The source code, used to print string literals exactly as they are written.
+	source string
+
+	// The source tokens, ending with EOF, and the index of the next token to print.
+	tokens []token.Token
+	next   int
+	// The source comments and the index of the next comment to print.
+	comments    []token.Token
+	nextComment int
+
+	// The current level of indentation.
+	indent int
+	// Whether nothing was written on the current line yet.
+	atLineStart bool
+	// Whether a space must be written before the next text on the current line.
+	pendingSpace bool
+	// Whether a comment broke the current statement over several lines, the
+	// continuation lines are indented one level deeper.
+	continuation bool
+	// The source line where the last printed token or comment ends, 0 if nothing was printed.
+	lastLine int
+	// The type of the last printed token or COMMENT.
+	lastType token.TokenType
+}
+
+// desyncError is raised when the AST does not match the source tokens, which is a bug in the printer.
+type desyncError struct {
+	message string
+}
+
+func (p *printer) print(statements []ast.Stmt) (formatted string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			desync, ok := r.(desyncError)
+			if !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("🤖 formatter error: %s", desync.message)
+		}
+	}()
+
+	p.atLineStart = true
+	for _, stmt := range statements {
+		p.statement(stmt)
+	}
+	// Comments at the end of the source code precede the EOF token.
+	p.leadingComments(p.tokens[p.next])
+	if p.tokens[p.next].TokenType != token.EOF {
+		panic(desyncError{message: fmt.Sprintf("token '%s' on line %d was not printed", p.tokens[p.next].Lexeme, p.tokens[p.next].Start.Line)})
+	}
+	return p.out.String(), nil
+}
+
+// statement prints a statement on its own line, followed by its trailing comment.
+func (p *printer) statement(stmt ast.Stmt) {
+	p.startLine(p.tokens[p.next])
+	stmt.Accept(p)
+	p.endLine()
+}
+
+// startLine prints the comments preceding the token and a blank line if the token was
+// separated from the previous statement by blank lines in the source code.
+func (p *printer) startLine(tok token.Token) {
+	p.leadingComments(tok)
+	p.blankLine(tok.Start.Line)
+}
+
+// endLine prints the comment trailing the last printed token on its line, if any, and ends the line.
+func (p *printer) endLine() {
+	if p.nextComment < len(p.comments) {
+		comment := p.comments[p.nextComment]
+		if comment.Start.Line == p.lastLine && comment.Start.Offset < p.tokens[p.next].Start.Offset {
+			p.out.WriteString(" ")
+			p.out.WriteString(comment.Lexeme)
+			p.nextComment++
+		}
+	}
+	p.continuation = false
+	p.newline()
+}
+
+// leadingComments prints the comments preceding the token, each on its own line.
+func (p *printer) leadingComments(tok token.Token) {
+	for p.nextComment < len(p.comments) && p.comments[p.nextComment].Start.Offset < tok.Start.Offset {
+		comment := p.comments[p.nextComment]
+		if !p.atLineStart {
+			// The comment is inside a statement, e.g between the operands of a binary expression.
+			p.continuation = true
+			if comment.Start.Line == p.lastLine {
+				p.space()
+				p.printComment(comment)
+				continue
+			}
+			p.newline()
+		}
+		p.blankLine(comment.Start.Line)
+		p.printComment(comment)
+	}
+}
+
+// printComment prints the comment and ends the line.
+func (p *printer) printComment(comment token.Token) {
+	p.write(comment.Lexeme)
+	p.nextComment++
+	p.lastLine = comment.Start.Line
+	p.lastType = token.COMMENT
+	p.newline()
+}
+
+// blankLine prints a blank line if the source code has blank lines between the line of the last
+// printed token and `line`. Blank lines are not preserved at the start of a block.
+func (p *printer) blankLine(line int) {
+	if p.lastLine > 0 && line-p.lastLine > 1 && p.lastType != token.LCUR {
+		p.out.WriteString("\n")
+	}
+}
+
+// token prints the next source token, which must have the expected type.
+func (p *printer) token(expected token.TokenType) {
+	tok := p.tokens[p.next]
+	if tok.TokenType != expected {
+		panic(desyncError{message: fmt.Sprintf("expected %s on line %d, found '%s'", expected, tok.Start.Line, tok.Lexeme)})
+	}
+	p.leadingComments(tok)
+	p.write(p.tokenText(tok))
+	p.next++
+	// NOTE: A string can span several lines, the next token is compared with the line it ends on.
+	p.lastLine = tok.End.Line
+	p.lastType = tok.TokenType
+}
+
+// tokenText returns the source code of a token.
+func (p *printer) tokenText(tok token.Token) string {
+	if tok.TokenType == token.STRING || tok.TokenType == token.INTERPOLATION {
+		// NOTE: The lexeme of a string does not include its delimiters, raw string prefix or
+		// the `}` and `${` around interpolated expressions.
+		return p.source[tok.Start.Offset:tok.End.Offset]
+	}
+	return tok.Lexeme
+}
+
+// write writes the text, indenting it if it is the first text on the line.
+func (p *printer) write(text string) {
+	if p.atLineStart {
+		indent := p.indent
+		if p.continuation {
+			indent++
+		}
+		p.out.WriteString(strings.Repeat(indentation, indent))
+		p.atLineStart = false
+	} else if p.pendingSpace {
+		p.out.WriteString(" ")
+	}
+	p.pendingSpace = false
+	p.out.WriteString(text)
+}
+
+func (p *printer) newline() {
+	p.out.WriteString("\n")
+	p.atLineStart = true
+	p.pendingSpace = false
+}
+
+// space separates the last printed text from the next one. The space is dropped
+// if a comment ends the line in between.
+func (p *printer) space() {
+	p.pendingSpace = true
+}
+
+func (p *printer) VisitExpressionStmt(stmt ast.ExpressionStmt) any {
+	stmt.Expression.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitPrintStmt(stmt ast.PrintStmt) any {
+	p.token(token.PRINT)
+	p.space()
+	stmt.Expression.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitVarStmt(stmt ast.VarStmt) any {
+	p.token(token.VAR)
+	p.space()
+	p.token(token.IDENTIFIER)
+	if stmt.Initializer != nil {
+		p.space()
+		p.token(token.ASSIGN)
+		p.space()
+		stmt.Initializer.Accept(p)
+	}
+	return nil
+}
+
+// VisitBlockStmt prints a block. The caller prints the text preceding the opening brace
+// and ends the line after the closing brace.
+func (p *printer) VisitBlockStmt(block ast.BlockStmt) any {
+	// A block moved to its own line by a comment is not a continuation line, it is aligned with its statement.
+	p.leadingComments(p.tokens[p.next])
+	p.continuation = false
+	p.token(token.LCUR)
+	if len(block.Statements) == 0 && !p.hasCommentBefore(p.tokens[p.next]) {
+		p.token(token.RCUR)
+		return nil
+	}
+	p.endLine()
+	p.indent++
+	for _, stmt := range block.Statements {
+		p.statement(stmt)
+	}
+	p.leadingComments(p.tokens[p.next])
+	p.indent--
+	p.token(token.RCUR)
+	return nil
+}
+
+// afterBlock separates a closing brace from the keyword continuing its statement, e.g `} else {`.
+// If comments are written between them, the comment trailing the brace ends its line and the
+// keyword starts a new line, aligned with its statement, e.g `} # note` followed by `else {`.
+func (p *printer) afterBlock() {
+	if !p.hasCommentBefore(p.tokens[p.next]) {
+		p.space()
+		return
+	}
+	p.endLine()
+	p.startLine(p.tokens[p.next])
+}
+
+func (p *printer) hasCommentBefore(tok token.Token) bool {
+	return p.nextComment < len(p.comments) && p.comments[p.nextComment].Start.Offset < tok.Start.Offset
+}
+
+func (p *printer) VisitIfStmt(stmt ast.IfStmt) any {
+	p.token(token.IF)
+	p.space()
+	stmt.Condition.Accept(p)
+	p.space()
+	stmt.Then.Accept(p)
+	if stmt.Else == nil {
+		return nil
+	}
+	if _, ok := stmt.Then.(ast.BlockStmt); ok {
+		p.afterBlock()
+	} else {
+		p.endLine()
+		p.startLine(p.tokens[p.next])
+	}
+	p.token(token.ELSE)
+	p.space()
+	stmt.Else.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitWhileStmt(stmt ast.WhileStmt) any {
+	p.token(token.WHILE)
+	p.space()
+	stmt.Condition.Accept(p)
+	p.space()
+	stmt.Body.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitTryStmt(stmt ast.TryStmt) any {
+	p.token(token.TRY)
+	p.space()
+	stmt.Body.Accept(p)
+	if stmt.Catch != nil {
+		p.afterBlock()
+		p.token(token.CATCH)
+		p.space()
+		p.token(token.LPA)
+		p.token(token.IDENTIFIER)
+		p.token(token.RPA)
+		p.space()
+		stmt.Catch.Accept(p)
+	}
+	if stmt.Finally != nil {
+		p.afterBlock()
+		p.token(token.FINALLY)
+		p.space()
+		stmt.Finally.Accept(p)
+	}
+	return nil
+}
+
+func (p *printer) VisitThrowStmt(stmt ast.ThrowStmt) any {
+	p.token(token.THROW)
+	p.space()
+	stmt.Value.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitImportStmt(stmt ast.ImportStmt) any {
+	p.token(token.IMPORT)
+	p.space()
+	p.token(token.STRING)
+	p.space()
+	p.token(token.AS)
+	p.space()
+	p.token(token.IDENTIFIER)
+	return nil
+}
+
+func (p *printer) VisitStructStmt(stmt ast.StructStmt) any {
+	p.token(token.STRUCT)
+	p.space()
+	p.token(token.IDENTIFIER)
+	p.space()
+	p.token(token.LCUR)
+	for i := range stmt.Fields {
+		if i > 0 {
+			p.token(token.COMMA)
+		}
+		p.space()
+		p.token(token.IDENTIFIER)
+	}
+	if len(stmt.Fields) > 0 {
+		p.space()
+	}
+	p.token(token.RCUR)
+	return nil
+}
+
+func (p *printer) VisitBinary(binary ast.Binary) any {
+	binary.Left.Accept(p)
+	p.space()
+	p.token(binary.Operator.TokenType)
+	p.space()
+	binary.Right.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitLogicalExpression(logical ast.Logical) any {
+	logical.Left.Accept(p)
+	p.space()
+	p.token(logical.Operator.TokenType)
+	p.space()
+	logical.Right.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitUnary(unary ast.Unary) any {
+	p.token(unary.Operator.TokenType)
+	unary.Right.Accept(p)
+	return nil
+}
+
+// VisitLiteral prints the literal as it is written in the source code, e.g `1.50` is not printed as `1.5`.
+func (p *printer) VisitLiteral(literal ast.Literal) any {
+	p.token(p.tokens[p.next].TokenType)
+	return nil
+}
+
+func (p *printer) VisitGrouping(grouping ast.Grouping) any {
+	p.token(token.LPA)
+	grouping.Expression.Accept(p)
+	p.token(token.RPA)
+	return nil
+}
+
+// VisitInterpolation prints the text of the string as it is written in the source code and
+// formats the interpolated expressions, e.g `"a ${ x+1 }"` is printed as `"a ${x + 1}"`.
+func (p *printer) VisitInterpolation(interpolation ast.Interpolation) any {
+	for i, part := range interpolation.Parts {
+		switch {
+		case i%2 == 1:
+			part.Accept(p)
+		case i == len(interpolation.Parts)-1:
+			p.token(token.STRING)
+		default:
+			p.token(token.INTERPOLATION)
+		}
+	}
+	return nil
+}
+
+func (p *printer) VisitVariableExpression(variable ast.Variable) any {
+	p.token(token.IDENTIFIER)
+	return nil
+}
+
+func (p *printer) VisitCall(call ast.Call) any {
+	call.Callee.Accept(p)
+	p.token(token.LPA)
+	for i, argument := range call.Arguments {
+		if i > 0 {
+			p.token(token.COMMA)
+			p.space()
+		}
+		argument.Accept(p)
+	}
+	p.token(token.RPA)
+	return nil
+}
+
+func (p *printer) VisitGet(get ast.Get) any {
+	get.Object.Accept(p)
+	p.token(token.DOT)
+	p.token(token.IDENTIFIER)
+	return nil
+}
+
+func (p *printer) VisitSet(set ast.Set) any {
+	set.Object.Accept(p)
+	p.token(token.DOT)
+	p.token(token.IDENTIFIER)
+	p.space()
+	p.token(set.Operator.TokenType)
+	p.space()
+	set.Value.Accept(p)
+	return nil
+}
+
+func (p *printer) VisitAssignExpression(assign ast.Assign) any {
+	p.token(token.IDENTIFIER)
+	p.space()
+	p.token(assign.Operator.TokenType)
+	p.space()
+	assign.Value.Accept(p)
+	return nil
+}
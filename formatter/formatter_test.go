@@ -0,0 +1,196 @@
+package formatter
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "empty",
+			source: "",
+			want:   "",
+		},
+		{
+			name:   "spacing",
+			source: "var   a=1\nprint(a+2)*-3\na=a  ==  1 and !true or  false",
+			want:   "var a = 1\nprint (a + 2) * -3\na = a == 1 and !true or false\n",
+		},
+		{
+			name:   "literals are printed as written",
+			source: `print 1.50 + "hello  world" + null`,
+			want:   "print 1.50 + \"hello  world\" + null\n",
+		},
+		{
+			name:   "strings keep their escapes and delimiters",
+			source: "print   \"a\\n\\\"b\\\"\" + r\"C:\\dir\"\nvar s =   \"\"\"line 1\n   line 2\"\"\"\nprint s",
+			want:   "print \"a\\n\\\"b\\\"\" + r\"C:\\dir\"\nvar s = \"\"\"line 1\n   line 2\"\"\"\nprint s\n",
+		},
+		{
+			name:   "interpolated expressions are formatted",
+			source: "print   \"a ${ x+1 } b${\"c${ y }\"}\"",
+			want:   "print \"a ${x + 1} b${\"c${y}\"}\"\n",
+		},
+		{
+			name:   "operators and compound assignment",
+			source: "a+=1\nprint 2**3//2%5&~1<<2|a^1",
+			want:   "a += 1\nprint 2 ** 3 // 2 % 5 & ~1 << 2 | a ^ 1\n",
+		},
+		{
+			name:   "calls and properties",
+			source: "print math . max( 1,abs(-2) ,math.pi)+floor( 2.5 )\nprint f()",
+			want:   "print math.max(1, abs(-2), math.pi) + floor(2.5)\nprint f()\n",
+		},
+		{
+			name:   "imports",
+			source: "import   \"lib/util.ni\"as util\nprint util . x",
+			want:   "import \"lib/util.ni\" as util\nprint util.x\n",
+		},
+		{
+			name:   "structs",
+			source: "struct   Point{x,y}\nstruct Empty{ }\nvar p=Point(1,2)\np . x+=1",
+			want:   "struct Point { x, y }\nstruct Empty {}\nvar p = Point(1, 2)\np.x += 1\n",
+		},
+		{
+			name:   "statements on one line",
+			source: "var a = 1 print a",
+			want:   "var a = 1\nprint a\n",
+		},
+		{
+			name:   "blank lines",
+			source: "\n\nvar a = 1\n\n\n\nprint a\nprint a\n\n\n",
+			want:   "var a = 1\n\nprint a\nprint a\n",
+		},
+		{
+			name:   "blocks",
+			source: "{var a = 1 {print a}}\n{ }",
+			want:   "{\n    var a = 1\n    {\n        print a\n    }\n}\n{}\n",
+		},
+		{
+			name:   "no blank lines at the start or end of a block",
+			source: "while true {\n\n\n  print 1\n\n  print 2\n\n}",
+			want:   "while true {\n    print 1\n\n    print 2\n}\n",
+		},
+		{
+			name:   "if else",
+			source: "if a>1{print a}\nelse   {print b}\nif a print a\nelse print b",
+			want:   "if a > 1 {\n    print a\n} else {\n    print b\n}\nif a print a\nelse print b\n",
+		},
+		{
+			name:   "else if",
+			source: "if a {print 1} else if b {print 2} else {print 3}",
+			want:   "if a {\n    print 1\n} else if b {\n    print 2\n} else {\n    print 3\n}\n",
+		},
+		{
+			name:   "comments before else",
+			source: "if a {\n    print 1\n} # note\nelse {\n    print 2\n}\nif b {print 3}   # one\n  # two\n  else {print 4}",
+			want:   "if a {\n    print 1\n} # note\nelse {\n    print 2\n}\nif b {\n    print 3\n} # one\n# two\nelse {\n    print 4\n}\n",
+		},
+		{
+			name:   "comments before catch and finally",
+			source: "try {print 1} # t\ncatch (e) {print e} # c\nfinally {print 2}",
+			want:   "try {\n    print 1\n} # t\ncatch (e) {\n    print e\n} # c\nfinally {\n    print 2\n}\n",
+		},
+		{
+			name:   "try catch finally",
+			source: "try{throw \"x\"}catch(e){print e}finally{print 1}",
+			want:   "try {\n    throw \"x\"\n} catch (e) {\n    print e\n} finally {\n    print 1\n}\n",
+		},
+		{
+			name:   "comments",
+			source: "# header\n\n\nvar a = 1   # trailing\n  # leading\nprint a\n# footer",
+			want:   "# header\n\nvar a = 1 # trailing\n# leading\nprint a\n# footer\n",
+		},
+		{
+			name:   "comments in blocks",
+			source: "{ # open\n# first\n\n  print 1\n\n  # last\n}\n{\n# only\n}",
+			want:   "{ # open\n    # first\n\n    print 1\n\n    # last\n}\n{\n    # only\n}\n",
+		},
+		{
+			name:   "comment inside an expression",
+			source: "print 1 + # one\n2\nprint 3",
+			want:   "print 1 + # one\n    2\nprint 3\n",
+		},
+		{
+			name:   "only comments",
+			source: "# a\n\n# b",
+			want:   "# a\n\n# b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+			// Formatting is idempotent.
+			again, err := Format(got)
+			if err != nil {
+				t.Fatalf("unexpected error formatting the output: %v", err)
+			}
+			if again != got {
+				t.Errorf("formatting is not idempotent, got:\n%q\nthen:\n%q", got, again)
+			}
+		})
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "lexer error",
+			source: `print "unclosed`,
+			want:   "unterminated string literal",
+		},
+		{
+			name:   "parser error",
+			source: "var = 1",
+			want:   "Syntax error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Format(tt.source)
+			if err == nil {
+				t.Fatalf("expected an error containing %q, got nil", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error: %v, want it to contain: %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// The sample programs are kept formatted.
+func TestFormatSamples(t *testing.T) {
+	for _, path := range []string{"../hellow_world.ni"} {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Format(string(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != string(data) {
+				t.Errorf("%s is not formatted, got:\n%s", path, got)
+			}
+		})
+	}
+}
@@ -3,7 +3,8 @@ module nilan
 go 1.22.3
 
 require (
-	github.com/chzyer/readline v1.5.1 // indirect
-	github.com/google/subcommands v1.2.0 // indirect
-	golang.org/x/sys v0.0.0-20220310020820-b874c991c1a5 // indirect
+	github.com/chzyer/readline v1.5.1
+	github.com/google/subcommands v1.2.0
 )
+
+require golang.org/x/sys v0.0.0-20220310020820-b874c991c1a5 // indirect
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"nilan/stdlib"
+)
+
+// capabilityFlags are the flags which grant programs the capabilities of the standard library,
+// which are denied by default.
+type capabilityFlags struct {
+	allowFS    bool
+	allowStdin bool
+	allowEnv   bool
+}
+
+func (c *capabilityFlags) setFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.allowFS, "allow-fs", false, "Allow the program to read and write files with read_file and write_file.")
+	f.BoolVar(&c.allowStdin, "allow-stdin", false, "Allow the program to read lines of the standard input with read_line.")
+	f.BoolVar(&c.allowEnv, "allow-env", false, "Allow the program to read environment variables with env.")
+}
+
+// host returns the host of a program run from the command line, which reads the standard input
+// and is given `args`, the command-line arguments after the file name.
+func (c *capabilityFlags) host(args []string) stdlib.Host {
+	return stdlib.Host{
+		Args:       args,
+		Stdin:      os.Stdin,
+		AllowStdin: c.allowStdin,
+		AllowFS:    c.allowFS,
+		AllowEnv:   c.allowEnv,
+	}
+}
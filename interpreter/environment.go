@@ -59,7 +59,7 @@ func (env *Environment) assign(name token.Token, value any) error {
 	}
 
 	msg := fmt.Sprintf("Undefined variable: %s", name.Lexeme)
-	return CreateRuntimeError(name.Line, name.Column, msg)
+	return CreateRuntimeError(name.Start, name.End, msg)
 }
 
 // Sets a variable in the environment
@@ -90,5 +90,5 @@ func (env *Environment) get(name token.Token) (any, error) {
 		return env.enclosing.get(name)
 	}
 	msg := fmt.Sprintf("Undefined variable: %s", name.Lexeme)
-	return nil, CreateRuntimeError(name.Line, name.Column, msg)
+	return nil, CreateRuntimeError(name.Start, name.End, msg)
 }
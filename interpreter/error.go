@@ -1,22 +1,78 @@
 package interpreter
 
-import "fmt"
+import (
+	"fmt"
+	"nilan/diagnostics"
+	"nilan/token"
+	"nilan/value"
+)
 
 // Defines the struct for all runtime errors in the Parser
 type RuntimeError struct {
-	Line    int32
-	Column  int
 	Message string
+	// The span of the code the error was raised at, e.g the operator of a binary expression.
+	Start token.Position
+	End   token.Position
 }
 
-func CreateRuntimeError(line int32, column int, message string) RuntimeError {
+func CreateRuntimeError(start token.Position, end token.Position, message string) RuntimeError {
 	return RuntimeError{
-		Line:    line,
-		Column:  column,
 		Message: message,
+		Start:   start,
+		End:     end,
 	}
 }
 
 func (e RuntimeError) Error() string {
-	return fmt.Sprintf("💥 Nilan Runtime error:\nline:%d, column:%d - %s", e.Line, e.Column, e.Message)
+	return fmt.Sprintf("💥 Nilan Runtime error:\nline:%d, column:%d - %s", e.Start.Line, e.Start.Column, e.Message)
+}
+
+// Diagnostic describes the error for rendering.
+func (e RuntimeError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeRuntime,
+		Message:  e.Message,
+		Start:    e.Start,
+		End:      e.End,
+	}
+}
+
+// ThrownError is raised by a `throw` statement and carries the thrown value.
+type ThrownError struct {
+	Value any
+	// The span of the `throw` keyword.
+	Start token.Position
+	End   token.Position
+}
+
+func (e ThrownError) Error() string {
+	return fmt.Sprintf("💥 Nilan Uncaught exception:\nline:%d - %v", e.Start.Line, e.Value)
+}
+
+// Diagnostic describes the error for rendering.
+func (e ThrownError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeUncaught,
+		Message:  fmt.Sprintf("uncaught exception: %s", stringify(e.Value)),
+		Help:     "wrap the code in `try { ... } catch (e) { ... }` to handle the exception",
+		Start:    e.Start,
+		End:      e.End,
+	}
+}
+
+// errorToValue converts an error recovered by a `try` statement to the value bound to the
+// catch variable. Thrown values are bound as they are, any other error is converted to a `value.Error`.
+func errorToValue(r any) any {
+	switch err := r.(type) {
+	case ThrownError:
+		return err.Value
+	case RuntimeError:
+		return value.Error{Message: err.Message, Line: err.Start.Line}
+	case error:
+		return value.Error{Message: err.Error()}
+	default:
+		return value.Error{Message: fmt.Sprint(err)}
+	}
 }
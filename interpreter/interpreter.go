@@ -2,29 +2,87 @@ package interpreter
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"nilan/ast"
+	"nilan/stdlib"
 	"nilan/token"
+	"nilan/value"
+	"os"
 	"strconv"
+	"strings"
 )
 
 // TreeWalkInterpreter executes parsed statements and evaluates expressions.
 type TreeWalkInterpreter struct {
 	environment *Environment
+	// out is where `print` statements and runtime errors are written to.
+	out io.Writer
+	// host provides the arguments, input and capabilities used by the system functions of the standard library.
+	host *stdlib.Host
+	// exit is the error raised by `exit` once the program called it, nil before.
+	exit *stdlib.ExitError
+	// reportError receives the runtime error which terminated the program, if it is set.
+	// Otherwise the error is written to `out`.
+	reportError func(err error)
 }
 
 // Creates an instance of a "Tree-Walk Interpreter"
 func Make() *TreeWalkInterpreter {
+	environment := MakeEnvironment()
+	host := &stdlib.Host{}
+	for name, builtin := range stdlib.Globals(host) {
+		environment.set(name, builtin)
+	}
 	return &TreeWalkInterpreter{
-		environment: MakeEnvironment(),
+		environment: environment,
+		out:         os.Stdout,
+		host:        host,
+	}
+}
+
+// SetHost sets the arguments, the standard input and the capabilities of the interpreted programs.
+// By default programs have no arguments and no input, and can not access the file system or
+// the environment variables.
+func (i *TreeWalkInterpreter) SetHost(host stdlib.Host) {
+	*i.host = host
+}
+
+// ExitCode returns the code the program exited with by calling `exit`, and false if it did not call it.
+func (i *TreeWalkInterpreter) ExitCode() (int, bool) {
+	if i.exit == nil {
+		return 0, false
 	}
+	return i.exit.Code, true
+}
+
+// SetOutput sets the writer where the interpreter writes the output of `print` statements
+// and runtime errors. Defaults to standard output.
+func (i *TreeWalkInterpreter) SetOutput(out io.Writer) {
+	i.out = out
+}
+
+// SetErrorReporter sets the function which receives the runtime error terminating the program,
+// e.g to render it as a diagnostic. By default the error is written to the output.
+func (i *TreeWalkInterpreter) SetErrorReporter(report func(err error)) {
+	i.reportError = report
 }
 
 // Interpret executes a list of statements.
-// It recovers from panics to print runtime errors without crashing.
+// It recovers from panics to report runtime errors without crashing, see SetErrorReporter.
+// Execution stops without an error if the program calls `exit`, whose code is returned by ExitCode.
 func (i *TreeWalkInterpreter) Interpret(statements []ast.Stmt) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println(r)
+			if exit, ok := r.(stdlib.ExitError); ok {
+				i.exit = &exit
+				return
+			}
+			if err, ok := r.(error); ok && i.reportError != nil {
+				i.reportError(err)
+				return
+			}
+			fmt.Fprintln(i.out, r)
 		}
 	}()
 	i.executeStatements(statements)
@@ -50,22 +108,94 @@ func (i *TreeWalkInterpreter) executeStmt(stmt ast.Stmt) {
 // within a new nested environment. It temporarily replaces the current
 // interpreter environment with a new one scoped as a child of the previous environment.
 // A deferred function ensures that if a panic occurs, the environment
-// is restored and the panic is printed. After executing the statements,
-// the previous environment is always restored,
+// is restored before the panic propagates to an enclosing `try` statement or to `Interpret`.
+// After executing the statements, the previous environment is always restored,
 // providing block-scoped execution and panic safety.
 func (i *TreeWalkInterpreter) VisitBlockStmt(blockStmt ast.BlockStmt) any {
 
 	previous := i.environment
 	i.environment = MakeNestedEnvironment(i.environment)
+	defer func() {
+		i.environment = previous
+	}()
+
+	i.executeStatements(blockStmt.Statements)
+	return nil
+}
+
+// VisitTryStmt executes the body of the try statement. If an error is raised while executing it,
+// the error is bound to the catch variable in a new environment and the catch block is executed.
+// The finally block is always executed last. If the error is not caught or the catch block raises
+// another error, it continues propagating after the finally block was executed.
+func (i *TreeWalkInterpreter) VisitTryStmt(stmt ast.TryStmt) any {
+	if stmt.Finally != nil {
+		defer func() {
+			r := recover()
+			// NOTE: The program stops immediately when it exits, like in the VM.
+			if _, exiting := r.(stdlib.ExitError); !exiting {
+				i.executeStmt(*stmt.Finally)
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
+	}
+	if stmt.Catch == nil {
+		i.executeStmt(stmt.Body)
+		return nil
+	}
+
+	thrown, ok := i.tryExecute(stmt.Body)
+	if ok {
+		return nil
+	}
+	previous := i.environment
+	i.environment = MakeNestedEnvironment(i.environment)
+	defer func() {
+		i.environment = previous
+	}()
+	i.environment.set(stmt.CatchName.Lexeme, thrown)
+	i.executeStmt(*stmt.Catch)
+	return nil
+}
+
+// tryExecute executes the statement and recovers from any error it raises.
+// It returns the error converted to a value and false if an error was raised.
+func (i *TreeWalkInterpreter) tryExecute(stmt ast.Stmt) (thrown any, ok bool) {
 	defer func() {
 		if r := recover(); r != nil {
-			i.environment = previous
-			fmt.Println(r)
+			if _, exiting := r.(stdlib.ExitError); exiting {
+				panic(r)
+			}
+			thrown = errorToValue(r)
+			ok = false
 		}
 	}()
+	i.executeStmt(stmt)
+	return nil, true
+}
 
-	i.executeStatements(blockStmt.Statements)
-	i.environment = previous
+// VisitThrowStmt evaluates the expression and raises its value as an error.
+func (i *TreeWalkInterpreter) VisitThrowStmt(stmt ast.ThrowStmt) any {
+	panic(ThrownError{
+		Value: i.evaluate(stmt.Value),
+		Start: stmt.Keyword.Start,
+		End:   stmt.Keyword.End,
+	})
+}
+
+// VisitImportStmt raises a runtime error, modules can only be imported by programs executed by the VM.
+func (i *TreeWalkInterpreter) VisitImportStmt(stmt ast.ImportStmt) any {
+	panic(CreateRuntimeError(stmt.Keyword.Start, stmt.Keyword.End, "import is not supported by the tree-walk interpreter, use the VM"))
+}
+
+// VisitStructStmt declares a variable whose value is the struct.
+func (i *TreeWalkInterpreter) VisitStructStmt(stmt ast.StructStmt) any {
+	fields := make([]string, len(stmt.Fields))
+	for index, field := range stmt.Fields {
+		fields[index] = field.Lexeme
+	}
+	i.environment.set(stmt.Name.Lexeme, &value.Struct{Name: stmt.Name.Lexeme, Fields: fields})
 	return nil
 }
 
@@ -104,12 +234,33 @@ func (i *TreeWalkInterpreter) VisitIfStmt(stmt ast.IfStmt) any {
 //   - any: always nil because print statements have no return value.
 func (i *TreeWalkInterpreter) VisitPrintStmt(printStmt ast.PrintStmt) any {
 	value := i.evaluate(printStmt.Expression)
+	fmt.Fprintln(i.out, stringify(value))
+	return nil
+}
+
+// stringify converts a value to the text printed for it, `null` for a nil value.
+func stringify(value any) string {
 	if value == nil {
-		fmt.Println("null")
-		return nil
+		return "null"
 	}
-	fmt.Println(value)
-	return nil
+	return fmt.Sprint(value)
+}
+
+// valuesEqual determines if two values are equal, comparing lists and maps by their elements
+// and struct instances by their fields.
+func valuesEqual(a any, b any) bool {
+	switch x := a.(type) {
+	case *value.List:
+		y, ok := b.(*value.List)
+		return ok && x.Equal(y, valuesEqual)
+	case *value.Map:
+		y, ok := b.(*value.Map)
+		return ok && x.Equal(y, valuesEqual)
+	case *value.Instance:
+		y, ok := b.(*value.Instance)
+		return ok && x.Equal(y, valuesEqual)
+	}
+	return a == b
 }
 
 // VisitVarStmt visits a VarStmt node.
@@ -148,7 +299,8 @@ func (i *TreeWalkInterpreter) VisitWhileStmt(stmt ast.WhileStmt) any {
 //
 // Steps:
 //  1. The right-hand side expression (`assign.Value`) is evaluated using
-//     the interpreter's `evaluate` method.
+//     the interpreter's `evaluate` method. For a compound assignment such as
+//     `a += 1`, the expression `a + 1` is evaluated.
 //  2. The resulting value is attempted to be assigned to the variable
 //     identified by `assign.Name` via the environment's `assign` method.
 //  3. If the variable is undefined in the current environment, a runtime
@@ -163,10 +315,10 @@ func (i *TreeWalkInterpreter) VisitWhileStmt(stmt ast.WhileStmt) any {
 //   - any: The value resulting from evaluating `assign.Value`, which is
 //     also the value bound to the variable after the assignment.
 func (i *TreeWalkInterpreter) VisitAssignExpression(assign ast.Assign) any {
-	value := i.evaluate(assign.Value)
+	value := i.evaluate(assign.AssignedValue())
 	err := i.environment.assign(assign.Name, value)
 	if err != nil {
-		panic(err.Error())
+		panic(err)
 	}
 	return value
 }
@@ -187,32 +339,38 @@ func (i *TreeWalkInterpreter) VisitBinary(binary ast.Binary) any {
 
 	switch operator {
 	case token.MULT:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
-			panic(err.Error())
+			panic(err)
 		}
 		// TODO: support string multiplication by integer count
+		if leftInt, rightInt, ok := integerOperands(leftResult, rightResult); ok {
+			return leftInt * rightInt
+		}
 		return leftValue * rightValue
 
 	case token.DIV:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
-			panic(err.Error())
+			panic(err)
 		}
 		if rightValue == 0 {
-			return CreateRuntimeError(binary.Operator.Line, binary.Operator.Column+1, "Division by zero")
+			panic(CreateRuntimeError(binary.Operator.Start, binary.Operator.End, "division by zero"))
 		}
 		return leftValue / rightValue
 
 	case token.SUB:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
-			panic(err.Error())
+			panic(err)
+		}
+		if leftInt, rightInt, ok := integerOperands(leftResult, rightResult); ok {
+			return leftInt - rightInt
 		}
 		return leftValue - rightValue
 
 	case token.ADD:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
 			// If not numeric, check if both are strings for concatenation
 			leftValString, ok := leftResult.(string)
@@ -222,44 +380,110 @@ func (i *TreeWalkInterpreter) VisitBinary(binary ast.Binary) any {
 				_, errA := strconv.ParseFloat(leftValString, 64)
 				_, errB := strconv.ParseFloat(rightValString, 64)
 				if errA == nil || errB == nil {
-					panic(err.Error())
+					panic(err)
+				}
+				if len(leftValString)+len(rightValString) > stdlib.MaxStringLength {
+					msg := fmt.Sprintf("concatenated string is longer than %d bytes", stdlib.MaxStringLength)
+					panic(CreateRuntimeError(binary.Operator.Start, binary.Operator.End, msg))
 				}
 				return leftValString + rightValString
 			}
 			// Otherwise propagate the error
-			panic(err.Error())
+			panic(err)
+		}
+		if leftInt, rightInt, ok := integerOperands(leftResult, rightResult); ok {
+			return leftInt + rightInt
 		}
 		return leftValue + rightValue
 
+	case token.FLOOR_DIV, token.MOD:
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
+		if err != nil {
+			panic(err)
+		}
+		if rightValue == 0 {
+			message := "division by zero"
+			if operator == token.MOD {
+				message = "modulo by zero"
+			}
+			panic(CreateRuntimeError(binary.Operator.Start, binary.Operator.End, message))
+		}
+		leftInt, isLeftInt := leftResult.(int64)
+		rightInt, isRightInt := rightResult.(int64)
+		switch {
+		case operator == token.FLOOR_DIV && isLeftInt && isRightInt:
+			return value.FloorDivInt(leftInt, rightInt)
+		case operator == token.FLOOR_DIV:
+			return value.FloorDivFloat(leftValue, rightValue)
+		case isLeftInt && isRightInt:
+			return value.ModInt(leftInt, rightInt)
+		default:
+			return value.ModFloat(leftValue, rightValue)
+		}
+
+	case token.POW:
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
+		if err != nil {
+			panic(err)
+		}
+		leftInt, isLeftInt := leftResult.(int64)
+		rightInt, isRightInt := rightResult.(int64)
+		if isLeftInt && isRightInt && rightInt >= 0 {
+			return value.PowInt(leftInt, rightInt)
+		}
+		return math.Pow(leftValue, rightValue)
+
+	case token.BIT_AND, token.BIT_OR, token.BIT_XOR, token.SHIFT_LEFT, token.SHIFT_RIGHT:
+		leftValue, rightValue, err := isOperandsInteger(leftResult, rightResult, binary.Operator)
+		if err != nil {
+			panic(err)
+		}
+		switch operator {
+		case token.BIT_AND:
+			return leftValue & rightValue
+		case token.BIT_OR:
+			return leftValue | rightValue
+		case token.BIT_XOR:
+			return leftValue ^ rightValue
+		}
+		if rightValue < 0 {
+			message := fmt.Sprintf("negative shift count: %d", rightValue)
+			panic(CreateRuntimeError(binary.Operator.Start, binary.Operator.End, message))
+		}
+		if operator == token.SHIFT_LEFT {
+			return leftValue << rightValue
+		}
+		return leftValue >> rightValue
+
 	case token.EQUAL_EQUAL:
-		return leftResult == rightResult
+		return valuesEqual(leftResult, rightResult)
 
 	case token.NOT_EQUAL:
-		return leftResult != rightResult
+		return !valuesEqual(leftResult, rightResult)
 
 	case token.LARGER:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
 			panic(err)
 		}
 		return leftValue > rightValue
 
 	case token.LARGER_EQUAL:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
 			panic(err)
 		}
 		return leftValue >= rightValue
 
 	case token.LESS:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
 			panic(err)
 		}
 		return leftValue < rightValue
 
 	case token.LESS_EQUAL:
-		leftValue, rightValue, err := isOperandsNumeric(operator, leftResult, rightResult, binary.Operator)
+		leftValue, rightValue, err := isOperandsNumeric(leftResult, rightResult, binary.Operator)
 		if err != nil {
 			panic(err)
 		}
@@ -267,7 +491,7 @@ func (i *TreeWalkInterpreter) VisitBinary(binary ast.Binary) any {
 
 	default:
 		message := fmt.Sprintf("operator '%s' not supported", operator)
-		error := CreateRuntimeError(binary.Operator.Line, binary.Operator.Column, message)
+		error := CreateRuntimeError(binary.Operator.Start, binary.Operator.End, message)
 		panic(error)
 	}
 }
@@ -286,13 +510,23 @@ func (i *TreeWalkInterpreter) VisitUnary(unary ast.Unary) any {
 	operator := unary.Operator.TokenType
 	switch operator {
 	case token.SUB:
+		if integer, ok := rightResult.(int64); ok {
+			return -integer
+		}
 		r, err := literalToFloat64(rightResult)
 		if err != nil {
-			message := fmt.Sprintf("operand must be a numeric value. '%s %s' is not allowed", operator, rightResult)
-			error := CreateRuntimeError(unary.Operator.Line, unary.Operator.Column, message)
+			message := fmt.Sprintf("operand must be a numeric value: -%s", stringify(rightResult))
+			error := CreateRuntimeError(unary.Operator.Start, unary.Operator.End, message)
 			panic(error)
 		}
 		return -r
+	case token.BIT_NOT:
+		r, ok := rightResult.(int64)
+		if !ok {
+			message := fmt.Sprintf("operand must be an integer value: ~%s", stringify(rightResult))
+			panic(CreateRuntimeError(unary.Operator.Start, unary.Operator.End, message))
+		}
+		return ^r
 	case token.BANG:
 		if rightResult == nil {
 			return true
@@ -304,7 +538,7 @@ func (i *TreeWalkInterpreter) VisitUnary(unary ast.Unary) any {
 		return false
 	default:
 		message := fmt.Sprintf("operator '%s' not supported for unary operations", operator)
-		error := CreateRuntimeError(unary.Operator.Line, unary.Operator.Column, message)
+		error := CreateRuntimeError(unary.Operator.Start, unary.Operator.End, message)
 		panic(error)
 	}
 }
@@ -337,7 +571,7 @@ func (i *TreeWalkInterpreter) VisitVariableExpression(expression ast.Variable) a
 	}
 	if value == nil {
 		msg := fmt.Sprintf("Cant access uninitialised variable: %s", expression.Name.Lexeme)
-		err := CreateRuntimeError(expression.Name.Line, expression.Name.Column, msg)
+		err := CreateRuntimeError(expression.Name.Start, expression.Name.End, msg)
 		panic(err)
 	}
 	return value
@@ -375,6 +609,64 @@ func (i *TreeWalkInterpreter) VisitLogicalExpression(logical ast.Logical) any {
 
 }
 
+// VisitInterpolation evaluates a string containing interpolated expressions by evaluating its
+// parts in order and concatenating them, each converted to a string the same way `print` does.
+// A runtime error is raised if the string is longer than `stdlib.MaxStringLength`.
+//
+// Returns:
+//   - any: the resulting string.
+func (i *TreeWalkInterpreter) VisitInterpolation(interpolation ast.Interpolation) any {
+	var result strings.Builder
+	for _, part := range interpolation.Parts {
+		result.WriteString(stringify(i.evaluate(part)))
+		if result.Len() > stdlib.MaxStringLength {
+			msg := fmt.Sprintf("interpolated string is longer than %d bytes", stdlib.MaxStringLength)
+			panic(CreateRuntimeError(interpolation.Start, interpolation.End, msg))
+		}
+	}
+	return result.String()
+}
+
+// VisitCall evaluates the callee and the arguments of a call in order, and calls the function.
+// A runtime error is raised if the callee is not a function, or the function fails.
+func (i *TreeWalkInterpreter) VisitCall(call ast.Call) any {
+	callee := i.evaluate(call.Callee)
+	arguments := make([]any, 0, len(call.Arguments))
+	for _, argument := range call.Arguments {
+		arguments = append(arguments, i.evaluate(argument))
+	}
+
+	function, ok := callee.(value.Callable)
+	if !ok {
+		msg := fmt.Sprintf("can only call functions and structs, got %s", stringify(callee))
+		panic(CreateRuntimeError(call.Paren.Start, call.Paren.End, msg))
+	}
+	result, err := function.Call(arguments)
+	if exit, ok := err.(stdlib.ExitError); ok {
+		panic(exit)
+	}
+	if err != nil {
+		panic(CreateRuntimeError(call.Paren.Start, call.Paren.End, err.Error()))
+	}
+	return result
+}
+
+// VisitGet evaluates the access of a property, such as `math.pi`.
+// A runtime error is raised if the object has no properties or no such member.
+func (i *TreeWalkInterpreter) VisitGet(get ast.Get) any {
+	object := i.evaluate(get.Object)
+	holder, ok := object.(value.Object)
+	if !ok {
+		msg := fmt.Sprintf("only modules, maps, struct instances and errors have properties, got %s", stringify(object))
+		panic(CreateRuntimeError(get.Name.Start, get.Name.End, msg))
+	}
+	member, err := holder.Member(get.Name.Lexeme)
+	if err != nil {
+		panic(CreateRuntimeError(get.Name.Start, get.Name.End, err.Error()))
+	}
+	return member
+}
+
 // VisitGrouping evaluates a Grouping expression by evaluating its inner expression.
 //
 // Parameters:
@@ -386,6 +678,31 @@ func (i *TreeWalkInterpreter) VisitGrouping(grouping ast.Grouping) any {
 	return i.evaluate(grouping.Expression)
 }
 
+// VisitSet evaluates the assignment of a field, such as `p.x = 3`, and returns the assigned value.
+// A runtime error is raised if the object is not a struct instance or has no such field.
+func (i *TreeWalkInterpreter) VisitSet(set ast.Set) any {
+	object := i.evaluate(set.Object)
+	holder, ok := object.(value.MutableObject)
+	if !ok {
+		msg := fmt.Sprintf("only struct instances have assignable fields, got %s", stringify(object))
+		panic(CreateRuntimeError(set.Name.Start, set.Name.End, msg))
+	}
+	assigned := set.Value
+	if operator, ok := set.BinaryOperator(); ok {
+		current, err := holder.Member(set.Name.Lexeme)
+		if err != nil {
+			panic(CreateRuntimeError(set.Name.Start, set.Name.End, err.Error()))
+		}
+		// NOTE: The object is only evaluated once, the current value of the field is used as a literal.
+		assigned = ast.Binary{Left: ast.Literal{Value: current}, Operator: operator, Right: set.Value}
+	}
+	val := i.evaluate(assigned)
+	if err := holder.SetMember(set.Name.Lexeme, val); err != nil {
+		panic(CreateRuntimeError(set.Name.Start, set.Name.End, err.Error()))
+	}
+	return val
+}
+
 // evaluate evaluates any expression node by invoking its Accept method
 // with the Interpreter visitor.
 //
@@ -431,10 +748,33 @@ func literalToFloat64(value any) (float64, error) {
 	}
 }
 
+// isOperandsInteger validates that both operands are integers and converts them to int64.
+//
+// Returns:
+//   - int64: integer value of left operand.
+//   - int64: integer value of right operand.
+//   - error: if either operand is not an integer.
+func isOperandsInteger(left any, right any, token token.Token) (int64, int64, error) {
+	if l, r, ok := integerOperands(left, right); ok {
+		return l, r, nil
+	}
+
+	message := fmt.Sprintf("operands must be integer values: %s,%s", stringify(left), stringify(right))
+	return 0, 0, CreateRuntimeError(token.Start, token.End, message)
+}
+
+// integerOperands returns both operands if they are integers, so arithmetic on integers
+// produces an integer, e.g `0 + 1 == 1` is true. The interpreter always wraps around on
+// overflow, it does not support the VM's `check` and `promote` overflow modes.
+func integerOperands(left any, right any) (int64, int64, bool) {
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+	return l, r, lok && rok
+}
+
 // isOperandsNumeric validates that both operands are numeric and converts them to float64.
 //
 // Parameters:
-//   - operator: the token type of the operator.
 //   - left, right: values of the operands.
 //   - token: token for error positioning.
 //
@@ -442,7 +782,7 @@ func literalToFloat64(value any) (float64, error) {
 //   - float64: numeric value of left operand.
 //   - float64: numeric value of right operand.
 //   - error: if either operand cannot be converted to float64.
-func isOperandsNumeric(operator token.TokenType, left any, right any, token token.Token) (float64, float64, error) {
+func isOperandsNumeric(left any, right any, token token.Token) (float64, float64, error) {
 	l, lerr := literalToFloat64(left)
 	r, rerr := literalToFloat64(right)
 
@@ -450,7 +790,7 @@ func isOperandsNumeric(operator token.TokenType, left any, right any, token toke
 		return l, r, nil
 	}
 
-	message := fmt.Sprintf("operands must be numeric values. '%v %s %v' is not allowed", left, operator, right)
-	error := CreateRuntimeError(token.Line, token.Column, message)
+	message := fmt.Sprintf("operands must be numeric values: %s,%s", stringify(left), stringify(right))
+	error := CreateRuntimeError(token.Start, token.End, message)
 	return 0, 0, error
 }
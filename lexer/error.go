@@ -0,0 +1,115 @@
+package lexer
+
+import (
+	"fmt"
+	"nilan/diagnostics"
+	"nilan/token"
+)
+
+// ErrorKind identifies the kind of a LexError.
+type ErrorKind int
+
+const (
+	// A character which does not start any token, e.g `$`.
+	UnexpectedCharacter ErrorKind = iota
+	// A malformed number, e.g `1.`, `1.1.1`, `0xg`, `1__0` or `1.d`.
+	InvalidNumber
+	// A string literal without its closing `"`.
+	UnterminatedString
+	// An unknown or malformed escape sequence in a string literal, e.g `\q` or `\u{110000}`.
+	InvalidEscape
+	// An interpolation without an expression, e.g `"${}"`.
+	EmptyInterpolation
+	// A number which does not fit in an int64, overflows a float64 or has a too large decimal exponent,
+	// e.g `9223372036854775808`, `1e400` or `1e1001d`.
+	NumberOutOfRange
+)
+
+func (kind ErrorKind) String() string {
+	switch kind {
+	case UnexpectedCharacter:
+		return "unexpected character"
+	case InvalidNumber:
+		return "invalid number"
+	case UnterminatedString:
+		return "unterminated string"
+	case InvalidEscape:
+		return "invalid escape sequence"
+	case EmptyInterpolation:
+		return "empty interpolation"
+	case NumberOutOfRange:
+		return "number out of range"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(kind))
+}
+
+// Defines the struct for all errors found while scanning the source code.
+type LexError struct {
+	Kind ErrorKind
+	// The offending source code: the text starting with the unexpected character up to the
+	// next whitespace, the invalid number, the contents of the unterminated string, the
+	// invalid escape sequence or the empty interpolation.
+	Lexeme string
+	// The span of the offending source code. Only the unexpected character is spanned for
+	// an UnexpectedCharacter error.
+	Start token.Position
+	End   token.Position
+}
+
+// Error returns the message describing the error, its position is described by its span.
+func (e LexError) Error() string {
+	switch e.Kind {
+	case UnexpectedCharacter:
+		return fmt.Sprintf("unexpected character '%c'", e.character())
+	case InvalidNumber:
+		return fmt.Sprintf("invalid number '%s'", e.Lexeme)
+	case UnterminatedString:
+		return "unterminated string literal"
+	case InvalidEscape:
+		return fmt.Sprintf("invalid escape sequence '%s'", e.Lexeme)
+	case EmptyInterpolation:
+		return "empty interpolation"
+	case NumberOutOfRange:
+		return fmt.Sprintf("number '%s' is out of range", e.Lexeme)
+	}
+	return fmt.Sprintf("%s '%s'", e.Kind, e.Lexeme)
+}
+
+// character returns the first character of the offending source code.
+func (e LexError) character() rune {
+	for _, char := range e.Lexeme {
+		return char
+	}
+	return 0
+}
+
+// Diagnostic describes the error for rendering.
+func (e LexError) Diagnostic() diagnostics.Diagnostic {
+	diagnostic := diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeLexical,
+		Message:  e.Error(),
+		Start:    e.Start,
+		End:      e.End,
+	}
+	switch e.Kind {
+	case UnexpectedCharacter:
+		diagnostic.Code = diagnostics.CodeUnexpectedCharacter
+	case InvalidNumber:
+		diagnostic.Code = diagnostics.CodeInvalidNumber
+		diagnostic.Help = "a number has at most one decimal point followed by digits, an optional exponent and `_` only between digits, e.g `1.5`, `.5`, `1_000`, `1.5e-3`, `0xff`, `0b1010`, `0o17` or the decimal `12.50d`"
+	case UnterminatedString:
+		diagnostic.Code = diagnostics.CodeUnterminatedString
+		diagnostic.Help = "add a closing `\"` at the end of the string"
+	case InvalidEscape:
+		diagnostic.Code = diagnostics.CodeInvalidEscape
+		diagnostic.Help = `the valid escape sequences are \n, \t, \r, \0, \\, \", \$, \uXXXX and \u{X...}, use a raw string such as r"C:\dir" to keep backslashes`
+	case EmptyInterpolation:
+		diagnostic.Code = diagnostics.CodeEmptyInterpolation
+		diagnostic.Help = `add an expression between the braces, e.g "${name}", or write \${ to keep the text as it is`
+	case NumberOutOfRange:
+		diagnostic.Code = diagnostics.CodeNumberOutOfRange
+		diagnostic.Help = "integers must be at most 9223372036854775807, floats at most 1.8e308 and the exponent of a decimal at most 1000, use a float such as `1e20` or a decimal such as `1e20d` for a larger integer"
+	}
+	return diagnostic
+}
@@ -0,0 +1,35 @@
+package lexer
+
+import (
+	"nilan/token"
+	"testing"
+)
+
+// FuzzScan checks that the lexer never panics and that a successful scan
+// always ends with an EOF token.
+func FuzzScan(f *testing.F) {
+	seeds := []string{
+		"",
+		"1+2+3+4",
+		"var a = 1.5\nprint a",
+		`"unclosed`,
+		"1.11.",
+		".2 0.0001 1000",
+		"# comment\nwhile a < b { a = a + 1 }",
+		"!= == <= >= < > ! = ( ) { } , ;",
+		"@",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, source string) {
+		tokens, lexErrs := New(source).Scan()
+		if len(lexErrs) > 0 {
+			return
+		}
+		if len(tokens) == 0 || tokens[len(tokens)-1].TokenType != token.EOF {
+			t.Fatalf("expected the last token to be EOF, got: %v", tokens)
+		}
+	})
+}
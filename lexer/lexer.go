@@ -1,15 +1,22 @@
 package lexer
 
 import (
-	"fmt"
+	"errors"
 	"nilan/token"
+	"nilan/value"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 const (
 	COMMENT_CHAR = '#'
 )
 
+// whitespace are the characters skipped between tokens.
+const whitespace = " \r\t\n"
+
 func isLetter(char rune) bool {
 	return rune('a') <= char && char <= rune('z') || rune('A') <= char && char <= rune('Z') || char == rune('_')
 }
@@ -29,6 +36,12 @@ type Lexer struct {
 	// Total number of runes in the input.
 	totalChars int
 
+	// The byte offset of every rune in the input, followed by the length of the input in bytes.
+	byteOffsets []int
+
+	// The index of the first rune of every line in the input.
+	lineStarts []int
+
 	// Stores the sequence of tokens produced during lexing.
 	tokens []token.Token
 
@@ -42,15 +55,16 @@ type Lexer struct {
 	// will be read
 	readPosition int
 
-	// Tracks the number of lines processed (incremented on newline).
-	lineCount int32
+	// Stores the LexErrors found during lexing.
+	errors []error
 
-	// Tracks the character's position within the current line.
-	// Gets reset on every new line back to 0
-	column int
+	// Stores the strings whose interpolated expression is being scanned, the innermost string is the last one.
+	interpolations []stringLiteral
 
-	// Stores any scanning errors that occur during lexing.
-	errors []error
+	// Stores the comments found during lexing as COMMENT tokens. Comments are trivia,
+	// they are not part of the token sequence returned by `Scan` and are ignored by the parser,
+	// but tools such as the formatter need them to reproduce the source code.
+	comments []token.Token
 }
 
 // Initializes and returns a new Lexer instance.
@@ -64,24 +78,44 @@ type Lexer struct {
 func New(input string) *Lexer {
 	lexer := &Lexer{
 		characters: []rune(input),
+		lineStarts: []int{0},
 	}
 	lexer.totalChars = len(lexer.characters)
+	lexer.byteOffsets = make([]int, 0, lexer.totalChars+1)
+	for offset, char := range input {
+		lexer.byteOffsets = append(lexer.byteOffsets, offset)
+		if char == '\n' {
+			lexer.lineStarts = append(lexer.lineStarts, len(lexer.byteOffsets))
+		}
+	}
+	lexer.byteOffsets = append(lexer.byteOffsets, len(input))
 	lexer.readChar()
 	return lexer
 }
 
+// positionAt returns the position in the source code of the rune at `index`.
+// The index can be the total number of runes, which is the position after the end of the input.
+func (lexer *Lexer) positionAt(index int) token.Position {
+	if index > lexer.totalChars {
+		index = lexer.totalChars
+	}
+	line := sort.Search(len(lexer.lineStarts), func(i int) bool { return lexer.lineStarts[i] > index }) - 1
+	return token.Position{
+		Line:   line + 1,
+		Column: index - lexer.lineStarts[line] + 1,
+		Offset: lexer.byteOffsets[index],
+	}
+}
+
 // Updates the `Lexer`'s reading position forward by one character.
 //
 // Behavior:
 //   - Sets `position` to the current `readPosition“
 //   - Increments `readPosition` by 1, so the lexer is ready to read the next
 //     character on the following call.
-//   - Updates the `column` to match `readPosition`, keeping track of the
-//     character's position within the line.
 func (lexer *Lexer) advance() {
 	lexer.position = lexer.readPosition
 	lexer.readPosition++
-	lexer.column = lexer.readPosition
 }
 
 // Determines of the lexer has finished scanning all the source code.
@@ -155,72 +189,186 @@ func (lexer *Lexer) peekNext() rune {
 // This method is responsible for handling comments in the lexical analysis.
 // It checks if the current character is a comment character and, if so,
 // consumes all characters until the end of the line or end of input,
-// while advancing the `Lexer`'s position
+// while advancing the `Lexer`'s position. The comment is recorded as trivia.
 func (lexer *Lexer) handleComment() {
+	initPos := lexer.position
+	end := initPos
+	for end < lexer.totalChars && lexer.characters[end] != rune('\n') {
+		end++
+	}
+	comment := strings.TrimRight(string(lexer.characters[initPos:end]), "\r")
+	tok := token.CreateLiteralToken(token.COMMENT, comment, comment)
+	tok.Start = lexer.positionAt(initPos)
+	tok.End = lexer.positionAt(initPos + len([]rune(comment)))
+	lexer.comments = append(lexer.comments, tok)
+
 	for lexer.currentChar != rune('\n') && !lexer.isFinished() {
 		lexer.readChar()
 	}
 }
 
-// handleNumber scans a sequence of digits (and at most one decimal point) from
-// the input and creates an integer or floating-point literal token accordingly.
+// Comments returns the comments found by `Scan`, in the order they appear in the input.
+func (lexer *Lexer) Comments() []token.Token {
+	return lexer.comments
+}
+
+// handleNumber scans a numeric literal from the input and creates an integer or
+// floating-point literal token accordingly. The following literals are supported:
+//   - decimal integers, e.g `42`. A leading zero does not make a number octal, `010` is 10.
+//   - hexadecimal, binary and octal integers, prefixed by `0x`, `0b` and `0o` (or
+//     `0X`, `0B`, `0O`), e.g `0xff`, `0b1010` and `0o17`.
+//   - decimal floats with a fraction and/or an exponent, e.g `1.5`, `.5`, `1e3` and `1.5e-3`.
+//
+// Digits can be separated by a single `_` for readability, e.g `1_000_000` or `0xff_ff`.
+// The lexeme of the token keeps the number as it is written.
 //
-// The method starts scanning from the current lexer position and continues
-// advancing until it encounters a character that is not a digit or a decimal
-// point (`.`). A decimal point is allowed only once within the number.
+// The method starts scanning from the current lexer position and reads every following
+// letter, digit, `_` and `.`, and the sign of an exponent, so a malformed number is
+// reported as a whole, e.g `1.1.1` or `12abc`.
 //
 // Validation rules:
-//   - A number ending with a decimal point (e.g., "1.") without further digits
-//     results in an error.
-//   - Multiple decimal points (e.g., "1.1.") are considered invalid and cause
-//     an error.
+//   - A decimal point must be followed by digits, `1.` is invalid while `.5` is 0.5.
+//   - A number has at most one decimal point and one exponent, which must contain digits.
+//   - Hexadecimal, binary and octal numbers are integers which only contain digits of their base.
+//   - A decimal number is written with a `d` suffix, e.g `12.50d`, its exponent is at most 1000.
+//   - An `_` must be between two digits.
+//   - An integer must fit in 64 bits and a float must not overflow to infinity.
 //
 // Returns:
 //   - nil if the token was successfully created and added
-//   - an error if the number format is invalid
+//   - a LexError if the number format is invalid or the number is out of range
 func (lexer *Lexer) handleNumber() error {
 	initPos := lexer.position
-	decimalCount := 0
+	decimal := !(lexer.currentChar == '0' && strings.ContainsRune("xXbBoO", lexer.peek()))
 
 	for {
 		nextChar := lexer.peek()
-		if nextChar == rune(0) || nextChar == rune('\n') || !isNumber(nextChar) && nextChar != rune('.') {
+		// NOTE: `advance` does not update `currentChar`, the last character read is before `readPosition`.
+		lastChar := lexer.characters[lexer.readPosition-1]
+		sign := decimal && (nextChar == '+' || nextChar == '-') && (lastChar == 'e' || lastChar == 'E')
+		if !isLetter(nextChar) && !isNumber(nextChar) && nextChar != '.' && !sign {
 			break
 		}
-		if nextChar == '.' {
-			// handles numbers such as 1.
-			if lexer.peekNext() == rune(0) {
-				illegalNumber := string(lexer.characters[initPos : lexer.readPosition+1])
-				return fmt.Errorf("invalid number: '%s', line: %v", string(illegalNumber), lexer.lineCount)
-			}
-			// handles numbers such as 1.1.
-			if decimalCount == 1 {
-				illegalNumber := lexer.readIllegal(initPos)
-				return fmt.Errorf("invalid number: '%s', line: %v", string(illegalNumber), lexer.lineCount)
+		lexer.advance()
+	}
 
-			}
-			decimalCount++
+	number := string(lexer.characters[initPos:lexer.readPosition])
+	literal, kind, ok := parseNumber(number)
+	if !ok {
+		return lexer.lexError(kind, initPos, lexer.readPosition)
+	}
+
+	var tokenType token.TokenType = token.INT
+	switch literal.(type) {
+	case float64:
+		tokenType = token.FLOAT
+	case value.Decimal:
+		tokenType = token.DECIMAL
+	}
+	lexer.tokens = append(lexer.tokens, token.CreateLiteralToken(tokenType, literal, number))
+	return nil
+}
+
+// parseNumber validates a numeric literal, see `handleNumber`, and converts it to its value.
+//
+// Returns:
+//   - any: the value of the number, an int64 for integers, a float64 for floats and a value.Decimal
+//     for decimals.
+//   - ErrorKind: InvalidNumber or NumberOutOfRange if the number is not valid.
+//   - bool: whether the number is valid.
+func parseNumber(number string) (any, ErrorKind, bool) {
+	base := 10
+	digits := number
+	if len(number) > 1 && number[0] == '0' {
+		switch number[1] {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		case 'o', 'O':
+			base = 8
 		}
-		// handles numbers such as .2
-		if lexer.currentChar == rune('.') && isNumber(nextChar) {
-			decimalCount++
+	}
+	if base != 10 {
+		digits = number[2:]
+		if !validDigits(digits, base) {
+			return nil, InvalidNumber, false
 		}
+		return parseInt(digits, base)
+	}
 
-		lexer.advance()
+	isDecimal := strings.HasSuffix(number, "d") || strings.HasSuffix(number, "D")
+	if isDecimal {
+		number = number[:len(number)-1]
 	}
-	number := string(lexer.characters[initPos:lexer.readPosition])
-	var tok token.Token
 
-	if decimalCount == 0 {
-		result, _ := strconv.ParseInt(number, 0, 64)
-		tok = token.CreateLiteralToken(token.INT, result, number, lexer.lineCount, lexer.column)
-	} else {
-		result, _ := strconv.ParseFloat(number, 64)
-		tok = token.CreateLiteralToken(token.FLOAT, result, number, lexer.lineCount, lexer.column)
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(number), "e")
+	whole, fraction, hasFraction := strings.Cut(mantissa, ".")
+	if hasExponent {
+		exponent = strings.TrimPrefix(strings.TrimPrefix(exponent, "+"), "-")
+		if !validDigits(exponent, 10) {
+			return nil, InvalidNumber, false
+		}
+	}
+	if hasFraction && !validDigits(fraction, 10) {
+		return nil, InvalidNumber, false
+	}
+	// NOTE: The whole part can only be omitted before a fraction, e.g `.5`.
+	if !validDigits(whole, 10) && !(whole == "" && hasFraction) {
+		return nil, InvalidNumber, false
+	}
+	if isDecimal {
+		decimal, err := value.ParseDecimal(strings.ReplaceAll(number, "_", ""))
+		if errors.Is(err, value.ErrDecimalRange) {
+			return nil, NumberOutOfRange, false
+		}
+		if err != nil {
+			return nil, InvalidNumber, false
+		}
+		return decimal, 0, true
+	}
+	if !hasFraction && !hasExponent {
+		return parseInt(number, 10)
 	}
-	lexer.tokens = append(lexer.tokens, tok)
 
-	return nil
+	float, err := strconv.ParseFloat(strings.ReplaceAll(number, "_", ""), 64)
+	return parseResult(float, err)
+}
+
+// parseInt converts the digits of an integer in the given base, without its prefix, to an int64.
+func parseInt(digits string, base int) (any, ErrorKind, bool) {
+	value, err := strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+	return parseResult(value, err)
+}
+
+// parseResult converts the result of parsing a validated number with strconv to the
+// result of `parseNumber`.
+func parseResult(value any, err error) (any, ErrorKind, bool) {
+	if errors.Is(err, strconv.ErrRange) {
+		return nil, NumberOutOfRange, false
+	}
+	if err != nil {
+		return nil, InvalidNumber, false
+	}
+	return value, 0, true
+}
+
+// validDigits determines if the text is a non-empty sequence of digits in the given base,
+// where every `_` is between two digits.
+func validDigits(text string, base int) bool {
+	if text == "" || text[0] == '_' || text[len(text)-1] == '_' || strings.Contains(text, "__") {
+		return false
+	}
+	for _, char := range text {
+		if char == '_' {
+			continue
+		}
+		digit, ok := hexDigit(char)
+		if !ok || int(digit) >= base {
+			return false
+		}
+	}
+	return true
 }
 
 // handleIdentifier processes a user identifier or a
@@ -249,38 +397,221 @@ func (lexer *Lexer) handleIdentifier() {
 	lexer.tokens = append(lexer.tokens, lexeme)
 }
 
-// handleStringLiteral processes string literals in the input.
+// handleStringLiteral processes string literals in the input. A string is delimited by
+// `"` or, to contain unescaped quotes, by `"""`. Both can span several lines.
+//
+// Escape sequences are replaced by the character they represent, see `readEscape`. A raw string,
+// prefixed by `r`, keeps its backslashes as they are written, e.g `r"C:\dir"`.
+//
+// A string which is not raw can contain interpolated expressions, e.g `"Hello ${name}!"`, see `scanString`.
 //
 // Returns:
 //   - nil if the string literal is properly closed and processed
-//   - error if the string literal is unclosed or has new lines
-func (lexer *Lexer) handleStringLiteral() error {
+//   - the LexErrors found if the string literal is unclosed or contains invalid escape sequences.
+func (lexer *Lexer) handleStringLiteral(raw bool) []error {
+	str := stringLiteral{start: lexer.position, delimiter: 1}
+	if raw {
+		// skip the `r` prefix
+		lexer.advance()
+	}
+	if lexer.peek() == '"' && lexer.peekNext() == '"' {
+		str.delimiter = 3
+		lexer.advance()
+		lexer.advance()
+	}
+	return lexer.scanString(str, raw)
+}
 
-	initPos := lexer.position
-	isClosed := false
+// stringLiteral describes a string literal being scanned.
+type stringLiteral struct {
+	// The index of the first character of the string.
+	start int
+	// The number of quotes delimiting the string.
+	delimiter int
+	// The number of `{` opened and not closed yet in the expression being interpolated.
+	braces int
+	// The index of the `${` starting the expression being interpolated, and the number of tokens
+	// scanned before the expression.
+	interpolation int
+	tokens        int
+}
+
+// scanString scans the contents of a string up to its closing delimiter, starting after its
+// opening delimiter or after the `}` closing an interpolated expression.
+//
+// The lexeme of the string token is its source code between the delimiters and its literal is its value.
+//
+// A string containing interpolated expressions, e.g `"a${x}b${y}c"`, is scanned in parts:
+// the text before every `${` is an INTERPOLATION token, followed by the tokens of the expression,
+// and the text after the last expression is a STRING token, i.e:
+//
+//	INTERPOLATION("a") IDENTIFIER(x) INTERPOLATION("b") IDENTIFIER(y) STRING("c")
+//
+// The string is kept in `interpolations` while its expression is scanned, until the `}` ending it.
+//
+// Returns:
+//   - nil if the part of the string was successfully scanned
+//   - the LexErrors found if the string literal is unclosed or contains invalid escape sequences.
+//     Scanning continues until the end of the part after an invalid escape sequence, so all
+//     of them are reported.
+func (lexer *Lexer) scanString(str stringLiteral, raw bool) []error {
+	contentStart := lexer.readPosition
+
+	var value strings.Builder
+	var errs []error
+	tokenType := token.TokenType("")
 	for {
 		result := lexer.peek()
 		if result == 0 {
 			break
 		}
+		if result == '"' && lexer.isDelimiter(str.delimiter) {
+			for i := 0; i < str.delimiter; i++ {
+				lexer.advance()
+			}
+			tokenType = token.STRING
+			break
+		}
+		if result == '$' && lexer.peekNext() == '{' && !raw {
+			lexer.advance()
+			lexer.advance()
+			tokenType = token.INTERPOLATION
+			break
+		}
 
 		lexer.advance()
-		if result == '"' {
-			isClosed = true
-			break
+		switch {
+		case result == '\\' && !raw:
+			char, err := lexer.readEscape()
+			if err != nil {
+				errs = append(errs, *err)
+			}
+			value.WriteString(char)
+		default:
+			value.WriteRune(result)
 		}
 	}
 
-	if !isClosed {
-		return fmt.Errorf("unclosed string literal: '%s', line: %v", string(lexer.characters[initPos+1:lexer.readPosition]), lexer.lineCount)
+	if tokenType == "" {
+		err := lexer.lexError(UnterminatedString, str.start, lexer.totalChars)
+		err.Lexeme = string(lexer.characters[contentStart:lexer.readPosition])
+		return append(errs, err)
 	}
 
-	// NOTE: `initPos+1`` and `lexer.position` is to ignore escape characters.
-	// as we dont need to store them for a literal string token
-	// "\"foo\"" -> "foo"
-	stringLiteral := string(lexer.characters[initPos+1 : lexer.position])
-	lexer.tokens = append(lexer.tokens, token.CreateLiteralToken(token.STRING, stringLiteral, stringLiteral, lexer.lineCount, lexer.column))
-	return nil
+	delimiter := str.delimiter
+	if tokenType == token.INTERPOLATION {
+		delimiter = len("${")
+	}
+	if len(errs) == 0 {
+		lexeme := string(lexer.characters[contentStart : lexer.readPosition-delimiter])
+		lexer.tokens = append(lexer.tokens, token.CreateLiteralToken(tokenType, value.String(), lexeme))
+	}
+	if tokenType == token.INTERPOLATION {
+		// NOTE: The string is kept even if the part contains errors, so the rest of it is scanned as expected.
+		str.interpolation = lexer.readPosition - delimiter
+		str.tokens = len(lexer.tokens)
+		lexer.interpolations = append(lexer.interpolations, str)
+	}
+	return errs
+}
+
+// isDelimiter determines if the next `length` characters close a string delimited by
+// `length` quotes.
+func (lexer *Lexer) isDelimiter(length int) bool {
+	for i := 0; i < length; i++ {
+		index := lexer.readPosition + i
+		if index >= lexer.totalChars || lexer.characters[index] != '"' {
+			return false
+		}
+	}
+	return true
+}
+
+// readEscape reads the escape sequence after a backslash in a string literal and returns the
+// text it represents. The following escape sequences are supported:
+//   - `\n`, `\t`, `\r` and `\0`: newline, tab, carriage return and null characters
+//   - `\\`, `\"` and `\$`: a backslash, a quote and a dollar sign, e.g to write `\${` without interpolating
+//   - `\uXXXX` and `\u{X...}`: the unicode code point with 4, or 1 to 6, hexadecimal digits
+//
+// Returns:
+//   - string: The text represented by the escape sequence, empty if it is invalid.
+//   - *LexError: An InvalidEscape error spanning the escape sequence if it is invalid, otherwise nil.
+func (lexer *Lexer) readEscape() (string, *LexError) {
+	start := lexer.position
+	char := lexer.peek()
+	switch char {
+	case 'n':
+		lexer.advance()
+		return "\n", nil
+	case 't':
+		lexer.advance()
+		return "\t", nil
+	case 'r':
+		lexer.advance()
+		return "\r", nil
+	case '0':
+		lexer.advance()
+		return "\x00", nil
+	case '\\', '"', '$':
+		lexer.advance()
+		return string(char), nil
+	case 'u':
+		lexer.advance()
+		if codePoint, ok := lexer.readCodePoint(); ok {
+			return string(codePoint), nil
+		}
+	case 0, '\n':
+		// NOTE: A newline is not consumed so it is kept in the string by `scanString`.
+	default:
+		lexer.advance()
+	}
+	err := lexer.lexError(InvalidEscape, start, lexer.readPosition)
+	return "", &err
+}
+
+// readCodePoint reads the hexadecimal digits of a `\u` escape sequence, either 4 digits or
+// 1 to 6 digits between braces. Only valid unicode code points are accepted.
+func (lexer *Lexer) readCodePoint() (rune, bool) {
+	braced := lexer.peek() == '{'
+	if braced {
+		lexer.advance()
+	}
+	digits := 0
+	var codePoint rune
+	for digits < 6 {
+		digit, ok := hexDigit(lexer.peek())
+		if !ok {
+			break
+		}
+		codePoint = codePoint*16 + digit
+		digits++
+		lexer.advance()
+		if !braced && digits == 4 {
+			break
+		}
+	}
+	if braced {
+		if lexer.peek() != '}' {
+			return 0, false
+		}
+		lexer.advance()
+	} else if digits != 4 {
+		return 0, false
+	}
+	return codePoint, digits > 0 && utf8.ValidRune(codePoint)
+}
+
+// hexDigit returns the value of a hexadecimal digit.
+func hexDigit(char rune) (rune, bool) {
+	switch {
+	case '0' <= char && char <= '9':
+		return char - '0', true
+	case 'a' <= char && char <= 'f':
+		return char - 'a' + 10, true
+	case 'A' <= char && char <= 'F':
+		return char - 'A' + 10, true
+	}
+	return 0, false
 }
 
 // Determines if the next character in the source code
@@ -313,16 +644,7 @@ func (lexer *Lexer) isMatch(expected rune) bool {
 //   - bool: true if the character is considered whitespace, otherwise false.
 func (lexer *Lexer) isWhiteSpace(char rune) bool {
 
-	if char == rune(' ') || char == rune('\r') || char == rune('\t') {
-		return true
-	}
-	if lexer.currentChar == rune('\n') {
-		// increment line count and reset column back to zero
-		lexer.lineCount++
-		lexer.column = 0
-		return true
-	}
-	return false
+	return char == rune(' ') || char == rune('\r') || char == rune('\t') || char == rune('\n')
 }
 
 // Skips all whitespaces in the input while advancing the `Lexer`'s position
@@ -332,6 +654,18 @@ func (lexer *Lexer) skipWhiteSpace() {
 	}
 }
 
+// lexError creates a LexError spanning the characters from index `start` up to `end`.
+func (lexer *Lexer) lexError(kind ErrorKind, start int, end int) LexError {
+	// NOTE: Illegal text is read up to and including the whitespace after it, which is not spanned.
+	lexeme := []rune(strings.TrimRight(string(lexer.characters[start:min(end, lexer.totalChars)]), whitespace))
+	return LexError{
+		Kind:   kind,
+		Lexeme: string(lexeme),
+		Start:  lexer.positionAt(start),
+		End:    lexer.positionAt(start + len(lexeme)),
+	}
+}
+
 // Processes the current character and creates a token if applicable.
 //
 // This method is responsible for identifying and creating tokens based on the current
@@ -339,91 +673,147 @@ func (lexer *Lexer) skipWhiteSpace() {
 func (lexer *Lexer) createToken() {
 
 	lexer.skipWhiteSpace()
+	start := lexer.position
+	scanned := len(lexer.tokens)
 
 	switch lexer.currentChar {
 	case rune('('):
-		tok := token.CreateToken(token.LPA, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.LPA)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune(')'):
-		tok := token.CreateToken(token.RPA, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.RPA)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('{'):
-		tok := token.CreateToken(token.LCUR, lexer.lineCount, lexer.column)
+		if len(lexer.interpolations) > 0 {
+			lexer.interpolations[len(lexer.interpolations)-1].braces++
+		}
+		tok := token.CreateToken(token.LCUR)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('}'):
-		tok := token.CreateToken(token.RCUR, lexer.lineCount, lexer.column)
+		if last := len(lexer.interpolations) - 1; last >= 0 {
+			str := lexer.interpolations[last]
+			if str.braces == 0 {
+				// The `}` ends the interpolated expression, the rest of the string is scanned.
+				lexer.interpolations = lexer.interpolations[:last]
+				if len(lexer.tokens) == str.tokens {
+					lexer.errors = append(lexer.errors, lexer.lexError(EmptyInterpolation, str.interpolation, lexer.readPosition))
+				}
+				lexer.errors = append(lexer.errors, lexer.scanString(str, false)...)
+				break
+			}
+			lexer.interpolations[last].braces--
+		}
+		tok := token.CreateToken(token.RCUR)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune(';'):
-		tok := token.CreateToken(token.SEMICOLON, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.SEMICOLON)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune(','):
-		tok := token.CreateToken(token.COMMA, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.COMMA)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('*'):
-		tok := token.CreateToken(token.MULT, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.MULT)
+		if lexer.isMatch(rune('*')) {
+			tok = token.CreateToken(token.POW)
+		} else if lexer.isMatch(rune('=')) {
+			tok = token.CreateToken(token.MULT_ASSIGN)
+		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('+'):
-		tok := token.CreateToken(token.ADD, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.ADD)
+		if lexer.isMatch(rune('=')) {
+			tok = token.CreateToken(token.ADD_ASSIGN)
+		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('-'):
-		tok := token.CreateToken(token.SUB, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.SUB)
+		if lexer.isMatch(rune('=')) {
+			tok = token.CreateToken(token.SUB_ASSIGN)
+		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('/'):
-		tok := token.CreateToken(token.DIV, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.DIV)
+		if lexer.isMatch(rune('/')) {
+			tok = token.CreateToken(token.FLOOR_DIV)
+		} else if lexer.isMatch(rune('=')) {
+			tok = token.CreateToken(token.DIV_ASSIGN)
+		}
+		lexer.tokens = append(lexer.tokens, tok)
+	case rune('%'):
+		tok := token.CreateToken(token.MOD)
+		lexer.tokens = append(lexer.tokens, tok)
+	case rune('&'):
+		tok := token.CreateToken(token.BIT_AND)
+		lexer.tokens = append(lexer.tokens, tok)
+	case rune('|'):
+		tok := token.CreateToken(token.BIT_OR)
+		lexer.tokens = append(lexer.tokens, tok)
+	case rune('^'):
+		tok := token.CreateToken(token.BIT_XOR)
+		lexer.tokens = append(lexer.tokens, tok)
+	case rune('~'):
+		tok := token.CreateToken(token.BIT_NOT)
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('='):
-		tok := token.CreateToken(token.ASSIGN, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.ASSIGN)
 		if lexer.isMatch(rune('=')) {
-			tok = token.CreateToken(token.EQUAL_EQUAL, lexer.lineCount, lexer.column)
+			tok = token.CreateToken(token.EQUAL_EQUAL)
 		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('!'):
-		tok := token.CreateToken(token.BANG, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.BANG)
 		if lexer.isMatch(rune('=')) {
-			tok = token.CreateToken(token.NOT_EQUAL, lexer.lineCount, lexer.column)
+			tok = token.CreateToken(token.NOT_EQUAL)
 		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('<'):
-		tok := token.CreateToken(token.LESS, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.LESS)
 		if lexer.isMatch(rune('=')) {
-			tok = token.CreateToken(token.LESS_EQUAL, lexer.lineCount, lexer.column)
+			tok = token.CreateToken(token.LESS_EQUAL)
+		} else if lexer.isMatch(rune('<')) {
+			tok = token.CreateToken(token.SHIFT_LEFT)
 		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('>'):
-		tok := token.CreateToken(token.LARGER, lexer.lineCount, lexer.column)
+		tok := token.CreateToken(token.LARGER)
 		if lexer.isMatch(rune('=')) {
-			tok = token.CreateToken(token.LARGER_EQUAL, lexer.lineCount, lexer.column)
+			tok = token.CreateToken(token.LARGER_EQUAL)
+		} else if lexer.isMatch(rune('>')) {
+			tok = token.CreateToken(token.SHIFT_RIGHT)
 		}
 		lexer.tokens = append(lexer.tokens, tok)
 	case rune('"'):
-		err := lexer.handleStringLiteral()
-		if err != nil {
-
-			lexer.errors = append(lexer.errors, err)
-		}
+		lexer.errors = append(lexer.errors, lexer.handleStringLiteral(false)...)
 
 	case rune(COMMENT_CHAR):
 		lexer.handleComment()
 	default:
-		if isLetter(lexer.currentChar) {
+		if lexer.currentChar == rune('r') && lexer.peek() == rune('"') {
+			lexer.errors = append(lexer.errors, lexer.handleStringLiteral(true)...)
+		} else if isLetter(lexer.currentChar) {
 			lexer.handleIdentifier()
-		} else if isNumber(lexer.currentChar) || lexer.currentChar == rune('.') {
+		} else if isNumber(lexer.currentChar) || lexer.currentChar == rune('.') && isNumber(lexer.peek()) {
 			err := lexer.handleNumber()
 			if err != nil {
 				lexer.errors = append(lexer.errors, err)
 			}
-		} else if !lexer.isFinished() {
-
-			position := lexer.position
-			column := lexer.column
-			currentChar := lexer.currentChar
-			illegal := lexer.readIllegal(position)
-
-			err := fmt.Errorf("unexpected character: '%c' in: '%s', line: %v, column: %v", currentChar, illegal, lexer.lineCount, column)
+		} else if lexer.currentChar == rune('.') {
+			tok := token.CreateToken(token.DOT)
+			lexer.tokens = append(lexer.tokens, tok)
+		} else if lexer.currentChar != rune(0) {
+			err := lexer.lexError(UnexpectedCharacter, start, start+1)
+			// NOTE: The rest of the illegal text is skipped, so it is reported as a single error.
+			err.Lexeme = strings.TrimRight(lexer.readIllegal(start), whitespace)
 			lexer.errors = append(lexer.errors, err)
 		}
 	}
 
+	// NOTE: Once a token is scanned, its last character is the one before `readPosition`.
+	if len(lexer.tokens) > scanned {
+		tok := &lexer.tokens[len(lexer.tokens)-1]
+		tok.Start = lexer.positionAt(start)
+		tok.End = lexer.positionAt(lexer.readPosition)
+	}
 	lexer.readChar()
 }
 
@@ -431,27 +821,30 @@ func (lexer *Lexer) createToken() {
 //
 // This method is the main entry point for the lexical analysis process. It iterates
 // through the input, tokenizing it and collecting all tokens until the end of the input
-// is reached or an error occurs.
+// is reached. Scanning continues after an error, so all the errors in the input are reported at once.
 //
 // Returns:
-//   - []token.Token: A slice containing all tokens found in the input.
-//   - error: An error if any issues occurred during lexing, or nil if successful.
-func (lexer *Lexer) Scan() ([]token.Token, error) {
+//   - []token.Token: A slice containing all tokens found in the input, ending with an EOF token.
+//   - []error: The LexErrors found in the input, in the order they appear, or nil if successful.
+func (lexer *Lexer) Scan() ([]token.Token, []error) {
 
 	if lexer.totalChars > 1 {
 		for lexer.currentChar != rune(0) {
 			lexer.createToken()
-			if len(lexer.errors) == 1 {
-				return lexer.tokens, lexer.errors[0]
-			}
 		}
 	} else {
 		// special handling for inputs with a single character or empty inputs.
 		lexer.createToken()
-		if len(lexer.errors) == 1 {
-			return lexer.tokens, lexer.errors[0]
-		}
 	}
-	lexer.tokens = append(lexer.tokens, token.CreateToken(token.EOF, lexer.lineCount, lexer.column))
-	return lexer.tokens, nil
+	if len(lexer.interpolations) > 0 {
+		// NOTE: The input ended inside an interpolated expression, e.g `"a ${b`.
+		str := lexer.interpolations[0]
+		err := lexer.lexError(UnterminatedString, str.start, lexer.totalChars)
+		lexer.errors = append(lexer.errors, err)
+	}
+	eof := token.CreateToken(token.EOF)
+	eof.Start = lexer.positionAt(lexer.totalChars)
+	eof.End = eof.Start
+	lexer.tokens = append(lexer.tokens, eof)
+	return lexer.tokens, lexer.errors
 }
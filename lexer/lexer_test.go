@@ -1,15 +1,18 @@
 package lexer
 
 import (
+	"nilan/diagnostics"
 	"nilan/token"
+	"nilan/value"
+	"strings"
 	"testing"
 )
 
 func runTest(expected []token.Token, scanner *Lexer, t *testing.T) {
 
-	result, err := scanner.Scan()
-	if err != nil {
-		t.Errorf("scanner.Scan() raised an error: %v", err)
+	result, lexErrs := scanner.Scan()
+	if len(lexErrs) > 0 {
+		t.Errorf("scanner.Scan() raised an error: %v", lexErrs)
 	}
 
 	for i, tt := range expected {
@@ -33,35 +36,34 @@ func runTest(expected []token.Token, scanner *Lexer, t *testing.T) {
 func TestComments(t *testing.T) {
 
 	expected := []token.Token{
-		token.CreateToken(token.LPA, 0, 0),
-		token.CreateToken(token.RPA, 0, 0),
-		token.CreateToken(token.LCUR, 0, 0),
-		token.CreateToken(token.RCUR, 0, 0),
-		token.CreateToken(token.MULT, 0, 0),
-		token.CreateToken(token.MULT, 0, 0),
-		token.CreateToken(token.SEMICOLON, 0, 0),
-		token.CreateToken(token.ADD, 0, 0),
-		token.CreateToken(token.BANG, 0, 0),
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateToken(token.LESS, 0, 0),
-		token.CreateToken(token.EQUAL_EQUAL, 0, 0),
-		token.CreateToken(token.EQUAL_EQUAL, 0, 0),
-		token.CreateToken(token.EQUAL_EQUAL, 0, 0),
-		token.CreateToken(token.EQUAL_EQUAL, 0, 0),
-		token.CreateToken(token.NOT_EQUAL, 0, 0),
-		token.CreateToken(token.LESS_EQUAL, 0, 0),
-		token.CreateToken(token.LESS_EQUAL, 0, 0),
-		token.CreateToken(token.LARGER_EQUAL, 0, 0),
-		token.CreateToken(token.LARGER_EQUAL, 0, 0),
-		token.CreateToken(token.EQUAL_EQUAL, 0, 0),
-		token.CreateToken(token.NOT_EQUAL, 0, 0),
+		token.CreateToken(token.LPA),
+		token.CreateToken(token.RPA),
+		token.CreateToken(token.LCUR),
+		token.CreateToken(token.RCUR),
+		token.CreateToken(token.POW),
+		token.CreateToken(token.SEMICOLON),
+		token.CreateToken(token.ADD),
+		token.CreateToken(token.BANG),
+		token.CreateToken(token.ASSIGN),
+		token.CreateToken(token.LESS),
+		token.CreateToken(token.EQUAL_EQUAL),
+		token.CreateToken(token.EQUAL_EQUAL),
+		token.CreateToken(token.EQUAL_EQUAL),
+		token.CreateToken(token.EQUAL_EQUAL),
+		token.CreateToken(token.NOT_EQUAL),
+		token.CreateToken(token.LESS_EQUAL),
+		token.CreateToken(token.LESS_EQUAL),
+		token.CreateToken(token.LARGER_EQUAL),
+		token.CreateToken(token.LARGER_EQUAL),
+		token.CreateToken(token.EQUAL_EQUAL),
+		token.CreateToken(token.NOT_EQUAL),
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "my_var",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateToken(token.LCUR, 0, 0),
-		token.CreateToken(token.RCUR, 0, 0),
-		token.CreateToken(token.EOF, 0, 0),
+		token.CreateToken(token.ASSIGN),
+		token.CreateToken(token.LCUR),
+		token.CreateToken(token.RCUR),
+		token.CreateToken(token.EOF),
 	}
 
 	test := `
@@ -87,6 +89,41 @@ func TestComments(t *testing.T) {
 
 }
 
+func TestCommentTrivia(t *testing.T) {
+	source := "# header\nvar a = 1 # one\r\n\n#last"
+	lexer := New(source)
+	tokens, lexErrs := lexer.Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("unexpected error: %v", lexErrs)
+	}
+
+	expected := []token.Token{
+		{TokenType: token.COMMENT, Lexeme: "# header", Start: token.Position{Line: 1, Column: 1, Offset: 0}},
+		{TokenType: token.COMMENT, Lexeme: "# one", Start: token.Position{Line: 2, Column: 11, Offset: 19}},
+		{TokenType: token.COMMENT, Lexeme: "#last", Start: token.Position{Line: 4, Column: 1, Offset: 27}},
+	}
+	comments := lexer.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("got %d comments, want: %d", len(comments), len(expected))
+	}
+	for i, want := range expected {
+		got := comments[i]
+		if got.TokenType != want.TokenType || got.Lexeme != want.Lexeme || got.Start != want.Start {
+			t.Errorf("got comment: %+v, want: %+v", got, want)
+		}
+	}
+
+	// Comments are not part of the token sequence, but they end a line.
+	for _, tok := range tokens {
+		if tok.TokenType == token.COMMENT {
+			t.Errorf("unexpected comment token: %+v", tok)
+		}
+	}
+	if eof := tokens[len(tokens)-1]; eof.Start.Line != 4 {
+		t.Errorf("got EOF on line %d, want: 4", eof.Start.Line)
+	}
+}
+
 func TestLiteralStrings(t *testing.T) {
 
 	multiLine := `
@@ -100,20 +137,20 @@ func TestLiteralStrings(t *testing.T) {
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myString",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateLiteralToken(token.STRING, "hellow", "hellow", 0, 0),
+		token.CreateToken(token.ASSIGN),
+		token.CreateLiteralToken(token.STRING, "hellow", "hellow"),
 
-		token.CreateLiteralToken(token.STRING, "hi", "hi", 0, 0),
+		token.CreateLiteralToken(token.STRING, "hi", "hi"),
 		{
 			TokenType: token.VAR, Lexeme: "var",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "tabedString",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateLiteralToken(token.STRING, "tabed	", "tabed	", 0, 0),
-		token.CreateLiteralToken(token.STRING, multiLine, multiLine, 0, 0),
-		token.CreateToken(token.EOF, 0, 0),
+		token.CreateToken(token.ASSIGN),
+		token.CreateLiteralToken(token.STRING, "tabed	", "tabed	"),
+		token.CreateLiteralToken(token.STRING, multiLine, multiLine),
+		token.CreateToken(token.EOF),
 	}
 	test := `
 	var myString = "hellow" "hi"
@@ -138,19 +175,19 @@ func TestHandleStringLiteralErrors(t *testing.T) {
 			name:    "Unclosed string literal",
 			input:   `var c ="unclosed`,
 			wantErr: true,
-			errMsg:  "unclosed string literal: 'unclosed', line: 0",
+			errMsg:  "unterminated string literal",
 		},
 		{
 			name:    "Only opening quote",
 			input:   `"`,
 			wantErr: true,
-			errMsg:  "unclosed string literal: '', line: 0",
+			errMsg:  "unterminated string literal",
 		},
 		{
 			name:    "String literal at end of input",
 			input:   `hello "world`,
 			wantErr: true,
-			errMsg:  "unclosed string literal: 'world', line: 0",
+			errMsg:  "unterminated string literal",
 		},
 	}
 
@@ -158,14 +195,14 @@ func TestHandleStringLiteralErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			scanner := New(tt.input)
 
-			_, err := scanner.Scan()
+			_, errs := scanner.Scan()
 
-			if err == nil {
+			if len(errs) == 0 {
 				t.Errorf("handleStringLiteral() error = nil, wantErr %v", tt.wantErr)
 				return
 			}
-			if err.Error() != tt.errMsg {
-				t.Errorf("handleStringLiteral() error = %v, wantErr %v", err, tt.errMsg)
+			if errs[0].Error() != tt.errMsg {
+				t.Errorf("handleStringLiteral() error = %v, wantErr %v", errs[0], tt.errMsg)
 			}
 
 		})
@@ -183,13 +220,115 @@ func TestHandleNumberErrors(t *testing.T) {
 			name:    "Malformed decimal number A",
 			input:   `1.11.`,
 			wantErr: true,
-			errMsg:  "invalid number: '1.11.', line: 0",
+			errMsg:  "invalid number '1.11.'",
 		},
 		{
 			name:    "Malformed decimal number A",
 			input:   `0.000.111`,
 			wantErr: true,
-			errMsg:  "invalid number: '0.000.111', line: 0",
+			errMsg:  "invalid number '0.000.111'",
+		},
+		{
+			name:    "Decimal point at end of input",
+			input:   `var a = 1.`,
+			wantErr: true,
+			errMsg:  "invalid number '1.'",
+		},
+		{
+			name:    "Decimal point before a newline",
+			input:   "var a = 1.\nprint a",
+			wantErr: true,
+			errMsg:  "invalid number '1.'",
+		},
+		{
+			name:    "Letters after digits",
+			input:   `12abc`,
+			wantErr: true,
+			errMsg:  "invalid number '12abc'",
+		},
+		{
+			name:    "Hexadecimal without digits",
+			input:   `0x`,
+			wantErr: true,
+			errMsg:  "invalid number '0x'",
+		},
+		{
+			name:    "Invalid binary digit",
+			input:   `0b102`,
+			wantErr: true,
+			errMsg:  "invalid number '0b102'",
+		},
+		{
+			name:    "Invalid octal digit",
+			input:   `0o8`,
+			wantErr: true,
+			errMsg:  "invalid number '0o8'",
+		},
+		{
+			name:    "Float with a prefix",
+			input:   `0x1.5`,
+			wantErr: true,
+			errMsg:  "invalid number '0x1.5'",
+		},
+		{
+			name:    "Trailing underscore",
+			input:   `1_000_`,
+			wantErr: true,
+			errMsg:  "invalid number '1_000_'",
+		},
+		{
+			name:    "Consecutive underscores",
+			input:   `1__000`,
+			wantErr: true,
+			errMsg:  "invalid number '1__000'",
+		},
+		{
+			name:    "Underscore next to the decimal point",
+			input:   `1_.5`,
+			wantErr: true,
+			errMsg:  "invalid number '1_.5'",
+		},
+		{
+			name:    "Exponent without digits",
+			input:   `1e+`,
+			wantErr: true,
+			errMsg:  "invalid number '1e+'",
+		},
+		{
+			name:    "Several exponents",
+			input:   `1e2e3`,
+			wantErr: true,
+			errMsg:  "invalid number '1e2e3'",
+		},
+		{
+			name:    "Integer out of range",
+			input:   `9223372036854775808`,
+			wantErr: true,
+			errMsg:  "number '9223372036854775808' is out of range",
+		},
+		{
+			name:    "Hexadecimal out of range",
+			input:   `0x1_0000_0000_0000_0000`,
+			wantErr: true,
+			errMsg:  "number '0x1_0000_0000_0000_0000' is out of range",
+		},
+		{
+			name:    "Float out of range",
+			input:   `1e400`,
+			wantErr: true,
+			errMsg:  "number '1e400' is out of range",
+		},
+		{
+			name:    "Decimal without fraction digits",
+			input:   `1.d`,
+			wantErr: true,
+			errMsg:  "invalid number '1.d'",
+		},
+		{
+			name:    "Decimal exponent out of range",
+			input:   `1e1001d`,
+			wantErr: true,
+			errMsg:  "number '1e1001d' is out of range",
 		},
 	}
 
@@ -197,14 +336,14 @@ func TestHandleNumberErrors(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			scanner := New(tt.input)
 
-			_, err := scanner.Scan()
+			_, errs := scanner.Scan()
 
-			if err == nil {
+			if len(errs) == 0 {
 				t.Errorf("handleNumber() error = nil, wantErr %v", tt.wantErr)
 				return
 			}
-			if err.Error() != tt.errMsg {
-				t.Errorf("handleNumber() error = %v, wantErr %v", err, tt.errMsg)
+			if errs[0].Error() != tt.errMsg {
+				t.Errorf("handleNumber() error = %v, wantErr %v", errs[0], tt.errMsg)
 			}
 
 		})
@@ -213,9 +352,9 @@ func TestHandleNumberErrors(t *testing.T) {
 
 func TestHandleNumber(t *testing.T) {
 	expected := []token.Token{
-		token.CreateLiteralToken(token.FLOAT, float64(0.2), ".2", 0, 0),
-		token.CreateLiteralToken(token.FLOAT, float64(0.0001), "0.0001", 0, 0),
-		token.CreateLiteralToken(token.INT, int64(1000), "1000", 0, 0),
+		token.CreateLiteralToken(token.FLOAT, float64(0.2), ".2"),
+		token.CreateLiteralToken(token.FLOAT, float64(0.0001), "0.0001"),
+		token.CreateLiteralToken(token.INT, int64(1000), "1000"),
 	}
 	test := `
 	.2
@@ -226,6 +365,87 @@ func TestHandleNumber(t *testing.T) {
 	runTest(expected, scanner, t)
 }
 
+func TestHandleNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  any
+	}{
+		{input: "0xff", want: int64(255)},
+		{input: "0XFF", want: int64(255)},
+		{input: "0b1010", want: int64(10)},
+		{input: "0o17", want: int64(15)},
+		{input: "010", want: int64(10)},
+		{input: "1_000_000", want: int64(1000000)},
+		{input: "0xff_ff", want: int64(65535)},
+		{input: "9223372036854775807", want: int64(9223372036854775807)},
+		{input: "1e3", want: float64(1000)},
+		{input: "1.5e-3", want: float64(0.0015)},
+		{input: "2E+2", want: float64(200)},
+		{input: ".5e1", want: float64(5)},
+		{input: "1_000.000_1", want: float64(1000.0001)},
+		{input: "1e1_0", want: float64(1e10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, errs := New(tt.input).Scan()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if len(tokens) != 2 {
+				t.Fatalf("got tokens: %v, want a single number", tokens)
+			}
+			if tokens[0].Literal != tt.want {
+				t.Errorf("got value: %v (%T), want: %v (%T)", tokens[0].Literal, tokens[0].Literal, tt.want, tt.want)
+			}
+			if tokens[0].Lexeme != tt.input {
+				t.Errorf("got lexeme: %q, want: %q", tokens[0].Lexeme, tt.input)
+			}
+		})
+	}
+}
+
+func TestHandleDecimalLiterals(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "12.50d", want: "12.50"},
+		{input: "12D", want: "12"},
+		{input: ".5d", want: "0.5"},
+		{input: "0.001d", want: "0.001"},
+		{input: "1_000.000_1d", want: "1000.0001"},
+		{input: "1.5e3d", want: "1500"},
+		{input: "15e-3d", want: "0.015"},
+		{input: "99999999999999999999.99d", want: "99999999999999999999.99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, errs := New(tt.input).Scan()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if len(tokens) != 2 || tokens[0].TokenType != token.DECIMAL {
+				t.Fatalf("got tokens: %v, want a single decimal", tokens)
+			}
+			decimal, ok := tokens[0].Literal.(value.Decimal)
+			if !ok || decimal.String() != tt.want {
+				t.Errorf("got value: %v (%T), want the decimal %s", tokens[0].Literal, tokens[0].Literal, tt.want)
+			}
+			if tokens[0].Lexeme != tt.input {
+				t.Errorf("got lexeme: %q, want: %q", tokens[0].Lexeme, tt.input)
+			}
+		})
+	}
+
+	// NOTE: `d` is a hexadecimal digit, so hexadecimal numbers can not be decimals.
+	tokens, errs := New("0xfd").Scan()
+	if len(errs) > 0 || tokens[0].Literal != int64(0xfd) {
+		t.Errorf("got tokens: %v, errors: %v, want the integer 253", tokens, errs)
+	}
+}
+
 func TestScanSourceCode(t *testing.T) {
 
 	expected := []token.Token{
@@ -236,27 +456,27 @@ func TestScanSourceCode(t *testing.T) {
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myFunction",
 		},
-		token.CreateToken(token.LPA, 0, 0),
+		token.CreateToken(token.LPA),
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "a",
 		},
-		token.CreateToken(token.COMMA, 0, 0),
+		token.CreateToken(token.COMMA),
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "b",
 		},
-		token.CreateToken(token.RPA, 0, 0),
-		token.CreateToken(token.LCUR, 0, 0),
+		token.CreateToken(token.RPA),
+		token.CreateToken(token.LCUR),
 		{
 			TokenType: token.RETURN, Lexeme: "return",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "a",
 		},
-		token.CreateToken(token.ADD, 0, 0),
+		token.CreateToken(token.ADD),
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "b",
 		},
-		token.CreateToken(token.RCUR, 0, 0),
+		token.CreateToken(token.RCUR),
 
 		{
 			TokenType: token.VAR, Lexeme: "var",
@@ -264,61 +484,61 @@ func TestScanSourceCode(t *testing.T) {
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "result",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
+		token.CreateToken(token.ASSIGN),
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myFunction",
 		},
-		token.CreateToken(token.LPA, 0, 0),
-		token.CreateLiteralToken(token.INT, int64(2), "2", 0, 0),
-		token.CreateToken(token.ADD, 0, 0),
-		token.CreateLiteralToken(token.INT, int64(5), "5", 0, 0),
-		token.CreateToken(token.RPA, 0, 0),
+		token.CreateToken(token.LPA),
+		token.CreateLiteralToken(token.INT, int64(2), "2"),
+		token.CreateToken(token.ADD),
+		token.CreateLiteralToken(token.INT, int64(5), "5"),
+		token.CreateToken(token.RPA),
 		{
 			TokenType: token.VAR, Lexeme: "var",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "_foo_bar",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateLiteralToken(token.FLOAT, 0.000001, "0.000001", 0, 0),
+		token.CreateToken(token.ASSIGN),
+		token.CreateLiteralToken(token.FLOAT, 0.000001, "0.000001"),
 		{
 			TokenType: token.VAR, Lexeme: "var",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myInt",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateLiteralToken(token.INT, int64(123), "123", 0, 0),
+		token.CreateToken(token.ASSIGN),
+		token.CreateLiteralToken(token.INT, int64(123), "123"),
 		{
 			TokenType: token.VAR, Lexeme: "var",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myNegativeInt",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
+		token.CreateToken(token.ASSIGN),
 		{
 			TokenType: token.SUB, Lexeme: "-",
 		},
-		token.CreateLiteralToken(token.INT, int64(123), "123", 0, 0),
+		token.CreateLiteralToken(token.INT, int64(123), "123"),
 		{
 			TokenType: token.VAR, Lexeme: "var",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myNegativeFloat",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
+		token.CreateToken(token.ASSIGN),
 		{
 			TokenType: token.SUB, Lexeme: "-",
 		},
-		token.CreateLiteralToken(token.FLOAT, 0.01, "0.01", 0, 0),
+		token.CreateLiteralToken(token.FLOAT, 0.01, "0.01"),
 		{
 			TokenType: token.VAR, Lexeme: "var",
 		},
 		{
 			TokenType: token.IDENTIFIER, Lexeme: "myString",
 		},
-		token.CreateToken(token.ASSIGN, 0, 0),
-		token.CreateLiteralToken(token.STRING, "hellow", "hellow", 0, 0),
+		token.CreateToken(token.ASSIGN),
+		token.CreateLiteralToken(token.STRING, "hellow", "hellow"),
 
 		{
 			TokenType: token.IF, Lexeme: "if",
@@ -335,7 +555,7 @@ func TestScanSourceCode(t *testing.T) {
 		{
 			TokenType: token.FOR, Lexeme: "for",
 		},
-		token.CreateToken(token.EOF, 0, 0),
+		token.CreateToken(token.EOF),
 	}
 	test := `
 	fn myFunction(a, b){
@@ -355,3 +575,360 @@ func TestScanSourceCode(t *testing.T) {
 	runTest(expected, scanner, t)
 
 }
+
+func TestTokenPositions(t *testing.T) {
+	// NOTE: Columns count characters, offsets count bytes, `é` is encoded with two bytes.
+	lexer := New("var a_b\n  x >= \"é\" # c\n1.5")
+	tokens, lexErrs := lexer.Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("unexpected error: %v", lexErrs)
+	}
+
+	expected := []struct {
+		tokenType  token.TokenType
+		start, end token.Position
+	}{
+		{token.VAR, token.Position{Line: 1, Column: 1, Offset: 0}, token.Position{Line: 1, Column: 4, Offset: 3}},
+		{token.IDENTIFIER, token.Position{Line: 1, Column: 5, Offset: 4}, token.Position{Line: 1, Column: 8, Offset: 7}},
+		{token.IDENTIFIER, token.Position{Line: 2, Column: 3, Offset: 10}, token.Position{Line: 2, Column: 4, Offset: 11}},
+		{token.LARGER_EQUAL, token.Position{Line: 2, Column: 5, Offset: 12}, token.Position{Line: 2, Column: 7, Offset: 14}},
+		{token.STRING, token.Position{Line: 2, Column: 8, Offset: 15}, token.Position{Line: 2, Column: 11, Offset: 19}},
+		{token.FLOAT, token.Position{Line: 3, Column: 1, Offset: 24}, token.Position{Line: 3, Column: 4, Offset: 27}},
+		{token.EOF, token.Position{Line: 3, Column: 4, Offset: 27}, token.Position{Line: 3, Column: 4, Offset: 27}},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("got %d tokens, want: %d", len(tokens), len(expected))
+	}
+	for i, want := range expected {
+		tok := tokens[i]
+		if tok.TokenType != want.tokenType || tok.Start != want.start || tok.End != want.end {
+			t.Errorf("got %s at %+v-%+v, want: %s at %+v-%+v", tok.TokenType, tok.Start, tok.End, want.tokenType, want.start, want.end)
+		}
+	}
+
+	comment := lexer.Comments()[0]
+	if want := (token.Position{Line: 2, Column: 12, Offset: 20}); comment.Start != want {
+		t.Errorf("got comment start: %+v, want: %+v", comment.Start, want)
+	}
+	if want := (token.Position{Line: 2, Column: 15, Offset: 23}); comment.End != want {
+		t.Errorf("got comment end: %+v, want: %+v", comment.End, want)
+	}
+}
+
+func TestScanReportsAllErrors(t *testing.T) {
+	source := "var a = 1.2.3\nprint $ + 1\nvar b = @\n\"unclosed"
+	tokens, errs := New(source).Scan()
+
+	want := []struct {
+		kind   ErrorKind
+		lexeme string
+		start  token.Position
+		end    token.Position
+	}{
+		{kind: InvalidNumber, lexeme: "1.2.3", start: token.Position{Line: 1, Column: 9, Offset: 8}, end: token.Position{Line: 1, Column: 14, Offset: 13}},
+		{kind: UnexpectedCharacter, lexeme: "$", start: token.Position{Line: 2, Column: 7, Offset: 20}, end: token.Position{Line: 2, Column: 8, Offset: 21}},
+		{kind: UnexpectedCharacter, lexeme: "@", start: token.Position{Line: 3, Column: 9, Offset: 34}, end: token.Position{Line: 3, Column: 10, Offset: 35}},
+		{kind: UnterminatedString, lexeme: "unclosed", start: token.Position{Line: 4, Column: 1, Offset: 36}, end: token.Position{Line: 4, Column: 10, Offset: 45}},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors: %v, want: %d", len(errs), errs, len(want))
+	}
+	for i, w := range want {
+		lexErr, ok := errs[i].(LexError)
+		if !ok {
+			t.Fatalf("expected a LexError, got %T", errs[i])
+		}
+		if lexErr.Kind != w.kind || lexErr.Lexeme != w.lexeme {
+			t.Errorf("got %s '%s', want: %s '%s'", lexErr.Kind, lexErr.Lexeme, w.kind, w.lexeme)
+		}
+		if lexErr.Start != w.start || lexErr.End != w.end {
+			t.Errorf("got span %v-%v for '%s', want: %v-%v", lexErr.Start, lexErr.End, lexErr.Lexeme, w.start, w.end)
+		}
+		if diagnostic := lexErr.Diagnostic(); diagnostic.Start != w.start || diagnostic.Code == diagnostics.CodeLexical {
+			t.Errorf("got diagnostic %+v for '%s', want a %s diagnostic at %v", diagnostic, lexErr.Lexeme, w.kind, w.start)
+		}
+	}
+
+	// NOTE: Scanning continues after an error, so the valid tokens are still returned.
+	var identifiers []string
+	for _, tok := range tokens {
+		if tok.TokenType == token.IDENTIFIER {
+			identifiers = append(identifiers, tok.Lexeme)
+		}
+	}
+	if strings.Join(identifiers, " ") != "a b" {
+		t.Errorf("got identifiers: %v, want: [a b]", identifiers)
+	}
+	if tokens[len(tokens)-1].TokenType != token.EOF {
+		t.Errorf("got last token: %s, want: EOF", tokens[len(tokens)-1].TokenType)
+	}
+}
+
+func TestUnexpectedCharacterAtEndOfInput(t *testing.T) {
+	for _, source := range []string{"$", "print $"} {
+		_, errs := New(source).Scan()
+		if len(errs) != 1 {
+			t.Fatalf("got %d errors for %q: %v, want: 1", len(errs), source, errs)
+		}
+		if lexErr, ok := errs[0].(LexError); !ok || lexErr.Kind != UnexpectedCharacter {
+			t.Errorf("got %v for %q, want an unexpected character error", errs[0], source)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		lexeme string
+	}{
+		{name: "newline and tab", input: `"a\nb\tc"`, want: "a\nb\tc", lexeme: `a\nb\tc`},
+		{name: "carriage return and null", input: `"\r\0"`, want: "\r\x00", lexeme: `\r\0`},
+		{name: "quote and backslash", input: `"say \"hi\" \\ bye"`, want: `say "hi" \ bye`, lexeme: `say \"hi\" \\ bye`},
+		{name: "unicode with 4 digits", input: `"caf\u00e9"`, want: "café", lexeme: `caf\u00e9`},
+		{name: "braced unicode", input: `"\u{1F600}!"`, want: "😀!", lexeme: `\u{1F600}!`},
+		{name: "triple quoted", input: "\"\"\"line 1\n\"quoted\"\nline 3\"\"\"", want: "line 1\n\"quoted\"\nline 3", lexeme: "line 1\n\"quoted\"\nline 3"},
+		{name: "triple quoted with escapes", input: `"""a\tb"""`, want: "a\tb", lexeme: `a\tb`},
+		{name: "empty triple quoted", input: `""""""`, want: "", lexeme: ""},
+		{name: "raw", input: `r"C:\new\dir"`, want: `C:\new\dir`, lexeme: `C:\new\dir`},
+		{name: "raw triple quoted", input: "r\"\"\"\\d+\n\"x\" \"\"\"", want: "\\d+\n\"x\" ", lexeme: "\\d+\n\"x\" "},
+		{name: "empty string", input: `""`, want: "", lexeme: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, errs := New(tt.input).Scan()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if len(tokens) != 2 || tokens[0].TokenType != token.STRING {
+				t.Fatalf("got tokens: %v, want a single string", tokens)
+			}
+			if tokens[0].Literal != tt.want {
+				t.Errorf("got value: %q, want: %q", tokens[0].Literal, tt.want)
+			}
+			if tokens[0].Lexeme != tt.lexeme {
+				t.Errorf("got lexeme: %q, want: %q", tokens[0].Lexeme, tt.lexeme)
+			}
+			if tokens[0].Start.Offset != 0 || tokens[0].End.Offset != len(tt.input) {
+				t.Errorf("got span: %v-%v, want the whole input", tokens[0].Start, tokens[0].End)
+			}
+		})
+	}
+}
+
+func TestInvalidStringEscapes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "unknown escape", input: `"a\qb"`, want: []string{`\q`}},
+		{name: "all invalid escapes are reported", input: `"\x \u12"`, want: []string{`\x`, `\u12`}},
+		{name: "unclosed braced unicode", input: `"\u{12 "`, want: []string{`\u{12`}},
+		{name: "code point out of range", input: `"\u{110000}"`, want: []string{`\u{110000}`}},
+		{name: "surrogate code point", input: `"\uD800"`, want: []string{`\uD800`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, errs := New(tt.input).Scan()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("got %d errors: %v, want: %d", len(errs), errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				lexErr, ok := errs[i].(LexError)
+				if !ok || lexErr.Kind != InvalidEscape || lexErr.Lexeme != want {
+					t.Errorf("got error: %v, want an invalid escape sequence '%s'", errs[i], want)
+				}
+			}
+			if len(tokens) != 1 {
+				t.Errorf("got tokens: %v, want only EOF", tokens)
+			}
+		})
+	}
+}
+
+func TestLinesAfterMultiLineStrings(t *testing.T) {
+	source := "var a = \"one\ntwo\"\nvar b = \"\"\"three\n\nfour\"\"\"\nprint $"
+	tokens, errs := New(source).Scan()
+
+	want := []struct {
+		tokenType token.TokenType
+		line      int
+	}{
+		{token.VAR, 1}, {token.IDENTIFIER, 1}, {token.ASSIGN, 1}, {token.STRING, 1},
+		{token.VAR, 3}, {token.IDENTIFIER, 3}, {token.ASSIGN, 3}, {token.STRING, 3},
+		{token.PRINT, 6}, {token.EOF, 6},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens: %v, want: %d", len(tokens), tokens, len(want))
+	}
+	for i, w := range want {
+		if tokens[i].TokenType != w.tokenType || tokens[i].Start.Line != w.line {
+			t.Errorf("got %s on line %d, want: %s on line %d", tokens[i].TokenType, tokens[i].Start.Line, w.tokenType, w.line)
+		}
+	}
+	if tokens[7].End.Line != 5 {
+		t.Errorf("got the second string ending at %v, want it to end on line 5", tokens[7].End)
+	}
+	if len(errs) != 1 || errs[0].(LexError).Start.Line != 6 {
+		t.Errorf("got errors: %v, want an error on line 6", errs)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []token.Token
+	}{
+		{
+			name:  "single expression",
+			input: `"Hello ${name}!"`,
+			want: []token.Token{
+				{TokenType: token.INTERPOLATION, Lexeme: "Hello ", Literal: "Hello "},
+				{TokenType: token.IDENTIFIER, Lexeme: "name"},
+				{TokenType: token.STRING, Lexeme: "!", Literal: "!"},
+			},
+		},
+		{
+			name:  "several expressions with empty text",
+			input: `"${a}${b + 1}"`,
+			want: []token.Token{
+				{TokenType: token.INTERPOLATION, Lexeme: "", Literal: ""},
+				{TokenType: token.IDENTIFIER, Lexeme: "a"},
+				{TokenType: token.INTERPOLATION, Lexeme: "", Literal: ""},
+				{TokenType: token.IDENTIFIER, Lexeme: "b"},
+				{TokenType: token.ADD, Lexeme: "+"},
+				{TokenType: token.INT, Lexeme: "1", Literal: int64(1)},
+				{TokenType: token.STRING, Lexeme: "", Literal: ""},
+			},
+		},
+		{
+			name:  "nested string",
+			input: `"a${"b${c}"}d"`,
+			want: []token.Token{
+				{TokenType: token.INTERPOLATION, Lexeme: "a", Literal: "a"},
+				{TokenType: token.INTERPOLATION, Lexeme: "b", Literal: "b"},
+				{TokenType: token.IDENTIFIER, Lexeme: "c"},
+				{TokenType: token.STRING, Lexeme: "", Literal: ""},
+				{TokenType: token.STRING, Lexeme: "d", Literal: "d"},
+			},
+		},
+		{
+			name:  "escaped dollar",
+			input: `"\${a}"`,
+			want: []token.Token{
+				{TokenType: token.STRING, Lexeme: `\${a}`, Literal: "${a}"},
+			},
+		},
+		{
+			name:  "raw string",
+			input: `r"${a}"`,
+			want: []token.Token{
+				{TokenType: token.STRING, Lexeme: "${a}", Literal: "${a}"},
+			},
+		},
+		{
+			name:  "dollar without brace",
+			input: `"$5"`,
+			want: []token.Token{
+				{TokenType: token.STRING, Lexeme: "$5", Literal: "$5"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, errs := New(tt.input).Scan()
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if len(tokens) != len(tt.want)+1 {
+				t.Fatalf("got %d tokens: %v, want: %d", len(tokens), tokens, len(tt.want)+1)
+			}
+			for i, want := range tt.want {
+				got := tokens[i]
+				if got.TokenType != want.TokenType || got.Lexeme != want.Lexeme || got.Literal != want.Literal {
+					t.Errorf("got token %d: %s %q %v, want: %s %q %v", i, got.TokenType, got.Lexeme, got.Literal, want.TokenType, want.Lexeme, want.Literal)
+				}
+			}
+		})
+	}
+}
+
+func TestStringInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		kind   ErrorKind
+		lexeme string
+	}{
+		{name: "empty interpolation", input: `"a ${} b"`, kind: EmptyInterpolation, lexeme: "${}"},
+		{name: "empty interpolation with spaces", input: `"${  }"`, kind: EmptyInterpolation, lexeme: "${  }"},
+		{name: "unclosed expression", input: `"a ${b`, kind: UnterminatedString, lexeme: `"a ${b`},
+		{name: "unclosed string after expression", input: `"a ${b} c`, kind: UnterminatedString, lexeme: " c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := New(tt.input).Scan()
+			if len(errs) != 1 {
+				t.Fatalf("got errors: %v, want 1", errs)
+			}
+			lexErr, ok := errs[0].(LexError)
+			if !ok || lexErr.Kind != tt.kind || lexErr.Lexeme != tt.lexeme {
+				t.Errorf("got error: %#v, want a %s error for '%s'", errs[0], tt.kind, tt.lexeme)
+			}
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	expected := []token.Token{
+		token.CreateToken(token.MOD),
+		token.CreateToken(token.POW),
+		token.CreateToken(token.MULT),
+		token.CreateToken(token.FLOOR_DIV),
+		token.CreateToken(token.DIV),
+		token.CreateToken(token.BIT_AND),
+		token.CreateToken(token.BIT_OR),
+		token.CreateToken(token.BIT_XOR),
+		token.CreateToken(token.BIT_NOT),
+		token.CreateToken(token.SHIFT_LEFT),
+		token.CreateToken(token.LESS),
+		token.CreateToken(token.SHIFT_RIGHT),
+		token.CreateToken(token.LARGER_EQUAL),
+		token.CreateToken(token.ADD_ASSIGN),
+		token.CreateToken(token.SUB_ASSIGN),
+		token.CreateToken(token.MULT_ASSIGN),
+		token.CreateToken(token.DIV_ASSIGN),
+		token.CreateToken(token.EOF),
+	}
+
+	test := `% *** /// & | ^ ~ <<< >>>= += -= *= /=`
+
+	scanner := New(test)
+	runTest(expected, scanner, t)
+}
+
+func TestCallsAndProperties(t *testing.T) {
+	expected := []token.Token{
+		{TokenType: token.IDENTIFIER, Lexeme: "math"},
+		token.CreateToken(token.DOT),
+		{TokenType: token.IDENTIFIER, Lexeme: "max"},
+		token.CreateToken(token.LPA),
+		token.CreateLiteralToken(token.FLOAT, 0.5, ".5"),
+		token.CreateToken(token.COMMA),
+		{TokenType: token.IDENTIFIER, Lexeme: "math"},
+		token.CreateToken(token.DOT),
+		{TokenType: token.IDENTIFIER, Lexeme: "pi"},
+		token.CreateToken(token.RPA),
+		token.CreateToken(token.EOF),
+	}
+
+	scanner := New("math.max(.5, math . pi)")
+	runTest(expected, scanner, t)
+}
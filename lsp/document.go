@@ -0,0 +1,226 @@
+package lsp
+
+import (
+	"fmt"
+	"nilan/compiler"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/token"
+	"unicode/utf16"
+)
+
+// diagnosticSource is the source of the diagnostics published by the server.
+const diagnosticSource = "nilan"
+
+// document is a text document opened in the client, together with the result of analysing it.
+type document struct {
+	uri string
+	// The document's text. Token columns are offsets into this array.
+	text []rune
+	// The offset of the first character of every line.
+	lineStarts []int
+
+	diagnostics []Diagnostic
+	symbols     compiler.SymbolTable
+}
+
+// newDocument creates a document and analyses its text.
+func newDocument(uri string, text string) *document {
+	doc := &document{uri: uri, text: []rune(text), lineStarts: []int{0}}
+	for i, char := range doc.text {
+		if char == '\n' {
+			doc.lineStarts = append(doc.lineStarts, i+1)
+		}
+	}
+	doc.analyze()
+	return doc
+}
+
+// analyze runs the lexer, parser and ASTCompiler on the document's text, collecting the
+// diagnostics they report and the symbol table built by the ASTCompiler's scope resolution.
+func (doc *document) analyze() {
+	doc.diagnostics = []Diagnostic{}
+
+	tokens, lexErrs := lexer.New(string(doc.text)).Scan()
+	for _, lexErr := range lexErrs {
+		if err, ok := lexErr.(lexer.LexError); ok {
+			doc.addDiagnostic(doc.spanRange(err.Start, err.End), err.Error())
+		} else {
+			doc.addDiagnostic(Range{}, lexErr.Error())
+		}
+	}
+	if len(lexErrs) > 0 {
+		return
+	}
+
+	statements, parseErrs := parser.Make(tokens).Parse()
+	for _, parseErr := range parseErrs {
+		if syntaxErr, ok := parseErr.(parser.SyntaxError); ok {
+			doc.addDiagnostic(doc.spanRange(syntaxErr.Start, syntaxErr.End), syntaxErr.Message)
+		} else {
+			doc.addDiagnostic(Range{}, parseErr.Error())
+		}
+	}
+
+	// NOTE: The statements which were parsed successfully are still compiled to resolve their symbols,
+	// but semantic errors are only reported for programs without syntax errors, as missing
+	// statements could cause misleading errors.
+	astCompiler := compiler.NewASTCompiler()
+	_, compileErrs := astCompiler.CompileAST(statements)
+	doc.symbols = astCompiler.Symbols()
+	if len(parseErrs) > 0 {
+		return
+	}
+	for _, compileErr := range compileErrs {
+		if semanticErr, ok := compileErr.(compiler.SemanticError); ok {
+			errRange := Range{}
+			if semanticErr.Token.TokenType != "" {
+				errRange = doc.tokenRange(semanticErr.Token)
+			}
+			doc.addDiagnostic(errRange, semanticErr.Message)
+			continue
+		}
+		doc.addDiagnostic(Range{}, compileErr.Error())
+	}
+}
+
+func (doc *document) addDiagnostic(errRange Range, message string) {
+	doc.diagnostics = append(doc.diagnostics, Diagnostic{
+		Range:    errRange,
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  message,
+	})
+}
+
+// definition returns the declaration of the variable at the offset. The offset can be either
+// on a use of the variable or on its declaration.
+func (doc *document) definition(offset int) (token.Token, bool) {
+	for _, reference := range doc.symbols.References {
+		if doc.tokenContains(reference.Name, offset) {
+			return reference.Declaration, true
+		}
+	}
+	for _, symbol := range doc.symbols.Symbols {
+		if doc.tokenContains(symbol.Name, offset) {
+			return symbol.Name, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// symbol returns the symbol declared by the declaration token.
+func (doc *document) symbol(declaration token.Token) (compiler.Symbol, bool) {
+	for _, symbol := range doc.symbols.Symbols {
+		if symbol.Name == declaration {
+			return symbol, true
+		}
+	}
+	return compiler.Symbol{}, false
+}
+
+// hover describes the variable at the offset.
+func (doc *document) hover(offset int) (*Hover, bool) {
+	var hovered token.Token
+	found := false
+	for _, reference := range doc.symbols.References {
+		if doc.tokenContains(reference.Name, offset) {
+			hovered, found = reference.Name, true
+			break
+		}
+	}
+	declaration, ok := doc.definition(offset)
+	if !ok {
+		return nil, false
+	}
+	if !found {
+		hovered = declaration
+	}
+
+	scope := "Local"
+	if symbol, ok := doc.symbol(declaration); ok && symbol.Global {
+		scope = "Global"
+	}
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: fmt.Sprintf("```nilan\nvar %s\n```\n%s variable declared on line %d", declaration.Lexeme, scope, declaration.Start.Line),
+		},
+		Range: doc.tokenRange(hovered),
+	}, true
+}
+
+// documentSymbols returns every variable declared in the document.
+func (doc *document) documentSymbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, symbol := range doc.symbols.Symbols {
+		detail := "local"
+		if symbol.Global {
+			detail = "global"
+		}
+		nameRange := doc.tokenRange(symbol.Name)
+		symbols = append(symbols, DocumentSymbol{
+			Name:           symbol.Name.Lexeme,
+			Detail:         detail,
+			Kind:           SymbolKindVariable,
+			Range:          nameRange,
+			SelectionRange: nameRange,
+		})
+	}
+	return symbols
+}
+
+// tokenSpan returns the offsets of the first character of the token and of the character after it.
+func (doc *document) tokenSpan(tok token.Token) (int, int) {
+	return doc.sourceOffset(tok.Start), doc.sourceOffset(tok.End)
+}
+
+func (doc *document) tokenContains(tok token.Token, offset int) bool {
+	start, end := doc.tokenSpan(tok)
+	return start <= offset && offset <= end
+}
+
+func (doc *document) tokenRange(tok token.Token) Range {
+	start, end := doc.tokenSpan(tok)
+	return Range{Start: doc.position(start), End: doc.position(end)}
+}
+
+// spanRange converts a span of the source code, e.g the span of a lexing error, to an LSP range.
+func (doc *document) spanRange(start token.Position, end token.Position) Range {
+	return Range{Start: doc.position(doc.sourceOffset(start)), End: doc.position(doc.sourceOffset(end))}
+}
+
+// sourceOffset converts a position in the source code to an offset into the document's text.
+func (doc *document) sourceOffset(position token.Position) int {
+	line := min(max(position.Line-1, 0), len(doc.lineStarts)-1)
+	return doc.lineStarts[line] + max(position.Column-1, 0)
+}
+
+// position converts an offset into the document's text to an LSP position.
+func (doc *document) position(offset int) Position {
+	offset = min(max(offset, 0), len(doc.text))
+	line := 0
+	for line+1 < len(doc.lineStarts) && doc.lineStarts[line+1] <= offset {
+		line++
+	}
+	lineStart := doc.lineStarts[line]
+	return Position{Line: line, Character: len(utf16.Encode(doc.text[lineStart:offset]))}
+}
+
+// offset converts an LSP position to an offset into the document's text.
+func (doc *document) offset(position Position) int {
+	if position.Line < 0 {
+		return 0
+	}
+	if position.Line >= len(doc.lineStarts) {
+		return len(doc.text)
+	}
+	offset := doc.lineStarts[position.Line]
+	for units := 0; offset < len(doc.text) && doc.text[offset] != '\n'; offset++ {
+		units += len(utf16.Encode(doc.text[offset : offset+1]))
+		if units > position.Character {
+			break
+		}
+	}
+	return offset
+}
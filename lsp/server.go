@@ -0,0 +1,211 @@
+// Package lsp implements a language server for Nilan, which speaks the Language Server Protocol
+// over stdio. It reuses the lexer, the parser and the ASTCompiler's scope resolution to provide
+// diagnostics, go-to-definition, hover and document symbols to editors.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Server is a Nilan language server which reads JSON-RPC messages from a reader, usually
+// standard input, and writes its responses and notifications to a writer, usually standard output.
+type Server struct {
+	reader *bufio.Reader
+	writer io.Writer
+	// documents maps the URI of every document opened by the client to its analysis.
+	documents map[string]*document
+	// shutdown is set once the client sends the `shutdown` request.
+	shutdown bool
+}
+
+// NewServer creates a language server communicating over the reader and writer.
+func NewServer(reader io.Reader, writer io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(reader),
+		writer:    writer,
+		documents: make(map[string]*document),
+	}
+}
+
+// Serve handles messages until the client sends the `exit` notification or closes the stream.
+// It returns an error if the stream is closed, or the client exits, without a `shutdown` request
+// or if a message can not be read.
+func (s *Server) Serve() error {
+	for {
+		content, err := readMessage(s.reader)
+		if err == io.EOF {
+			if s.shutdown {
+				return nil
+			}
+			return errors.New("the client closed the connection without a shutdown request")
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return nil
+			}
+			return errors.New("the client exited without a shutdown request")
+		}
+
+		result, respErr := s.handle(msg)
+		// NOTE: Notifications do not have an ID and must not be answered.
+		if msg.ID == nil {
+			continue
+		}
+		if err := s.reply(msg.ID, result, respErr); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification to its handler and returns the result of the request.
+func (s *Server) handle(msg message) (any, *responseError) {
+	if s.shutdown && msg.ID != nil {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "the server is shutting down"}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       TextDocumentSyncKindFull,
+				DefinitionProvider:     true,
+				HoverProvider:          true,
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "nilan"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.openDocument(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if len(params.ContentChanges) > 0 {
+			// NOTE: With full document synchronisation the last change holds the whole document.
+			s.openDocument(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		// Clears the diagnostics shown for the closed document.
+		s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{})
+		return nil, nil
+
+	case "textDocument/definition":
+		doc, offset, respErr := s.documentPosition(msg.Params)
+		if respErr != nil {
+			return nil, respErr
+		}
+		declaration, ok := doc.definition(offset)
+		if !ok {
+			return nil, nil
+		}
+		return Location{URI: doc.uri, Range: doc.tokenRange(declaration)}, nil
+	case "textDocument/hover":
+		doc, offset, respErr := s.documentPosition(msg.Params)
+		if respErr != nil {
+			return nil, respErr
+		}
+		hover, ok := doc.hover(offset)
+		if !ok {
+			return nil, nil
+		}
+		return hover, nil
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		doc, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			return nil, unknownDocument(params.TextDocument.URI)
+		}
+		return doc.documentSymbols(), nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' is not supported", msg.Method)}
+}
+
+// openDocument analyses the document's text and publishes its diagnostics.
+func (s *Server) openDocument(uri string, text string) {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+	s.publishDiagnostics(uri, doc.diagnostics)
+}
+
+// documentPosition decodes TextDocumentPositionParams, returning the referenced document and
+// the offset of the position in its text.
+func (s *Server) documentPosition(rawParams json.RawMessage) (*document, int, *responseError) {
+	var params TextDocumentPositionParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, 0, invalidParams(err)
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, 0, unknownDocument(params.TextDocument.URI)
+	}
+	return doc, doc.offset(params.Position), nil
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) {
+	params, _ := json.Marshal(PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	// NOTE: Write errors are detected when the next response is written.
+	writeMessage(s.writer, message{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// reply writes the response to the request with the ID.
+func (s *Server) reply(id *json.RawMessage, result any, respErr *responseError) error {
+	if id == nil {
+		// NOTE: Errors for messages which could not be decoded are sent with a null ID.
+		null := json.RawMessage("null")
+		id = &null
+	}
+	if respErr != nil {
+		return writeMessage(s.writer, message{JSONRPC: "2.0", ID: id, Error: respErr})
+	}
+	// NOTE: A successful response must contain a result, which is null for requests without one.
+	return writeMessage(s.writer, struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Result  any              `json:"result"`
+	}{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+func unknownDocument(uri string) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("document '%s' is not open", uri)}
+}
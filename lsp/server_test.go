@@ -0,0 +1,412 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// client is a JSON-RPC client talking to a Server running in a goroutine.
+type client struct {
+	t      *testing.T
+	writer io.WriteCloser
+	reader *bufio.Reader
+	nextID int
+	// done receives the error returned by Serve.
+	done chan error
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, writer: clientOut, reader: bufio.NewReader(clientIn), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(serverIn, serverOut).Serve()
+		serverOut.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	return c
+}
+
+func (c *client) send(msg map[string]any) {
+	c.t.Helper()
+	msg["jsonrpc"] = "2.0"
+	if err := writeMessage(c.writer, msg); err != nil {
+		c.t.Fatalf("writing message: %v", err)
+	}
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// receive reads the next message sent by the server.
+func (c *client) receive() map[string]json.RawMessage {
+	c.t.Helper()
+	content, err := readMessage(c.reader)
+	if err != nil {
+		c.t.Fatalf("reading message: %v", err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(content, &msg); err != nil {
+		c.t.Fatalf("decoding message %s: %v", content, err)
+	}
+	return msg
+}
+
+// request sends a request and decodes the result of its response into `result`.
+func (c *client) request(method string, params any, result any) {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"id": c.nextID, "method": method, "params": params})
+	msg := c.receive()
+	if string(msg["id"]) != strings.TrimSpace(string(mustMarshal(c.t, c.nextID))) {
+		c.t.Fatalf("got response with id %s, want %d", msg["id"], c.nextID)
+	}
+	if errMsg, ok := msg["error"]; ok {
+		c.t.Fatalf("%s request failed: %s", method, errMsg)
+	}
+	if err := json.Unmarshal(msg["result"], result); err != nil {
+		c.t.Fatalf("decoding %s result %s: %v", method, msg["result"], err)
+	}
+}
+
+// diagnostics reads the next message, which must publish diagnostics.
+func (c *client) diagnostics() PublishDiagnosticsParams {
+	c.t.Helper()
+	msg := c.receive()
+	var method string
+	json.Unmarshal(msg["method"], &method)
+	if method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("got message %v, want textDocument/publishDiagnostics", msg)
+	}
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(msg["params"], &params); err != nil {
+		c.t.Fatalf("decoding diagnostics: %v", err)
+	}
+	return params
+}
+
+func (c *client) open(uri string, text string) PublishDiagnosticsParams {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "nilan", "version": 1, "text": text},
+	})
+	return c.diagnostics()
+}
+
+func mustMarshal(t *testing.T, value any) []byte {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func positionParams(uri string, line int, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+const testURI = "file:///test.ni"
+
+func TestServerLifecycle(t *testing.T) {
+	c := newClient(t)
+
+	var result InitializeResult
+	c.request("initialize", map[string]any{"capabilities": map[string]any{}}, &result)
+	capabilities := result.Capabilities
+	if capabilities.TextDocumentSync != TextDocumentSyncKindFull || !capabilities.DefinitionProvider ||
+		!capabilities.HoverProvider || !capabilities.DocumentSymbolProvider {
+		t.Errorf("got capabilities %+v", capabilities)
+	}
+	c.notify("initialized", map[string]any{})
+
+	var unknown any
+	c.nextID++
+	c.send(map[string]any{"id": c.nextID, "method": "textDocument/formatting", "params": map[string]any{}})
+	if msg := c.receive(); !strings.Contains(string(msg["error"]), "-32601") {
+		t.Errorf("got response %v, want a method not found error", msg)
+	}
+
+	c.request("shutdown", nil, &unknown)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("Serve returned an error: %v", err)
+	}
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.notify("exit", nil)
+	if err := <-c.done; err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		message string
+		want    Range
+	}{
+		{
+			name:    "valid program",
+			text:    "var a = 1\nprint a",
+			message: "",
+		},
+		{
+			name:    "lexer error",
+			text:    "var a = 1\nprint a $ a",
+			message: "unexpected character",
+			want:    Range{Start: Position{Line: 1, Character: 8}, End: Position{Line: 1, Character: 9}},
+		},
+		{
+			name:    "unterminated string",
+			text:    "var a = 1\nprint \"abc",
+			message: "unterminated string literal",
+			want:    Range{Start: Position{Line: 1, Character: 6}, End: Position{Line: 1, Character: 10}},
+		},
+		{
+			name:    "syntax error",
+			text:    "var a = 1\nprint a +\nvar b = 2",
+			message: "Unrecognised expression",
+			want:    Range{Start: Position{Line: 2, Character: 0}, End: Position{Line: 2, Character: 3}},
+		},
+		{
+			name:    "syntax error at a string",
+			text:    "print (1 \"héllo\"",
+			message: "expression is missing ')'",
+			want:    Range{Start: Position{Line: 0, Character: 9}, End: Position{Line: 0, Character: 16}},
+		},
+		{
+			name:    "semantic error",
+			text:    "var a = 1\n{\n  print a + missing\n}",
+			message: "name 'missing' is not defined",
+			want:    Range{Start: Position{Line: 2, Character: 12}, End: Position{Line: 2, Character: 19}},
+		},
+		{
+			name:    "redefinition",
+			text:    "{ var a = 1\n  var a = 2 }",
+			message: "Redefinition of variable 'a'",
+			want:    Range{Start: Position{Line: 1, Character: 6}, End: Position{Line: 1, Character: 7}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t)
+			published := c.open(testURI, tt.text)
+			if published.URI != testURI {
+				t.Errorf("got diagnostics for %s, want %s", published.URI, testURI)
+			}
+			if tt.message == "" {
+				if len(published.Diagnostics) != 0 {
+					t.Errorf("got diagnostics %+v, want none", published.Diagnostics)
+				}
+				return
+			}
+			if len(published.Diagnostics) == 0 {
+				t.Fatal("got no diagnostics")
+			}
+			diagnostic := published.Diagnostics[0]
+			if !strings.Contains(diagnostic.Message, tt.message) {
+				t.Errorf("got message %q, want it to contain %q", diagnostic.Message, tt.message)
+			}
+			if diagnostic.Range != tt.want {
+				t.Errorf("got range %+v, want %+v", diagnostic.Range, tt.want)
+			}
+			if diagnostic.Severity != SeverityError || diagnostic.Source != "nilan" {
+				t.Errorf("got severity %d and source %q", diagnostic.Severity, diagnostic.Source)
+			}
+		})
+	}
+}
+
+func TestServerReportsAllLexErrors(t *testing.T) {
+	c := newClient(t)
+	published := c.open(testURI, "var a = 1.2.3\nprint $ + 1.")
+	want := []Range{
+		{Start: Position{Line: 0, Character: 8}, End: Position{Line: 0, Character: 13}},
+		{Start: Position{Line: 1, Character: 6}, End: Position{Line: 1, Character: 7}},
+		{Start: Position{Line: 1, Character: 10}, End: Position{Line: 1, Character: 12}},
+	}
+	if len(published.Diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics: %+v, want: %d", len(published.Diagnostics), published.Diagnostics, len(want))
+	}
+	for i, w := range want {
+		if published.Diagnostics[i].Range != w {
+			t.Errorf("got range %+v for %q, want %+v", published.Diagnostics[i].Range, published.Diagnostics[i].Message, w)
+		}
+	}
+}
+
+func TestServerReportsAllSemanticErrors(t *testing.T) {
+	c := newClient(t)
+	published := c.open(testURI, "print a\nprint b\nvar c = 1\nvar c = 2")
+	want := []struct {
+		message string
+		line    int
+	}{
+		{message: "name 'a' is not defined", line: 0},
+		{message: "name 'b' is not defined", line: 1},
+		{message: "Redefinition of variable 'c'", line: 3},
+	}
+	if len(published.Diagnostics) != len(want) {
+		t.Fatalf("got diagnostics %+v, want %d", published.Diagnostics, len(want))
+	}
+	for i, w := range want {
+		diagnostic := published.Diagnostics[i]
+		if diagnostic.Message != w.message || diagnostic.Range.Start.Line != w.line {
+			t.Errorf("got diagnostic %+v, want %q on line %d", diagnostic, w.message, w.line)
+		}
+	}
+}
+
+func TestServerDidChangeAndClose(t *testing.T) {
+	c := newClient(t)
+	if published := c.open(testURI, "print missing"); len(published.Diagnostics) != 1 {
+		t.Fatalf("got diagnostics %+v, want 1", published.Diagnostics)
+	}
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": testURI, "version": 2},
+		"contentChanges": []any{map[string]any{"text": "var missing = 1\nprint missing"}},
+	})
+	if published := c.diagnostics(); len(published.Diagnostics) != 0 {
+		t.Errorf("got diagnostics %+v after fixing the error, want none", published.Diagnostics)
+	}
+
+	c.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": testURI}})
+	if published := c.diagnostics(); len(published.Diagnostics) != 0 {
+		t.Errorf("got diagnostics %+v after closing the document, want none", published.Diagnostics)
+	}
+}
+
+const symbolsProgram = `var total = 0
+{
+  var total = 10
+  total = total + 1
+}
+try { throw total } catch (err) { print err }
+print total`
+
+func TestServerDefinition(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      int
+		character int
+		want      *Range
+	}{
+		{
+			name: "local shadowing a global", line: 3, character: 11,
+			want: &Range{Start: Position{Line: 2, Character: 6}, End: Position{Line: 2, Character: 11}},
+		},
+		{
+			name: "assignment target", line: 3, character: 2,
+			want: &Range{Start: Position{Line: 2, Character: 6}, End: Position{Line: 2, Character: 11}},
+		},
+		{
+			name: "global", line: 6, character: 8,
+			want: &Range{Start: Position{Line: 0, Character: 4}, End: Position{Line: 0, Character: 9}},
+		},
+		{
+			name: "catch variable", line: 5, character: 41,
+			want: &Range{Start: Position{Line: 5, Character: 27}, End: Position{Line: 5, Character: 30}},
+		},
+		{
+			name: "declaration", line: 0, character: 5,
+			want: &Range{Start: Position{Line: 0, Character: 4}, End: Position{Line: 0, Character: 9}},
+		},
+		{
+			name: "not a variable", line: 6, character: 2,
+		},
+	}
+
+	c := newClient(t)
+	if published := c.open(testURI, symbolsProgram); len(published.Diagnostics) != 0 {
+		t.Fatalf("got diagnostics %+v", published.Diagnostics)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var location *Location
+			c.request("textDocument/definition", positionParams(testURI, tt.line, tt.character), &location)
+			if tt.want == nil {
+				if location != nil {
+					t.Errorf("got location %+v, want null", location)
+				}
+				return
+			}
+			if location == nil {
+				t.Fatal("got null location")
+			}
+			if location.URI != testURI || location.Range != *tt.want {
+				t.Errorf("got location %+v, want range %+v", location, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerHover(t *testing.T) {
+	c := newClient(t)
+	c.open(testURI, symbolsProgram)
+
+	var hover *Hover
+	c.request("textDocument/hover", positionParams(testURI, 6, 8), &hover)
+	if hover == nil {
+		t.Fatal("got null hover")
+	}
+	want := "```nilan\nvar total\n```\nGlobal variable declared on line 1"
+	if hover.Contents.Value != want || hover.Contents.Kind != "markdown" {
+		t.Errorf("got hover contents %+v, want %q", hover.Contents, want)
+	}
+	if wantRange := (Range{Start: Position{Line: 6, Character: 6}, End: Position{Line: 6, Character: 11}}); hover.Range != wantRange {
+		t.Errorf("got hover range %+v, want %+v", hover.Range, wantRange)
+	}
+
+	c.request("textDocument/hover", positionParams(testURI, 3, 10), &hover)
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Local variable declared on line 3") {
+		t.Errorf("got hover %+v, want a local variable", hover)
+	}
+
+	hover = nil
+	c.request("textDocument/hover", positionParams(testURI, 1, 0), &hover)
+	if hover != nil {
+		t.Errorf("got hover %+v, want null", hover)
+	}
+}
+
+func TestServerDocumentSymbols(t *testing.T) {
+	c := newClient(t)
+	c.open(testURI, symbolsProgram)
+
+	var symbols []DocumentSymbol
+	c.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": testURI}}, &symbols)
+	want := []struct {
+		name   string
+		detail string
+		line   int
+	}{
+		{name: "total", detail: "global", line: 0},
+		{name: "total", detail: "local", line: 2},
+		{name: "err", detail: "local", line: 5},
+	}
+	if len(symbols) != len(want) {
+		t.Fatalf("got symbols %+v, want %d", symbols, len(want))
+	}
+	for i, symbol := range symbols {
+		if symbol.Name != want[i].name || symbol.Detail != want[i].detail ||
+			symbol.Kind != SymbolKindVariable || symbol.SelectionRange.Start.Line != want[i].line {
+			t.Errorf("symbol %d - got %+v, want %+v", i, symbol, want[i])
+		}
+	}
+}
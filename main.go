@@ -14,6 +14,11 @@ func main() {
 	subcommands.Register(&emitBytecodeCmd{}, "compiler")
 	subcommands.Register(&replCompiledCmd{}, "compiler")
 	subcommands.Register(&runCompiledCmd{}, "compiler")
+	subcommands.Register(&cacheCmd{}, "compiler")
+	subcommands.Register(&benchCmd{}, "tooling")
+	subcommands.Register(&lspCmd{}, "tooling")
+	subcommands.Register(&fmtCmd{}, "tooling")
+	subcommands.Register(&lintCmd{}, "tooling")
 	flag.Parse()
 	ctx := context.Background()
 	os.Exit(int(subcommands.Execute(ctx)))
@@ -0,0 +1,195 @@
+package modules
+
+// This file implements the cache of compiled programs, which stores the bytecode of each program
+// and module on disk so it is only compiled again when its source or the compiler changes.
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"nilan/compiler"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// CacheDirEnv is the environment variable setting the directory compiled programs are cached in.
+const CacheDirEnv = "NILAN_CACHE_DIR"
+
+// cacheExt is the extension of the files of the cache, which contain bytecode encoded by
+// `Bytecode.MarshalBinary`.
+const cacheExt = ".nbc"
+
+// Cache stores compiled bytecode in a directory, in a file named after the hash of the source
+// code it was compiled from and of the build of Nilan which compiled it, so an entry is never
+// stale: a changed source or a different build of the compiler has a different key.
+//
+// It is safe to use by concurrent processes: entries are written to a temporary file which is
+// renamed once complete, so an entry is either missing or complete.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache storing its entries in `dir`, which is created when the first entry is stored.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultCacheDir returns the directory set by the NILAN_CACHE_DIR environment variable, or the
+// `nilan` directory of the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nilan"), nil
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Key returns the key of the bytecode compiled from `source` by this build of the compiler.
+func (c *Cache) Key(source string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "nilan build %s, bytecode format %d\n", buildID(), compiler.BytecodeFormatVersion)
+	hash.Write([]byte(source))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// buildID identifies the build of the running program, so bytecode cached by another build of
+// the compiler is never reused, whether or not the change affects the generated code.
+// It is the version control revision the program was built from, or the version of its module
+// when it was installed with `go install`. A build with uncommitted changes, or without either,
+// is identified by the hash of its executable.
+var buildID = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if ok {
+		var revision string
+		modified := false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if revision != "" && !modified {
+			return "revision " + revision
+		}
+		if info.Main.Sum != "" {
+			return "module " + info.Main.Version + " " + info.Main.Sum
+		}
+	}
+	if id, err := executableHash(); err == nil {
+		return "executable " + id
+	}
+	// NOTE: Without a way to identify the build, a random ID makes every run miss the cache
+	// instead of reusing bytecode compiled by another build.
+	random := make([]byte, 16)
+	rand.Read(random)
+	return "unknown " + hex.EncodeToString(random)
+})
+
+// executableHash returns the hash of the executable of the running program.
+func executableHash() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Load returns the bytecode compiled from `source`, and false if it is not cached.
+// Entries which can not be decoded or are malformed, e.g a file corrupted on disk, are ignored.
+func (c *Cache) Load(source string) (compiler.Bytecode, bool) {
+	data, err := os.ReadFile(c.path(source))
+	if err != nil {
+		return compiler.Bytecode{}, false
+	}
+	var bytecode compiler.Bytecode
+	if err := bytecode.UnmarshalBinary(data); err != nil {
+		return compiler.Bytecode{}, false
+	}
+	if err := compiler.Verify(bytecode); err != nil {
+		return compiler.Bytecode{}, false
+	}
+	return bytecode, true
+}
+
+// Store caches the bytecode compiled from `source`, replacing the entry if there is one.
+func (c *Cache) Store(source string, bytecode compiler.Bytecode) error {
+	data, err := bytecode.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	// NOTE: Renaming a file is atomic, so processes loading the entry concurrently never read a
+	// partially written file. Processes storing the same entry write the same bytecode.
+	file, err := os.CreateTemp(c.dir, "tmp-*"+cacheExt)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), c.path(source))
+}
+
+// Clean removes every entry of the cache and returns how many were removed.
+// Removing the entries used by a running program is safe, they are read once when it starts.
+func (c *Cache) Clean() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		// NOTE: Only the files written by the cache are removed, in case the directory is shared.
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheExt) {
+			continue
+		}
+		err := os.Remove(filepath.Join(c.dir, entry.Name()))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		if err == nil && !strings.HasPrefix(entry.Name(), "tmp-") {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// path returns the path of the file of the entry of `source`.
+func (c *Cache) path(source string) string {
+	return filepath.Join(c.dir, c.Key(source)+cacheExt)
+}
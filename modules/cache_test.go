@@ -0,0 +1,162 @@
+package modules
+
+import (
+	"nilan/compiler"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// compileSource compiles a program which is expected to be valid.
+func compileSource(t *testing.T, source string) compiler.Bytecode {
+	t.Helper()
+	path := writeFile(t, t.TempDir(), "program.ni", source)
+	bytecode, err := NewLoader(nil).compile("program.ni", path)
+	if err != nil {
+		t.Fatalf("compilation error: %v", err)
+	}
+	return bytecode
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache(filepath.Join(t.TempDir(), "cache"))
+	source := "var a = 1\nprint a + 2"
+	if _, ok := cache.Load(source); ok {
+		t.Fatalf("got a cached program from an empty cache")
+	}
+	bytecode := compileSource(t, source)
+	if err := cache.Store(source, bytecode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cached, ok := cache.Load(source)
+	if !ok {
+		t.Fatalf("the stored program is not cached")
+	}
+	if string(cached.Instructions) != string(bytecode.Instructions) || len(cached.ConstantsPool) != len(bytecode.ConstantsPool) {
+		t.Errorf("got cached bytecode: %v, want: %v", cached, bytecode)
+	}
+	if _, ok := cache.Load(source + "\n"); ok {
+		t.Errorf("got a cached program for a changed source")
+	}
+
+	// NOTE: Entries which can not be decoded are ignored, and replaced by the next store.
+	if err := os.WriteFile(cache.path(source), []byte("NILC garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Load(source); ok {
+		t.Errorf("got a cached program from a corrupted entry")
+	}
+	if err := cache.Store(source, bytecode); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Load(source); !ok {
+		t.Errorf("the corrupted entry was not replaced")
+	}
+}
+
+func TestCacheConcurrentStores(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	source := "print 1"
+	bytecode := compileSource(t, source)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cache.Store(source, bytecode); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if _, ok := cache.Load(source); !ok {
+				t.Errorf("the stored program is not cached")
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := os.ReadDir(cache.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files in the cache, want 1", len(entries))
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	if cache.Key("print 1") != cache.Key("print 1") {
+		t.Errorf("got different keys for the same source")
+	}
+	if cache.Key("print 1") == cache.Key("print 2") {
+		t.Errorf("got the same key for different sources")
+	}
+	// NOTE: The test binary has no version control information, so it is identified by its executable.
+	if id := buildID(); strings.HasPrefix(id, "unknown") {
+		t.Errorf("got build ID %q, want the build to be identified", id)
+	}
+}
+
+func TestCacheClean(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	for _, source := range []string{"print 1", "print 2"} {
+		if err := cache.Store(source, compileSource(t, source)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	other := filepath.Join(cache.Dir(), "notes.txt")
+	if err := os.WriteFile(other, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := cache.Clean()
+	if err != nil || removed != 2 {
+		t.Errorf("got %d removed entries, error: %v, want 2 removed entries", removed, err)
+	}
+	if _, ok := cache.Load("print 1"); ok {
+		t.Errorf("got a cached program after cleaning the cache")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("a file which is not an entry was removed: %v", err)
+	}
+
+	removed, err = NewCache(filepath.Join(cache.Dir(), "missing")).Clean()
+	if err != nil || removed != 0 {
+		t.Errorf("got %d removed entries, error: %v, want none for a missing directory", removed, err)
+	}
+}
+
+func TestLoaderCache(t *testing.T) {
+	dir := t.TempDir()
+	main := writeFile(t, dir, "main.ni", "")
+	writeFile(t, dir, "lib.ni", "var x = 1")
+	cache := NewCache(filepath.Join(dir, "cache"))
+
+	loader := NewLoader(nil)
+	loader.SetCache(cache)
+	_, bytecode, err := loader.Import("lib.ni", main)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Load("var x = 1"); !ok {
+		t.Fatalf("the compiled module is not cached")
+	}
+
+	// NOTE: The cached entry is loaded instead of compiling the module.
+	other := compileSource(t, "var y = 2\nvar z = 3")
+	if err := cache.Store("var x = 1", other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loader = NewLoader(nil)
+	loader.SetCache(cache)
+	_, cached, err := loader.Import("lib.ni", main)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(cached.Instructions) == string(bytecode.Instructions) {
+		t.Errorf("got the module compiled again, want the cached bytecode")
+	}
+}
@@ -0,0 +1,154 @@
+// Package modules finds, compiles and caches the modules imported by Nilan programs with
+// `import "path/to/module.ni" as name` statements.
+//
+// A relative path is resolved from the directory of the importing file, or the working directory
+// for programs which are not files, and then from each directory of the search path in order.
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"nilan/compiler"
+	"nilan/diagnostics"
+	"nilan/lexer"
+	"nilan/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchPathEnv is the environment variable listing the directories modules are searched in,
+// separated like the directories of the PATH environment variable.
+const SearchPathEnv = "NILAN_PATH"
+
+// Loader resolves the paths of imported modules and compiles each module once, it implements
+// vm.Importer.
+type Loader struct {
+	searchPath []string
+	// compiled caches the bytecode of the compiled modules by their canonical path.
+	compiled map[string]compiler.Bytecode
+	// cache stores the bytecode of the compiled modules on disk, it is nil if they are not stored.
+	cache *Cache
+}
+
+// NewLoader creates a loader which searches modules in the directories of `searchPath`,
+// after the directory of the importing file.
+func NewLoader(searchPath []string) *Loader {
+	return &Loader{searchPath: searchPath, compiled: map[string]compiler.Bytecode{}}
+}
+
+// SetCache sets the cache the bytecode of the compiled modules is loaded from and stored in,
+// nil to compile every module.
+func (l *Loader) SetCache(cache *Cache) {
+	l.cache = cache
+}
+
+// SearchPath returns the directories of the value of a search path flag followed by the
+// directories of the NILAN_PATH environment variable. Empty entries are ignored.
+func SearchPath(flagValue string) []string {
+	var dirs []string
+	for _, list := range []string{flagValue, os.Getenv(SearchPathEnv)} {
+		for _, dir := range filepath.SplitList(list) {
+			if dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// Canonical returns the canonical path of a file, which is absolute and has no symbolic links,
+// so a file imported with different paths is recognised as the same module.
+func Canonical(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// Resolve returns the canonical path of the module imported as `path` by the module whose
+// canonical path is `from`, "" for a program which is not a file.
+func (l *Loader) Resolve(path string, from string) (string, error) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		dir := "."
+		if from != "" {
+			dir = filepath.Dir(from)
+		}
+		candidates = []string{filepath.Join(dir, path)}
+		for _, searched := range l.searchPath {
+			candidates = append(candidates, filepath.Join(searched, path))
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return Canonical(candidate)
+		}
+	}
+	return "", fmt.Errorf("module '%s' not found, searched: %s", path, strings.Join(candidates, ", "))
+}
+
+// Import returns the canonical path and the bytecode of the module imported as `path` by the
+// module whose canonical path is `from`. The module is compiled the first time it is imported.
+func (l *Loader) Import(path string, from string) (string, compiler.Bytecode, error) {
+	canonical, err := l.Resolve(path, from)
+	if err != nil {
+		return "", compiler.Bytecode{}, err
+	}
+	if bytecode, ok := l.compiled[canonical]; ok {
+		return canonical, bytecode, nil
+	}
+	bytecode, err := l.compile(path, canonical)
+	if err != nil {
+		return "", compiler.Bytecode{}, err
+	}
+	l.compiled[canonical] = bytecode
+	return canonical, bytecode, nil
+}
+
+// compile compiles the module at `canonical`, which was imported as `path`, or loads its
+// bytecode from the cache.
+func (l *Loader) compile(path string, canonical string) (compiler.Bytecode, error) {
+	data, err := os.ReadFile(canonical)
+	if err != nil {
+		return compiler.Bytecode{}, fmt.Errorf("can not read module '%s': %v", path, err)
+	}
+	source := string(data)
+	if l.cache != nil {
+		if bytecode, ok := l.cache.Load(source); ok {
+			return bytecode, nil
+		}
+	}
+	tokens, errs := lexer.New(source).Scan()
+	if len(errs) > 0 {
+		return compiler.Bytecode{}, moduleError(path, errs)
+	}
+	statements, errs := parser.Make(tokens).Parse()
+	if len(errs) > 0 {
+		return compiler.Bytecode{}, moduleError(path, errs)
+	}
+	bytecode, errs := compiler.NewASTCompiler().CompileAST(statements)
+	if len(errs) > 0 {
+		return compiler.Bytecode{}, moduleError(path, errs)
+	}
+	if l.cache != nil {
+		// NOTE: The cache only saves time, the module can be executed even if it can not be stored.
+		_ = l.cache.Store(source, bytecode)
+	}
+	return bytecode, nil
+}
+
+// moduleError reports the first error found while compiling the module imported as `path`,
+// and how many other errors were found.
+func moduleError(path string, errs []error) error {
+	diagnostic := diagnostics.FromError(errs[0])
+	message := fmt.Sprintf("module '%s' does not compile: %s, line %d", path, diagnostic.Message, diagnostic.Start.Line)
+	if diagnostic.Start.Column > 0 {
+		message += fmt.Sprintf(", column %d", diagnostic.Start.Column)
+	}
+	if len(errs) > 1 {
+		message += fmt.Sprintf(" (and %d more errors)", len(errs)-1)
+	}
+	return errors.New(message)
+}
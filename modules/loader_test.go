@@ -0,0 +1,103 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes a file in `dir`, creating its parent directories.
+func writeFile(t *testing.T, dir string, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := Canonical(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return canonical
+}
+
+func TestResolve(t *testing.T) {
+	project, library := t.TempDir(), t.TempDir()
+	main := writeFile(t, project, "main.ni", "")
+	local := writeFile(t, project, "util.ni", "")
+	nested := writeFile(t, project, "lib/nested.ni", "")
+	shared := writeFile(t, library, "shared.ni", "")
+	writeFile(t, library, "util.ni", "")
+
+	loader := NewLoader([]string{library})
+	tests := []struct {
+		path string
+		from string
+		want string
+	}{
+		{path: "util.ni", from: main, want: local},
+		{path: "lib/nested.ni", from: main, want: nested},
+		{path: "../util.ni", from: nested, want: local},
+		{path: "shared.ni", from: main, want: shared},
+		{path: "shared.ni", from: nested, want: shared},
+		{path: shared, from: main, want: shared},
+	}
+	for _, tt := range tests {
+		got, err := loader.Resolve(tt.path, tt.from)
+		if err != nil {
+			t.Errorf("unexpected error resolving %s: %v", tt.path, err)
+		} else if got != tt.want {
+			t.Errorf("got path of %s: %s, want: %s", tt.path, got, tt.want)
+		}
+	}
+
+	_, err := loader.Resolve("missing.ni", main)
+	want := "module 'missing.ni' not found, searched: " + filepath.Join(project, "missing.ni") + ", " + filepath.Join(library, "missing.ni")
+	if err == nil || err.Error() != want {
+		t.Errorf("got error: %v, want: %s", err, want)
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	t.Setenv(SearchPathEnv, strings.Join([]string{"/env/a", "", "/env/b"}, string(filepath.ListSeparator)))
+	got := SearchPath("/flag")
+	want := []string{"/flag", "/env/a", "/env/b"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got search path: %v, want: %v", got, want)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	main := writeFile(t, dir, "main.ni", "")
+	lib := writeFile(t, dir, "lib.ni", "var x = 1")
+	writeFile(t, dir, "broken.ni", "var x = 1\nvar = 2\nprint )")
+
+	loader := NewLoader(nil)
+	canonical, bytecode, err := loader.Import("lib.ni", main)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if canonical != lib || len(bytecode.Instructions) == 0 {
+		t.Errorf("got path: %s, bytecode: %v, want the compiled %s", canonical, bytecode, lib)
+	}
+
+	// NOTE: Modules are compiled once, changes to their file are not seen by later imports.
+	writeFile(t, dir, "lib.ni", "var x = 1\nvar y = 2")
+	_, again, err := loader.Import("./lib.ni", main)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(again.Instructions) != len(bytecode.Instructions) {
+		t.Errorf("got the module compiled again: %v, want: %v", again, bytecode)
+	}
+
+	_, _, err = loader.Import("broken.ni", main)
+	want := "module 'broken.ni' does not compile: Expected variable name, line 2, column 5 (and 1 more errors)"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error: %v, want: %s", err, want)
+	}
+}
@@ -1,22 +1,39 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"nilan/diagnostics"
+	"nilan/token"
+)
 
 // Defines the struct for all syntax errors in the Parser
 type SyntaxError struct {
-	Line    int32
-	Column  int
 	Message string
+	// The span of the offending token.
+	Start token.Position
+	End   token.Position
 }
 
-func CreateSyntaxError(line int32, column int, message string) SyntaxError {
+// syntaxErrorAt creates a SyntaxError reported at the token.
+func syntaxErrorAt(tok token.Token, message string) SyntaxError {
 	return SyntaxError{
-		Line:    line,
-		Column:  column,
 		Message: message,
+		Start:   tok.Start,
+		End:     tok.End,
 	}
 }
 
 func (e SyntaxError) Error() string {
-	return fmt.Sprintf("💥 Nilan Syntax error:\nline:%d, column:%d - %s", e.Line, e.Column, e.Message)
+	return fmt.Sprintf("💥 Nilan Syntax error:\nline:%d, column:%d - %s", e.Start.Line, e.Start.Column, e.Message)
+}
+
+// Diagnostic describes the error for rendering.
+func (e SyntaxError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeSyntax,
+		Message:  e.Message,
+		Start:    e.Start,
+		End:      e.End,
+	}
 }
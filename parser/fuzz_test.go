@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"nilan/lexer"
+	"testing"
+)
+
+// FuzzParse checks that the parser never panics on any token stream produced by the lexer.
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"1 + 2 * 3",
+		"var a = 1\nprint a",
+		"if a > 1 { print a } else { print b }",
+		"while count < n { count = count + 1 }",
+		"{ var a = 1 { var b = a } }",
+		"a = b = c",
+		"(1 + 2",
+		"{",
+		"1 = 2",
+		"var",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, source string) {
+		tokens, lexErrs := lexer.New(source).Scan()
+		if len(lexErrs) > 0 {
+			return
+		}
+		Make(tokens).Parse()
+	})
+}
@@ -25,6 +25,19 @@ var equalityTokenTypes = []token.TokenType{
 	token.EQUAL_EQUAL,
 }
 
+var assignmentTokenTypes = []token.TokenType{
+	token.ASSIGN,
+	token.ADD_ASSIGN,
+	token.SUB_ASSIGN,
+	token.MULT_ASSIGN,
+	token.DIV_ASSIGN,
+}
+
+var shiftTokenTypes = []token.TokenType{
+	token.SHIFT_LEFT,
+	token.SHIFT_RIGHT,
+}
+
 var termTokenTypes = []token.TokenType{
 	token.SUB,
 	token.ADD,
@@ -33,11 +46,14 @@ var termTokenTypes = []token.TokenType{
 var factorExpressionTypes = []token.TokenType{
 	token.MULT,
 	token.DIV,
+	token.FLOOR_DIV,
+	token.MOD,
 }
 
 var unaryExpressionTypes = []token.TokenType{
 	token.BANG,
 	token.SUB,
+	token.BIT_NOT,
 
 	// NOTE: not supported operands on unary expressions are included
 	// So they can be parsed, but then the interpreter can throw a more detailed
@@ -86,6 +102,16 @@ func (parser *Parser) PrintToFile(statements []ast.Stmt, path string) error {
 	return WriteASTJSONToFile(statements, path)
 }
 
+// span returns the span from the start of the `first` token to the end of the last consumed token.
+func (parser *Parser) span(first token.Token) ast.Span {
+	return ast.Span{Start: first.Start, End: parser.previous().End}
+}
+
+// spanBetween returns the span from the start of the `first` node to the end of the `last` node.
+func spanBetween(first ast.Expression, last ast.Expression) ast.Span {
+	return ast.Span{Start: first.SourceSpan().Start, End: last.SourceSpan().End}
+}
+
 // Peeks the token at the parser's current position,
 // without advancing the parser's position.
 // Returns:
@@ -199,6 +225,12 @@ func (parser *Parser) declaration() (ast.Stmt, error) {
 	if parser.isMatch([]token.TokenType{token.VAR}) {
 		return parser.variableDeclaration()
 	}
+	if parser.isMatch([]token.TokenType{token.IMPORT}) {
+		return parser.importDeclaration()
+	}
+	if parser.isMatch([]token.TokenType{token.STRUCT}) {
+		return parser.structDeclaration()
+	}
 	// TODO Add support for functions and classes
 	return parser.statement()
 }
@@ -210,6 +242,7 @@ func (parser *Parser) declaration() (ast.Stmt, error) {
 //   - ast.VarStmt: A VarStmt AST node epresenting the variable declaration.
 //   - error: A SyntaxError if parsing fails or if the variable has not been initialised.
 func (parser *Parser) variableDeclaration() (ast.Stmt, error) {
+	keyword := parser.previous()
 	tok, consumeError := parser.consume(token.IDENTIFIER, "Expected variable name")
 	if consumeError != nil {
 		return nil, consumeError
@@ -227,9 +260,63 @@ func (parser *Parser) variableDeclaration() (ast.Stmt, error) {
 	return ast.VarStmt{
 		Name:        tok,
 		Initializer: initialiser,
+		Span:        parser.span(keyword),
 	}, nil
 }
 
+// importDeclaration parses an import statement: `import "path/to/module.ni" as name`.
+// The path must be a string literal without interpolated expressions.
+func (parser *Parser) importDeclaration() (ast.Stmt, error) {
+	keyword := parser.previous()
+	path, err := parser.consume(token.STRING, "Expected the path of the module as a string after 'import'")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.consume(token.AS, "Expected 'as' after the path of the module"); err != nil {
+		return nil, err
+	}
+	name, err := parser.consume(token.IDENTIFIER, "Expected the name of the module after 'as'")
+	if err != nil {
+		return nil, err
+	}
+	return ast.ImportStmt{Keyword: keyword, Path: path, Name: name, Span: parser.span(keyword)}, nil
+}
+
+// structDeclaration parses a struct declaration: `struct Point { x, y }`. The fields are
+// separated by commas and a struct can have no fields.
+func (parser *Parser) structDeclaration() (ast.Stmt, error) {
+	keyword := parser.previous()
+	name, err := parser.consume(token.IDENTIFIER, "Expected the name of the struct after 'struct'")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.consume(token.LCUR, "Expected '{' after the name of the struct"); err != nil {
+		return nil, err
+	}
+	fields := []token.Token{}
+	for !parser.checkType(token.RCUR) {
+		if len(fields) > 0 {
+			if _, err := parser.consume(token.COMMA, "Expected ',' or '}' after a field"); err != nil {
+				return nil, err
+			}
+		}
+		field, err := parser.consume(token.IDENTIFIER, "Expected the name of a field")
+		if err != nil {
+			return nil, err
+		}
+		for _, declared := range fields {
+			if declared.Lexeme == field.Lexeme {
+				return nil, syntaxErrorAt(field, fmt.Sprintf("Duplicate field '%s' in struct '%s'", field.Lexeme, name.Lexeme))
+			}
+		}
+		fields = append(fields, field)
+	}
+	if _, err := parser.consume(token.RCUR, "Expected '}' after the fields of the struct"); err != nil {
+		return nil, err
+	}
+	return ast.StructStmt{Keyword: keyword, Name: name, Fields: fields, Span: parser.span(keyword)}, nil
+}
+
 // statement parses a single statement. Currently, this can be either
 // a print statement ("print <expr>") an expression statement,
 // a block statement or a conditional statement.
@@ -248,11 +335,12 @@ func (parser *Parser) statement() (ast.Stmt, error) {
 	}
 
 	if parser.isMatch([]token.TokenType{token.LCUR}) {
+		brace := parser.previous()
 		statements, err := parser.block()
 		if err != nil {
 			return nil, err
 		}
-		return ast.BlockStmt{Statements: statements}, nil
+		return ast.BlockStmt{Statements: statements, Span: parser.span(brace)}, nil
 	}
 
 	if parser.isMatch([]token.TokenType{token.IF}) {
@@ -262,13 +350,21 @@ func (parser *Parser) statement() (ast.Stmt, error) {
 	if parser.isMatch([]token.TokenType{token.WHILE}) {
 		return parser.WhileStatement()
 	}
+
+	if parser.isMatch([]token.TokenType{token.TRY}) {
+		return parser.tryStatement()
+	}
+
+	if parser.isMatch([]token.TokenType{token.THROW}) {
+		return parser.throwStatement()
+	}
 	// TODO: Add more expression types.
 
 	expression, err := parser.expression()
 	if err != nil {
 		return nil, err
 	}
-	exprStmt := ast.ExpressionStmt{Expression: expression}
+	exprStmt := ast.ExpressionStmt{Expression: expression, Span: expression.SourceSpan()}
 
 	return exprStmt, nil
 }
@@ -279,11 +375,12 @@ func (parser *Parser) statement() (ast.Stmt, error) {
 //   - Stmt: a PrintStmt containing the expression to print.
 //   - error: if the inner expression fails to parse.
 func (parser *Parser) printStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
 	expression, err := parser.expression()
 	if err != nil {
 		return nil, err
 	}
-	return ast.PrintStmt{Expression: expression}, nil
+	return ast.PrintStmt{Expression: expression, Span: parser.span(keyword)}, nil
 }
 
 // WhileStatement parses a while loop statement from the token stream.
@@ -294,6 +391,7 @@ func (parser *Parser) printStatement() (ast.Stmt, error) {
 //   - error: if parsing the condition or body fails.
 func (parser *Parser) WhileStatement() (ast.Stmt, error) {
 
+	keyword := parser.previous()
 	expr, err := parser.expression()
 	if err != nil {
 		return nil, err
@@ -307,8 +405,10 @@ func (parser *Parser) WhileStatement() (ast.Stmt, error) {
 	}
 
 	return ast.WhileStmt{
+		Keyword:   keyword,
 		Condition: expr,
 		Body:      stmt,
+		Span:      parser.span(keyword),
 	}, nil
 
 }
@@ -321,6 +421,7 @@ func (parser *Parser) WhileStatement() (ast.Stmt, error) {
 //   - error: if any part fails to parse.
 func (parser *Parser) ifStatement() (ast.Stmt, error) {
 
+	keyword := parser.previous()
 	conditionExpr, err := parser.expression()
 	if err != nil {
 		return nil, err
@@ -340,12 +441,91 @@ func (parser *Parser) ifStatement() (ast.Stmt, error) {
 	}
 
 	return ast.IfStmt{
+		Keyword:   keyword,
 		Condition: conditionExpr,
 		Then:      thenStmt,
 		Else:      elseStmt,
+		Span:      parser.span(keyword),
 	}, nil
 }
 
+// tryStatement parses a try statement of the form
+// "try { ... } catch (name) { ... } finally { ... }".
+// Either the catch or the finally clause can be omitted, but not both.
+// Returns:
+//   - ast.TryStmt: a TryStmt AST node.
+//   - error: if any part fails to parse.
+func (parser *Parser) tryStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
+
+	body, err := parser.blockStatement("Expected '{' after 'try'")
+	if err != nil {
+		return nil, err
+	}
+	stmt := ast.TryStmt{Keyword: keyword, Body: body}
+
+	if parser.isMatch([]token.TokenType{token.CATCH}) {
+		if _, err := parser.consume(token.LPA, "Expected '(' after 'catch'"); err != nil {
+			return nil, err
+		}
+		name, err := parser.consume(token.IDENTIFIER, "Expected variable name in 'catch' clause")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := parser.consume(token.RPA, "Expected ')' after 'catch' variable name"); err != nil {
+			return nil, err
+		}
+		catch, err := parser.blockStatement("Expected '{' after 'catch' clause")
+		if err != nil {
+			return nil, err
+		}
+		stmt.CatchName = name
+		stmt.Catch = &catch
+	}
+
+	if parser.isMatch([]token.TokenType{token.FINALLY}) {
+		finally, err := parser.blockStatement("Expected '{' after 'finally'")
+		if err != nil {
+			return nil, err
+		}
+		stmt.Finally = &finally
+	}
+
+	if stmt.Catch == nil && stmt.Finally == nil {
+		return nil, syntaxErrorAt(keyword, "Expected 'catch' or 'finally' after 'try' block")
+	}
+	stmt.Span = parser.span(keyword)
+	return stmt, nil
+}
+
+// throwStatement parses a throw statement of the form "throw <expression>".
+//
+// Returns:
+//   - Stmt: a ThrowStmt containing the expression to throw.
+//   - error: if the expression fails to parse.
+func (parser *Parser) throwStatement() (ast.Stmt, error) {
+	keyword := parser.previous()
+	expression, err := parser.expression()
+	if err != nil {
+		return nil, err
+	}
+	return ast.ThrowStmt{Keyword: keyword, Value: expression, Span: parser.span(keyword)}, nil
+}
+
+// blockStatement consumes the '{' opening a block and parses the block.
+// The errorMessage is used if the '{' is missing.
+func (parser *Parser) blockStatement(errorMessage string) (ast.BlockStmt, error) {
+	brace, err := parser.consume(token.LCUR, errorMessage)
+	if err != nil {
+		return ast.BlockStmt{}, err
+	}
+	statements, err := parser.block()
+	if err != nil {
+		return ast.BlockStmt{}, err
+	}
+	return ast.BlockStmt{Statements: statements, Span: parser.span(brace)}, nil
+}
+
 // expressionStatement parses a statement consisting of a single expression.
 //
 // Returns:
@@ -356,7 +536,7 @@ func (parser *Parser) expressionStatement() (ast.Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ast.ExpressionStmt{Expression: expression}, nil
+	return ast.ExpressionStmt{Expression: expression, Span: expression.SourceSpan()}, nil
 }
 
 // block parser a block statement consisting of a list of
@@ -379,7 +559,7 @@ func (parser *Parser) block() ([]ast.Stmt, error) {
 	previousToken := parser.previous()
 	if previousToken.TokenType != token.RCUR {
 		errMsg := fmt.Sprintf("Expected '%s' after block.", token.RCUR)
-		err := CreateSyntaxError(previousToken.Line, previousToken.Column, errMsg)
+		err := syntaxErrorAt(previousToken, errMsg)
 		return nil, err
 	}
 	return statements, nil
@@ -401,7 +581,7 @@ func (parser *Parser) expression() (ast.Expression, error) {
 //  1. First, parse the left-hand side (LHS) as an equality expression.
 //     This ensures proper precedence, so assignment has lower precedence
 //     than equality and arithmetic operators.
-//  2. If the next token is an '=' (ASSIGN), then:
+//  2. If the next token is an '=' (ASSIGN) or a compound assignment operator such as '+=', then:
 //     - Recursively call `assignment` to parse the right-hand side (RHS).
 //     - Check if the LHS is a valid assignment target:
 //     * If it's a Variable, produce an Assign AST node with the variable name
@@ -417,13 +597,15 @@ func (parser *Parser) expression() (ast.Expression, error) {
 //
 // Example:
 // Input:  x = 10
-// AST:    Assign{Name: x, Value: Literal(10)}
+// AST:    Assign{Name: x, Value: Literal(10), Operator: =}
+// Input:  x += 10
+// AST:    Assign{Name: x, Value: Literal(10), Operator: +=}
 func (parser *Parser) assignment() (ast.Expression, error) {
 	expression, err := parser.or()
 	if err != nil {
 		return nil, err
 	}
-	if parser.isMatch([]token.TokenType{token.ASSIGN}) {
+	if parser.isMatch(assignmentTokenTypes) {
 		equalsToken := parser.previous()
 		value, err := parser.assignment()
 		if err != nil {
@@ -432,11 +614,14 @@ func (parser *Parser) assignment() (ast.Expression, error) {
 		switch v := expression.(type) {
 		case ast.Variable:
 			name := v.Name
-			return ast.Assign{Name: name, Value: value}, nil
+			return ast.Assign{Name: name, Value: value, Operator: equalsToken, Span: spanBetween(v, value)}, nil
+
+		case ast.Get:
+			return ast.Set{Object: v.Object, Name: v.Name, Value: value, Operator: equalsToken, Span: spanBetween(v, value)}, nil
 
 		default:
 			msg := "Invalid assignment"
-			return nil, CreateSyntaxError(equalsToken.Line, equalsToken.Column, msg)
+			return nil, syntaxErrorAt(equalsToken, msg)
 		}
 	}
 
@@ -465,6 +650,7 @@ func (parser *Parser) or() (ast.Expression, error) {
 			Left:     expr,
 			Operator: op,
 			Right:    rightExpr,
+			Span:     spanBetween(expr, rightExpr),
 		}
 	}
 
@@ -495,6 +681,7 @@ func (parser *Parser) and() (ast.Expression, error) {
 			Left:     expr,
 			Operator: op,
 			Right:    rightExpr,
+			Span:     spanBetween(expr, rightExpr),
 		}
 	}
 	return expr, nil
@@ -520,6 +707,7 @@ func (parser *Parser) equality() (ast.Expression, error) {
 			Left:     exp,
 			Operator: operator,
 			Right:    right,
+			Span:     spanBetween(exp, right),
 		}
 	}
 	return exp, nil
@@ -531,13 +719,13 @@ func (parser *Parser) equality() (ast.Expression, error) {
 //   - Expression: a Binary node (or sub-expression) representing a comparison.
 //   - error: if parsing fails.
 func (parser *Parser) comparison() (ast.Expression, error) {
-	exp, err := parser.term()
+	exp, err := parser.bitwiseOr()
 	if err != nil {
 		return nil, err
 	}
 	for parser.isMatch(comparisonTokenTypes) {
 		operator := parser.previous()
-		right, err := parser.term()
+		right, err := parser.bitwiseOr()
 		if err != nil {
 			return nil, err
 		}
@@ -545,6 +733,75 @@ func (parser *Parser) comparison() (ast.Expression, error) {
 			Left:     exp,
 			Operator: operator,
 			Right:    right,
+			Span:     spanBetween(exp, right),
+		}
+	}
+	return exp, nil
+}
+
+// bitwiseOr parses bitwise OR expressions using the operator "|". Bitwise operators have a
+// higher precedence than comparisons, e.g `a & 1 == 0` is `(a & 1) == 0`.
+//
+// Returns:
+//   - Expression: a Binary node (or sub-expression) representing a bitwise OR.
+//   - error: if parsing fails.
+func (parser *Parser) bitwiseOr() (ast.Expression, error) {
+	return parser.leftAssociative([]token.TokenType{token.BIT_OR}, parser.bitwiseXor)
+}
+
+// bitwiseXor parses bitwise XOR expressions using the operator "^".
+//
+// Returns:
+//   - Expression: a Binary node (or sub-expression) representing a bitwise XOR.
+//   - error: if parsing fails.
+func (parser *Parser) bitwiseXor() (ast.Expression, error) {
+	return parser.leftAssociative([]token.TokenType{token.BIT_XOR}, parser.bitwiseAnd)
+}
+
+// bitwiseAnd parses bitwise AND expressions using the operator "&".
+//
+// Returns:
+//   - Expression: a Binary node (or sub-expression) representing a bitwise AND.
+//   - error: if parsing fails.
+func (parser *Parser) bitwiseAnd() (ast.Expression, error) {
+	return parser.leftAssociative([]token.TokenType{token.BIT_AND}, parser.shift)
+}
+
+// shift parses bit shift expressions using operators "<<" and ">>".
+//
+// Returns:
+//   - Expression: a Binary node (or sub-expression) representing a bit shift.
+//   - error: if parsing fails.
+func (parser *Parser) shift() (ast.Expression, error) {
+	return parser.leftAssociative(shiftTokenTypes, parser.term)
+}
+
+// leftAssociative parses a sequence of operands separated by any of the operators, building
+// a left-associative tree of Binary nodes, e.g `a | b | c` is `(a | b) | c`.
+//
+// Parameters:
+//   - operators: the operators of the precedence level.
+//   - operand: parses the operands, which are expressions of the next higher precedence level.
+//
+// Returns:
+//   - Expression: a Binary node (or sub-expression).
+//   - error: if parsing fails.
+func (parser *Parser) leftAssociative(operators []token.TokenType, operand func() (ast.Expression, error)) (ast.Expression, error) {
+	exp, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for parser.isMatch(operators) {
+		operator := parser.previous()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		exp = ast.Binary{
+			Left:     exp,
+			Operator: operator,
+			Right:    right,
+			Span:     spanBetween(exp, right),
 		}
 	}
 	return exp, nil
@@ -570,12 +827,14 @@ func (parser *Parser) term() (ast.Expression, error) {
 			Left:     exp,
 			Operator: operator,
 			Right:    right,
+			Span:     spanBetween(exp, right),
 		}
 	}
 	return exp, nil
 }
 
-// factor parses multiplication and division expressions using operators "*" and "/".
+// factor parses multiplication, division, integer division and modulo expressions
+// using operators "*", "/", "//" and "%".
 //
 // Returns:
 //   - Expression: a Binary node (or sub-expression) representing multiplication or division.
@@ -595,16 +854,17 @@ func (parser *Parser) factor() (ast.Expression, error) {
 			Left:     exp,
 			Operator: operator,
 			Right:    right,
+			Span:     spanBetween(exp, right),
 		}
 	}
 	return exp, nil
 }
 
-// unary parses unary prefix expressions using operators "!" or "-".
-// Examples: "!true", "-x".
+// unary parses unary prefix expressions using operators "!", "-" or "~".
+// Examples: "!true", "-x", "~0".
 //
 // Returns:
-//   - Expression: a Unary node if a unary operator was found, otherwise defers to primary().
+//   - Expression: a Unary node if a unary operator was found, otherwise defers to power().
 //   - error: if parsing fails.
 func (parser *Parser) unary() (ast.Expression, error) {
 	if parser.isMatch(unaryExpressionTypes) {
@@ -616,9 +876,108 @@ func (parser *Parser) unary() (ast.Expression, error) {
 		return ast.Unary{
 			Operator: operator,
 			Right:    right,
+			Span:     parser.span(operator),
 		}, nil
 	}
-	return parser.primary()
+	return parser.power()
+}
+
+// power parses exponentiation expressions using the operator "**". It is right-associative
+// and binds tighter than a unary operator on its left, but its right operand can be a unary
+// expression, e.g `2 ** 3 ** 2` is `2 ** (3 ** 2)`, `-2 ** 2` is `-(2 ** 2)` and `2 ** -1` is valid.
+//
+// Returns:
+//   - Expression: a Binary node (or sub-expression) representing an exponentiation.
+//   - error: if parsing fails.
+func (parser *Parser) power() (ast.Expression, error) {
+	exp, err := parser.call()
+	if err != nil {
+		return nil, err
+	}
+	if parser.isMatch([]token.TokenType{token.POW}) {
+		operator := parser.previous()
+		right, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		return ast.Binary{
+			Left:     exp,
+			Operator: operator,
+			Right:    right,
+			Span:     spanBetween(exp, right),
+		}, nil
+	}
+	return exp, nil
+}
+
+// maxArguments is the largest number of arguments a function can be called with.
+const maxArguments = 255
+
+// call parses a primary expression followed by any number of calls and property accesses,
+// which are left-associative, e.g `math.max(a, b)` calls the `max` property of `math`.
+//
+// Returns:
+//   - Expression: a Call or Get node, or the primary expression if it is not followed by either.
+//   - error: if parsing fails.
+func (parser *Parser) call() (ast.Expression, error) {
+	exp, err := parser.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if parser.isMatch([]token.TokenType{token.LPA}) {
+			exp, err = parser.finishCall(exp)
+			if err != nil {
+				return nil, err
+			}
+		} else if parser.isMatch([]token.TokenType{token.DOT}) {
+			name, err := parser.consume(token.IDENTIFIER, "Expected property name after '.'")
+			if err != nil {
+				return nil, err
+			}
+			exp = ast.Get{
+				Object: exp,
+				Name:   name,
+				Span:   ast.Span{Start: exp.SourceSpan().Start, End: name.End},
+			}
+		} else {
+			return exp, nil
+		}
+	}
+}
+
+// finishCall parses the arguments of a call of `callee`, after its opening parenthesis.
+//
+// Returns:
+//   - Expression: a Call node.
+//   - error: if parsing fails or there are more than maxArguments arguments.
+func (parser *Parser) finishCall(callee ast.Expression) (ast.Expression, error) {
+	arguments := []ast.Expression{}
+	if !parser.checkType(token.RPA) {
+		for {
+			if len(arguments) == maxArguments {
+				return nil, syntaxErrorAt(parser.peek(), fmt.Sprintf("Can't have more than %d arguments", maxArguments))
+			}
+			argument, err := parser.expression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+			if !parser.isMatch([]token.TokenType{token.COMMA}) {
+				break
+			}
+		}
+	}
+	paren, err := parser.consume(token.RPA, "Expected ')' after arguments")
+	if err != nil {
+		return nil, err
+	}
+	return ast.Call{
+		Callee:    callee,
+		Paren:     paren,
+		Arguments: arguments,
+		Span:      ast.Span{Start: callee.SourceSpan().Start, End: paren.End},
+	}, nil
 }
 
 // primary parses the most basic forms of expressions:
@@ -632,24 +991,29 @@ func (parser *Parser) unary() (ast.Expression, error) {
 //   - error: if no valid primary expression can be parsed.
 func (parser *Parser) primary() (ast.Expression, error) {
 	if parser.isMatch([]token.TokenType{token.FALSE}) {
-		return ast.Literal{Value: false}, nil
+		return ast.Literal{Value: false, Span: ast.TokenSpan(parser.previous())}, nil
 	}
 	if parser.isMatch([]token.TokenType{token.NULL}) {
-		return ast.Literal{Value: nil}, nil
+		return ast.Literal{Value: nil, Span: ast.TokenSpan(parser.previous())}, nil
 	}
 	if parser.isMatch([]token.TokenType{token.TRUE}) {
-		return ast.Literal{Value: true}, nil
+		return ast.Literal{Value: true, Span: ast.TokenSpan(parser.previous())}, nil
+	}
+
+	if parser.isMatch([]token.TokenType{token.FLOAT, token.INT, token.DECIMAL, token.STRING}) {
+		return ast.Literal{Value: parser.previous().Literal, Span: ast.TokenSpan(parser.previous())}, nil
 	}
 
-	if parser.isMatch([]token.TokenType{token.FLOAT, token.INT, token.STRING}) {
-		return ast.Literal{Value: parser.previous().Literal}, nil
+	if parser.isMatch([]token.TokenType{token.INTERPOLATION}) {
+		return parser.interpolation()
 	}
 
 	if parser.isMatch([]token.TokenType{token.IDENTIFIER}) {
-		return ast.Variable{Name: parser.previous()}, nil
+		return ast.Variable{Name: parser.previous(), Span: ast.TokenSpan(parser.previous())}, nil
 	}
 
 	if parser.isMatch([]token.TokenType{token.LPA}) {
+		paren := parser.previous()
 		expr, err := parser.expression()
 		if err != nil {
 			return nil, err
@@ -658,11 +1022,39 @@ func (parser *Parser) primary() (ast.Expression, error) {
 		if consumeErr != nil {
 			return nil, consumeErr
 		}
-		return ast.Grouping{Expression: expr}, nil
+		return ast.Grouping{Expression: expr, Span: parser.span(paren)}, nil
 	}
 
 	currentToken := parser.peek()
-	return nil, CreateSyntaxError(currentToken.Line, currentToken.Column, "Unrecognised expression.")
+	return nil, syntaxErrorAt(currentToken, "Unrecognised expression.")
+}
+
+// interpolation parses a string containing interpolated expressions, after its first INTERPOLATION
+// token. The lexer splits the string into an INTERPOLATION token before every expression and a
+// STRING token after the last one, e.g `"a${x}b"` is scanned as `INTERPOLATION(a) x STRING(b)`.
+//
+// Returns:
+//   - Expression: an Interpolation expression.
+//   - error: if an interpolated expression is missing or can not be parsed.
+func (parser *Parser) interpolation() (ast.Expression, error) {
+	first := parser.previous()
+	var parts []ast.Expression
+	for {
+		text := parser.previous()
+		parts = append(parts, ast.Literal{Value: text.Literal, Span: ast.TokenSpan(text)})
+		if text.TokenType == token.STRING {
+			return ast.Interpolation{Parts: parts, Span: parser.span(first)}, nil
+		}
+
+		expr, err := parser.expression()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, expr)
+		if !parser.isMatch([]token.TokenType{token.INTERPOLATION, token.STRING}) {
+			return nil, syntaxErrorAt(parser.peek(), "Expected '}' after the interpolated expression.")
+		}
+	}
 }
 
 // Consumes the current token by advancing the parsers current position by
@@ -677,5 +1069,5 @@ func (parser *Parser) consume(tokenType token.TokenType, errorMessage string) (t
 		return parser.advance(), nil
 	}
 	currentToken := parser.peek()
-	return token.CreateToken(token.EOF, 0, 0), CreateSyntaxError(currentToken.Line, currentToken.Column, errorMessage)
+	return token.CreateToken(token.EOF), syntaxErrorAt(currentToken, errorMessage)
 }
@@ -0,0 +1,380 @@
+package parser
+
+import (
+	"fmt"
+	"nilan/ast"
+	"nilan/lexer"
+	"strings"
+	"testing"
+)
+
+func parseSource(t *testing.T, source string) ([]ast.Stmt, []error) {
+	t.Helper()
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("lexing error: %v", lexErrs)
+	}
+	return Make(tokens).Parse()
+}
+
+func TestParseTryStatement(t *testing.T) {
+	stmts, errs := parseSource(t, "try { throw 1 } catch (e) { print e } finally { print 2 }")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(stmts) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(stmts))
+	}
+	tryStmt, ok := stmts[0].(ast.TryStmt)
+	if !ok {
+		t.Fatalf("expected a TryStmt, got %T", stmts[0])
+	}
+	if len(tryStmt.Body.Statements) != 1 {
+		t.Fatalf("expected 1 statement in the try block, got %d", len(tryStmt.Body.Statements))
+	}
+	if _, ok := tryStmt.Body.Statements[0].(ast.ThrowStmt); !ok {
+		t.Errorf("expected a ThrowStmt in the try block, got %T", tryStmt.Body.Statements[0])
+	}
+	if tryStmt.CatchName.Lexeme != "e" {
+		t.Errorf("expected catch variable 'e', got %q", tryStmt.CatchName.Lexeme)
+	}
+	if tryStmt.Catch == nil || tryStmt.Finally == nil {
+		t.Fatalf("expected catch and finally blocks, got %+v", tryStmt)
+	}
+
+	stmts, errs = parseSource(t, "try { print 1 } finally { print 2 }")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if tryStmt := stmts[0].(ast.TryStmt); tryStmt.Catch != nil || tryStmt.Finally == nil {
+		t.Errorf("expected only a finally block, got %+v", tryStmt)
+	}
+}
+
+func TestParseTryStatementErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "try { print 1 }", want: "Expected 'catch' or 'finally' after 'try' block"},
+		{source: "try print 1 catch (e) {}", want: "Expected '{' after 'try'"},
+		{source: "try {} catch e {}", want: "Expected '(' after 'catch'"},
+		{source: "try {} catch (1) {}", want: "Expected variable name in 'catch' clause"},
+		{source: "try {} catch (e {}", want: "Expected ')' after 'catch' variable name"},
+		{source: "throw", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, errs := parseSource(t, tt.source)
+			if len(errs) == 0 {
+				t.Fatal("expected a syntax error, got none")
+			}
+			if !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("got error: %v, want it to contain: %q", errs[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	source := "var s = \"héllo\"\nif s == null {\n    print -(1 + 2)\n} else print s\nwhile false s = s"
+	stmts, errs := parseSource(t, source)
+	if len(errs) > 0 {
+		t.Fatalf("parsing errors: %v", errs)
+	}
+	ifStmt := stmts[1].(ast.IfStmt)
+	block := ifStmt.Then.(ast.BlockStmt)
+	print := block.Statements[0].(ast.PrintStmt)
+	unary := print.Expression.(ast.Unary)
+	grouping := unary.Right.(ast.Grouping)
+	binary := grouping.Expression.(ast.Binary)
+	while := stmts[2].(ast.WhileStmt)
+	assign := while.Body.(ast.ExpressionStmt).Expression.(ast.Assign)
+
+	tests := []struct {
+		name  string
+		node  interface{ SourceSpan() ast.Span }
+		start string
+		end   string
+	}{
+		{name: "var statement", node: stmts[0], start: "1:1", end: "1:16"},
+		{name: "string literal", node: stmts[0].(ast.VarStmt).Initializer, start: "1:9", end: "1:16"},
+		{name: "if statement", node: ifStmt, start: "2:1", end: "4:15"},
+		{name: "condition", node: ifStmt.Condition, start: "2:4", end: "2:13"},
+		{name: "block", node: block, start: "2:14", end: "4:2"},
+		{name: "print statement", node: print, start: "3:5", end: "3:19"},
+		{name: "unary", node: unary, start: "3:11", end: "3:19"},
+		{name: "grouping", node: grouping, start: "3:12", end: "3:19"},
+		{name: "binary", node: binary, start: "3:13", end: "3:18"},
+		{name: "else branch", node: ifStmt.Else, start: "4:8", end: "4:15"},
+		{name: "while statement", node: while, start: "5:1", end: "5:18"},
+		{name: "assignment", node: assign, start: "5:13", end: "5:18"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := tt.node.SourceSpan()
+			if span.Start.String() != tt.start || span.End.String() != tt.end {
+				t.Errorf("got span: %s-%s, want: %s-%s", span.Start, span.End, tt.start, tt.end)
+			}
+		})
+	}
+
+	// Offsets are in bytes, `é` is encoded with two bytes.
+	if got := ifStmt.Span.Start.Offset; got != 17 {
+		t.Errorf("got offset of the if statement: %d, want: 17", got)
+	}
+}
+
+func TestParseInterpolation(t *testing.T) {
+	stmts, errs := parseSource(t, `print "a ${x + 1} b ${y}"`)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	interpolation, ok := stmts[0].(ast.PrintStmt).Expression.(ast.Interpolation)
+	if !ok {
+		t.Fatalf("expected an Interpolation, got %T", stmts[0].(ast.PrintStmt).Expression)
+	}
+	if len(interpolation.Parts) != 5 {
+		t.Fatalf("expected 5 parts, got %d: %+v", len(interpolation.Parts), interpolation.Parts)
+	}
+	for i, want := range []string{"a ", " b ", ""} {
+		literal, ok := interpolation.Parts[i*2].(ast.Literal)
+		if !ok || literal.Value != want {
+			t.Errorf("expected text part %d to be %q, got %+v", i*2, want, interpolation.Parts[i*2])
+		}
+	}
+	if _, ok := interpolation.Parts[1].(ast.Binary); !ok {
+		t.Errorf("expected a Binary expression as part 1, got %T", interpolation.Parts[1])
+	}
+	if _, ok := interpolation.Parts[3].(ast.Variable); !ok {
+		t.Errorf("expected a Variable as part 3, got %T", interpolation.Parts[3])
+	}
+	if span := interpolation.Span; span.Start.String() != "1:7" || span.End.String() != "1:26" {
+		t.Errorf("got span: %s-%s, want: 1:7-1:26", span.Start, span.End)
+	}
+
+	_, errs = parseSource(t, `print "a ${x y}"`)
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "Expected '}' after the interpolated expression") {
+		t.Errorf("got errors: %v, want a missing '}' error", errs)
+	}
+}
+
+// parenthesize renders an expression with every binary and unary expression in parentheses,
+// to compare how operators are grouped.
+func parenthesize(expr ast.Expression) string {
+	switch e := expr.(type) {
+	case ast.Binary:
+		return fmt.Sprintf("(%s %s %s)", parenthesize(e.Left), e.Operator.Lexeme, parenthesize(e.Right))
+	case ast.Logical:
+		return fmt.Sprintf("(%s %s %s)", parenthesize(e.Left), e.Operator.Lexeme, parenthesize(e.Right))
+	case ast.Unary:
+		return fmt.Sprintf("(%s%s)", e.Operator.Lexeme, parenthesize(e.Right))
+	case ast.Grouping:
+		return parenthesize(e.Expression)
+	case ast.Variable:
+		return e.Name.Lexeme
+	case ast.Literal:
+		return fmt.Sprint(e.Value)
+	case ast.Assign:
+		return fmt.Sprintf("(%s %s %s)", e.Name.Lexeme, e.Operator.Lexeme, parenthesize(e.Value))
+	case ast.Call:
+		arguments := make([]string, 0, len(e.Arguments))
+		for _, argument := range e.Arguments {
+			arguments = append(arguments, parenthesize(argument))
+		}
+		return fmt.Sprintf("%s(%s)", parenthesize(e.Callee), strings.Join(arguments, ", "))
+	case ast.Get:
+		return fmt.Sprintf("%s.%s", parenthesize(e.Object), e.Name.Lexeme)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func TestParseOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "a + b % c", want: "(a + (b % c))"},
+		{source: "a // b * c", want: "((a // b) * c)"},
+		{source: "a ** b ** c", want: "(a ** (b ** c))"},
+		{source: "-a ** b", want: "(-(a ** b))"},
+		{source: "a ** -b", want: "(a ** (-b))"},
+		{source: "a * b ** c", want: "(a * (b ** c))"},
+		{source: "~a & b", want: "((~a) & b)"},
+		{source: "a | b ^ c & d", want: "(a | (b ^ (c & d)))"},
+		{source: "a & b << c + d", want: "(a & (b << (c + d)))"},
+		{source: "a << b >> c", want: "((a << b) >> c)"},
+		{source: "a & b == c", want: "((a & b) == c)"},
+		{source: "a | b < c", want: "((a | b) < c)"},
+		{source: "a += b * c", want: "(a += (b * c))"},
+		{source: "a -= b -= c", want: "(a -= (b -= c))"},
+		{source: "a *= 2", want: "(a *= 2)"},
+		{source: "a /= 2", want: "(a /= 2)"},
+		{source: "-f(a) ** 2", want: "(-(f(a) ** 2))"},
+		{source: "2 ** math.pi", want: "(2 ** math.pi)"},
+		{source: "f(a)(b, c + 1)", want: "f(a)(b, (c + 1))"},
+		{source: "a.b.c()", want: "a.b.c()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			stmts, errs := parseSource(t, tt.source)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			got := parenthesize(stmts[0].(ast.ExpressionStmt).Expression)
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	_, errs := parseSource(t, "1 += 2")
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "Invalid assignment") {
+		t.Errorf("got errors: %v, want an invalid assignment error", errs)
+	}
+}
+
+func TestParseCall(t *testing.T) {
+	stmts, errs := parseSource(t, "math.max(1, x)")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	call, ok := stmts[0].(ast.ExpressionStmt).Expression.(ast.Call)
+	if !ok {
+		t.Fatalf("expected a Call, got %T", stmts[0].(ast.ExpressionStmt).Expression)
+	}
+	if get, ok := call.Callee.(ast.Get); !ok || get.Name.Lexeme != "max" {
+		t.Errorf("expected the callee to be a Get of 'max', got %+v", call.Callee)
+	}
+	if len(call.Arguments) != 2 {
+		t.Errorf("expected 2 arguments, got %d", len(call.Arguments))
+	}
+	if call.Paren.Lexeme != ")" {
+		t.Errorf("expected the closing parenthesis, got %q", call.Paren.Lexeme)
+	}
+	if span := call.Span; span.Start.String() != "1:1" || span.End.String() != "1:15" {
+		t.Errorf("got span: %s-%s, want: 1:1-1:15", span.Start, span.End)
+	}
+
+	tooMany := "f(" + strings.Repeat("1, ", 255) + "1)"
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "print .", want: "Unrecognised expression"},
+		{source: "math.", want: "Expected property name after '.'"},
+		{source: "math.(x)", want: "Expected property name after '.'"},
+		{source: "f(1, 2", want: "Expected ')' after arguments"},
+		{source: "f(1,)", want: "Unrecognised expression"},
+		{source: tooMany, want: "Can't have more than 255 arguments"},
+		{source: "f() = 3", want: "Invalid assignment"},
+	}
+	for _, tt := range tests {
+		_, errs := parseSource(t, tt.source)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("got errors for %.20q: %v, want: %s", tt.source, errs, tt.want)
+		}
+	}
+}
+
+func TestParseImport(t *testing.T) {
+	stmts, errs := parseSource(t, `import "lib/util.ni" as util`)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	stmt, ok := stmts[0].(ast.ImportStmt)
+	if !ok {
+		t.Fatalf("expected an ImportStmt, got %T", stmts[0])
+	}
+	if stmt.Path.Literal != "lib/util.ni" || stmt.Name.Lexeme != "util" {
+		t.Errorf("got path: %v, name: %s, want: lib/util.ni, util", stmt.Path.Literal, stmt.Name.Lexeme)
+	}
+	if span := stmt.Span; span.Start.String() != "1:1" || span.End.String() != "1:29" {
+		t.Errorf("got span: %s-%s, want: 1:1-1:29", span.Start, span.End)
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "import lib as lib", want: "Expected the path of the module as a string after 'import'"},
+		{source: `import "lib.ni"`, want: "Expected 'as' after the path of the module"},
+		{source: `import "lib.ni" lib`, want: "Expected 'as' after the path of the module"},
+		{source: `import "lib.ni" as "lib"`, want: "Expected the name of the module after 'as'"},
+	}
+	for _, tt := range tests {
+		_, errs := parseSource(t, tt.source)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("got errors for %q: %v, want: %s", tt.source, errs, tt.want)
+		}
+	}
+}
+
+func TestParseSet(t *testing.T) {
+	stmts, errs := parseSource(t, "a.b.c += 1")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	set, ok := stmts[0].(ast.ExpressionStmt).Expression.(ast.Set)
+	if !ok {
+		t.Fatalf("expected a Set, got %T", stmts[0].(ast.ExpressionStmt).Expression)
+	}
+	if get, ok := set.Object.(ast.Get); !ok || get.Name.Lexeme != "b" {
+		t.Errorf("expected the object to be a Get of 'b', got %+v", set.Object)
+	}
+	if set.Name.Lexeme != "c" || set.Operator.Lexeme != "+=" {
+		t.Errorf("got name: %s, operator: %s, want: c, +=", set.Name.Lexeme, set.Operator.Lexeme)
+	}
+	if span := set.Span; span.Start.String() != "1:1" || span.End.String() != "1:11" {
+		t.Errorf("got span: %s-%s, want: 1:1-1:11", span.Start, span.End)
+	}
+
+	for _, source := range []string{"a.b = 3 + 1", "a.b = c.d = 2"} {
+		stmts, errs := parseSource(t, source)
+		if len(errs) > 0 {
+			t.Fatalf("unexpected errors for %q: %v", source, errs)
+		}
+		if _, ok := stmts[0].(ast.ExpressionStmt).Expression.(ast.Set); !ok {
+			t.Errorf("expected a Set for %q, got %T", source, stmts[0].(ast.ExpressionStmt).Expression)
+		}
+	}
+}
+
+func TestParseStruct(t *testing.T) {
+	stmts, errs := parseSource(t, "struct Point { x, y }\nstruct Empty {}")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	stmt, ok := stmts[0].(ast.StructStmt)
+	if !ok {
+		t.Fatalf("expected a StructStmt, got %T", stmts[0])
+	}
+	if stmt.Name.Lexeme != "Point" || len(stmt.Fields) != 2 || stmt.Fields[0].Lexeme != "x" || stmt.Fields[1].Lexeme != "y" {
+		t.Errorf("got struct %s with fields %v, want Point with fields x, y", stmt.Name.Lexeme, stmt.Fields)
+	}
+	if span := stmt.Span; span.Start.String() != "1:1" || span.End.String() != "1:22" {
+		t.Errorf("got span: %s-%s, want: 1:1-1:22", span.Start, span.End)
+	}
+	if empty := stmts[1].(ast.StructStmt); len(empty.Fields) != 0 {
+		t.Errorf("got fields: %v, want none", empty.Fields)
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "struct { x }", want: "Expected the name of the struct after 'struct'"},
+		{source: "struct Point x, y", want: "Expected '{' after the name of the struct"},
+		{source: "struct Point { x y }", want: "Expected ',' or '}' after a field"},
+		{source: "struct Point { x, }", want: "Expected the name of a field"},
+		{source: "struct Point { x, 1 }", want: "Expected the name of a field"},
+		{source: "struct Point { x, y", want: "Expected ',' or '}' after a field"},
+		{source: "struct Point { x, y, x }", want: "Duplicate field 'x' in struct 'Point'"},
+	}
+	for _, tt := range tests {
+		_, errs := parseSource(t, tt.source)
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.want) {
+			t.Errorf("got errors for %q: %v, want: %s", tt.source, errs, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"nilan/ast"
+	"nilan/token"
 	"os"
 )
 
@@ -13,73 +14,150 @@ const (
 )
 
 type blockStmtJSON struct {
-	Type       string `json:"type"`
-	Statements []any  `json:"statements"`
+	Type       string   `json:"type"`
+	Statements []any    `json:"statements"`
+	Span       ast.Span `json:"span"`
 }
 
 type expressionStmtJSON struct {
-	Type       string `json:"type"`
-	Expression any    `json:"expression"`
+	Type       string   `json:"type"`
+	Expression any      `json:"expression"`
+	Span       ast.Span `json:"span"`
 }
 
 type printStmtJSON struct {
-	Type       string `json:"type"`
-	Expression any    `json:"expression"`
+	Type       string   `json:"type"`
+	Expression any      `json:"expression"`
+	Span       ast.Span `json:"span"`
 }
 
 type varStmtJSON struct {
-	Type        string `json:"type"`
-	Name        string `json:"name"`
-	Initializer any    `json:"initializer"`
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Initializer any      `json:"initializer"`
+	Span        ast.Span `json:"span"`
 }
 
 type whileStmtJSON struct {
-	Type      string `json:"type"`
-	Condition any    `json:"condition"`
-	Body      any    `json:"body"`
+	Type      string   `json:"type"`
+	Condition any      `json:"condition"`
+	Body      any      `json:"body"`
+	Span      ast.Span `json:"span"`
 }
 
 type ifStmtJSON struct {
-	Type      string `json:"type"`
-	Condition any    `json:"condition"`
-	Then      any    `json:"then"`
-	Else      any    `json:"else"`
+	Type      string   `json:"type"`
+	Condition any      `json:"condition"`
+	Then      any      `json:"then"`
+	Else      any      `json:"else"`
+	Span      ast.Span `json:"span"`
+}
+
+type tryStmtJSON struct {
+	Type      string   `json:"type"`
+	Body      any      `json:"body"`
+	CatchName string   `json:"catchName,omitempty"`
+	Catch     any      `json:"catch"`
+	Finally   any      `json:"finally"`
+	Span      ast.Span `json:"span"`
+}
+
+type throwStmtJSON struct {
+	Type  string   `json:"type"`
+	Value any      `json:"value"`
+	Span  ast.Span `json:"span"`
+}
+
+type importStmtJSON struct {
+	Type string   `json:"type"`
+	Path string   `json:"path"`
+	Name string   `json:"name"`
+	Span ast.Span `json:"span"`
+}
+
+type structStmtJSON struct {
+	Type   string   `json:"type"`
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+	Span   ast.Span `json:"span"`
 }
 
 type assignExprJSON struct {
-	Type  string `json:"type"`
-	Name  string `json:"name"`
-	Value any    `json:"value"`
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	Operator string   `json:"operator,omitempty"`
+	Value    any      `json:"value"`
+	Span     ast.Span `json:"span"`
 }
 
 type logicalExprJSON struct {
-	Type     string `json:"type"`
-	Operator string `json:"operator"`
-	Left     any    `json:"left"`
-	Right    any    `json:"right"`
+	Type     string   `json:"type"`
+	Operator string   `json:"operator"`
+	Left     any      `json:"left"`
+	Right    any      `json:"right"`
+	Span     ast.Span `json:"span"`
 }
 
 type groupingExprJSON struct {
-	Type       string `json:"type"`
-	Expression any    `json:"expression"`
+	Type       string   `json:"type"`
+	Expression any      `json:"expression"`
+	Span       ast.Span `json:"span"`
+}
+
+type interpolationExprJSON struct {
+	Type  string   `json:"type"`
+	Parts []any    `json:"parts"`
+	Span  ast.Span `json:"span"`
+}
+
+type callExprJSON struct {
+	Type      string   `json:"type"`
+	Callee    any      `json:"callee"`
+	Arguments []any    `json:"arguments"`
+	Span      ast.Span `json:"span"`
+}
+
+type getExprJSON struct {
+	Type   string   `json:"type"`
+	Object any      `json:"object"`
+	Name   string   `json:"name"`
+	Span   ast.Span `json:"span"`
+}
+
+type setExprJSON struct {
+	Type     string   `json:"type"`
+	Object   any      `json:"object"`
+	Name     string   `json:"name"`
+	Operator string   `json:"operator,omitempty"`
+	Value    any      `json:"value"`
+	Span     ast.Span `json:"span"`
 }
 
 type variableExprJSON struct {
-	Type string `json:"type"`
-	Name string `json:"name"`
+	Type string   `json:"type"`
+	Name string   `json:"name"`
+	Span ast.Span `json:"span"`
 }
 
 type binaryExprJSON struct {
-	Type     string `json:"type"`
-	Operator string `json:"operator"`
-	Left     any    `json:"left"`
-	Right    any    `json:"right"`
+	Type     string   `json:"type"`
+	Operator string   `json:"operator"`
+	Left     any      `json:"left"`
+	Right    any      `json:"right"`
+	Span     ast.Span `json:"span"`
+}
+
+type literalExprJSON struct {
+	Type  string   `json:"type"`
+	Value any      `json:"value"`
+	Span  ast.Span `json:"span"`
 }
 
 type unaryExprJSON struct {
-	Type     string `json:"type"`
-	Operator string `json:"operator"`
-	Right    any    `json:"right"`
+	Type     string   `json:"type"`
+	Operator string   `json:"operator"`
+	Right    any      `json:"right"`
+	Span     ast.Span `json:"span"`
 }
 
 // astPrinter implements the Visitor interfaces and builds a
@@ -91,6 +169,7 @@ func (p astPrinter) VisitExpressionStmt(exprStmt ast.ExpressionStmt) any {
 	return expressionStmtJSON{
 		Type:       "ExpressionStmt",
 		Expression: exprStmt.Expression.Accept(p),
+		Span:       exprStmt.Span,
 	}
 }
 
@@ -98,6 +177,7 @@ func (p astPrinter) VisitPrintStmt(printStmt ast.PrintStmt) any {
 	return printStmtJSON{
 		Type:       "PrintStmt",
 		Expression: printStmt.Expression.Accept(p),
+		Span:       printStmt.Span,
 	}
 }
 
@@ -106,6 +186,7 @@ func (p astPrinter) VisitVarStmt(varStmt ast.VarStmt) any {
 		Type:        "VarStmt",
 		Name:        varStmt.Name.Lexeme,
 		Initializer: nilOrAccept(varStmt.Initializer, p),
+		Span:        varStmt.Span,
 	}
 }
 
@@ -117,6 +198,7 @@ func (p astPrinter) VisitBlockStmt(blockStmt ast.BlockStmt) any {
 	return blockStmtJSON{
 		Type:       "BlockStmt",
 		Statements: stmts,
+		Span:       blockStmt.Span,
 	}
 }
 
@@ -125,6 +207,7 @@ func (p astPrinter) VisitWhileStmt(stmt ast.WhileStmt) any {
 		Type:      "WhileStmt",
 		Condition: stmt.Condition.Accept(p),
 		Body:      stmt.Body.Accept(p),
+		Span:      stmt.Span,
 	}
 }
 
@@ -140,6 +223,55 @@ func (p astPrinter) VisitIfStmt(stmt ast.IfStmt) any {
 		Condition: stmt.Condition.Accept(p),
 		Then:      stmt.Then.Accept(p),
 		Else:      elseVal,
+		Span:      stmt.Span,
+	}
+}
+
+func (p astPrinter) VisitTryStmt(stmt ast.TryStmt) any {
+	var catchVal, finallyVal any
+	if stmt.Catch != nil {
+		catchVal = stmt.Catch.Accept(p)
+	}
+	if stmt.Finally != nil {
+		finallyVal = stmt.Finally.Accept(p)
+	}
+	return tryStmtJSON{
+		Type:      "TryStmt",
+		Body:      stmt.Body.Accept(p),
+		CatchName: stmt.CatchName.Lexeme,
+		Catch:     catchVal,
+		Finally:   finallyVal,
+		Span:      stmt.Span,
+	}
+}
+
+func (p astPrinter) VisitThrowStmt(stmt ast.ThrowStmt) any {
+	return throwStmtJSON{
+		Type:  "ThrowStmt",
+		Value: stmt.Value.Accept(p),
+		Span:  stmt.Span,
+	}
+}
+
+func (p astPrinter) VisitImportStmt(stmt ast.ImportStmt) any {
+	return importStmtJSON{
+		Type: "ImportStmt",
+		Path: stmt.Path.Literal.(string),
+		Name: stmt.Name.Lexeme,
+		Span: stmt.Span,
+	}
+}
+
+func (p astPrinter) VisitStructStmt(stmt ast.StructStmt) any {
+	fields := make([]string, 0, len(stmt.Fields))
+	for _, field := range stmt.Fields {
+		fields = append(fields, field.Lexeme)
+	}
+	return structStmtJSON{
+		Type:   "StructStmt",
+		Name:   stmt.Name.Lexeme,
+		Fields: fields,
+		Span:   stmt.Span,
 	}
 }
 
@@ -149,21 +281,29 @@ func (p astPrinter) VisitLogicalExpression(expr ast.Logical) any {
 		Operator: expr.Operator.Lexeme,
 		Left:     expr.Left.Accept(p),
 		Right:    expr.Right.Accept(p),
+		Span:     expr.Span,
 	}
 }
 
 func (p astPrinter) VisitAssignExpression(assign ast.Assign) any {
-	return assignExprJSON{
+	expr := assignExprJSON{
 		Type:  "Assign",
 		Name:  assign.Name.Lexeme,
 		Value: assign.Value.Accept(p),
+		Span:  assign.Span,
+	}
+	// NOTE: Only compound assignment operators are printed, a plain assignment is `=`.
+	if _, ok := token.CompoundAssignments[assign.Operator.TokenType]; ok {
+		expr.Operator = assign.Operator.Lexeme
 	}
+	return expr
 }
 
 func (p astPrinter) VisitVariableExpression(variable ast.Variable) any {
 	return variableExprJSON{
 		Type: "Variable",
 		Name: variable.Name.Lexeme,
+		Span: variable.Span,
 	}
 }
 
@@ -173,6 +313,7 @@ func (p astPrinter) VisitBinary(b ast.Binary) any {
 		Operator: b.Operator.Lexeme,
 		Left:     b.Left.Accept(p),
 		Right:    b.Right.Accept(p),
+		Span:     b.Span,
 	}
 }
 
@@ -181,19 +322,73 @@ func (p astPrinter) VisitUnary(u ast.Unary) any {
 		Type:     "Unary",
 		Operator: u.Operator.Lexeme,
 		Right:    u.Right.Accept(p),
+		Span:     u.Span,
 	}
 }
 
 func (p astPrinter) VisitLiteral(l ast.Literal) any {
-	// literals are terminal values and can be used directly in JSON
-	return l.Value
+	return literalExprJSON{
+		Type:  "Literal",
+		Value: l.Value,
+		Span:  l.Span,
+	}
 }
 
 func (p astPrinter) VisitGrouping(g ast.Grouping) any {
 	return groupingExprJSON{
 		Type:       "Grouping",
 		Expression: g.Expression.Accept(p),
+		Span:       g.Span,
+	}
+}
+
+func (p astPrinter) VisitInterpolation(interpolation ast.Interpolation) any {
+	parts := make([]any, 0, len(interpolation.Parts))
+	for _, part := range interpolation.Parts {
+		parts = append(parts, part.Accept(p))
+	}
+	return interpolationExprJSON{
+		Type:  "Interpolation",
+		Parts: parts,
+		Span:  interpolation.Span,
+	}
+}
+
+func (p astPrinter) VisitCall(call ast.Call) any {
+	arguments := make([]any, 0, len(call.Arguments))
+	for _, argument := range call.Arguments {
+		arguments = append(arguments, argument.Accept(p))
+	}
+	return callExprJSON{
+		Type:      "Call",
+		Callee:    call.Callee.Accept(p),
+		Arguments: arguments,
+		Span:      call.Span,
+	}
+}
+
+func (p astPrinter) VisitGet(get ast.Get) any {
+	return getExprJSON{
+		Type:   "Get",
+		Object: get.Object.Accept(p),
+		Name:   get.Name.Lexeme,
+		Span:   get.Span,
+	}
+}
+
+func (p astPrinter) VisitSet(set ast.Set) any {
+	expr := setExprJSON{
+		Type:   "Set",
+		Object: set.Object.Accept(p),
+		Name:   set.Name.Lexeme,
+		Value:  set.Value.Accept(p),
+		Span:   set.Span,
+	}
+	// NOTE: Only compound assignment operators are printed, a plain assignment is `=`.
+	if _, ok := token.CompoundAssignments[set.Operator.TokenType]; ok {
+		expr.Operator = set.Operator.Lexeme
 	}
+	return expr
 }
 
 // nilOrAccept returns nil if expr is nil, otherwise it continues
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"nilan/ast"
+	"nilan/lexer"
 	"nilan/token"
 	"os"
 	"path/filepath"
@@ -33,14 +35,14 @@ func TestPrintASTJSON_PrintLiteral(t *testing.T) {
 		t.Fatalf("expected type PrintStmt, got %v", node["type"])
 	}
 
-	expr := node["expression"]
+	expr := literalValue(node["expression"])
 	if num, ok := expr.(float64); !ok || num != 42 {
 		t.Fatalf("expected expression 42, got %v", expr)
 	}
 }
 
 func TestPrintASTJSON_VarStmt_NilInitializer(t *testing.T) {
-	name := token.CreateLiteralToken(token.IDENTIFIER, nil, "x", 0, 0)
+	name := token.CreateLiteralToken(token.IDENTIFIER, nil, "x")
 	stmts := []ast.Stmt{
 		ast.VarStmt{Name: name, Initializer: nil},
 	}
@@ -77,7 +79,7 @@ func TestPrintASTJSON_BinaryExpression(t *testing.T) {
 	stmts := []ast.Stmt{
 		ast.ExpressionStmt{Expression: ast.Binary{
 			Left:     ast.Literal{Value: 1},
-			Operator: token.CreateToken(token.ADD, 0, 0),
+			Operator: token.CreateToken(token.ADD),
 			Right:    ast.Literal{Value: 2},
 		}},
 	}
@@ -114,10 +116,10 @@ func TestPrintASTJSON_BinaryExpression(t *testing.T) {
 		t.Fatalf("expected operator '+', got %v", expr["operator"])
 	}
 
-	if left, ok := expr["left"].(float64); !ok || left != 1 {
+	if left, ok := literalValue(expr["left"]).(float64); !ok || left != 1 {
 		t.Fatalf("expected left 1, got %v", expr["left"])
 	}
-	if right, ok := expr["right"].(float64); !ok || right != 2 {
+	if right, ok := literalValue(expr["right"]).(float64); !ok || right != 2 {
 		t.Fatalf("expected right 2, got %v", expr["right"])
 	}
 }
@@ -153,7 +155,45 @@ func TestWriteASTJSONToFile(t *testing.T) {
 		t.Fatalf("expected type PrintStmt, got %v", node["type"])
 	}
 
-	if expr, ok := node["expression"].(string); !ok || expr != "hellow nilan!" {
+	if expr, ok := literalValue(node["expression"]).(string); !ok || expr != "hellow nilan!" {
 		t.Fatalf("expected expression 'hellow nilan!', got %v", node["expression"])
 	}
 }
+
+// literalValue returns the value of a Literal node decoded from JSON.
+func literalValue(node any) any {
+	literal, ok := node.(map[string]any)
+	if !ok || literal["type"] != "Literal" {
+		return nil
+	}
+	return literal["value"]
+}
+
+func TestPrintASTJSON_Spans(t *testing.T) {
+	tokens, lexErrs := lexer.New("var a = 1\nprint (a + 22) * -a").Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("lexing error: %v", lexErrs)
+	}
+	stmts, parseErrs := Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("parsing errors: %v", parseErrs)
+	}
+
+	jsonStr, err := PrintASTJSON(stmts)
+	if err != nil {
+		t.Fatalf("PrintASTJSON error: %v", err)
+	}
+	var out []map[string]any
+	if err := json.Unmarshal([]byte(jsonStr), &out); err != nil {
+		t.Fatalf("unmarshal json: %v", err)
+	}
+
+	span := out[1]["span"].(map[string]any)
+	want := map[string]any{
+		"start": map[string]any{"line": 2.0, "column": 1.0, "offset": 10.0},
+		"end":   map[string]any{"line": 2.0, "column": 20.0, "offset": 29.0},
+	}
+	if fmt.Sprint(span) != fmt.Sprint(want) {
+		t.Errorf("got span: %v, want: %v", span, want)
+	}
+}
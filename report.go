@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"nilan/diagnostics"
+)
+
+// The values accepted by the `-error-format` flag.
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+// validErrorFormat reports whether `format` is a value accepted by the `-error-format` flag.
+func validErrorFormat(format string) bool {
+	return format == errorFormatText || format == errorFormatJSON
+}
+
+// reportErrors writes the errors to `w` as diagnostics, rendered with the offending source line
+// or as a JSON array depending on `format`. Errors which can not describe themselves as a
+// diagnostic are reported with `code`.
+func reportErrors(w io.Writer, format string, file string, source string, code string, errs ...error) {
+	reported := make([]diagnostics.Diagnostic, 0, len(errs))
+	for _, err := range errs {
+		diagnostic := diagnostics.FromError(err)
+		var reporter diagnostics.Reporter
+		if !errors.As(err, &reporter) {
+			diagnostic.Code = code
+		}
+		reported = append(reported, diagnostic)
+	}
+
+	if format == errorFormatJSON {
+		out, err := diagnostics.JSON(file, reported)
+		if err != nil {
+			fmt.Fprintf(w, "💥 Failed to encode diagnostics: %v\n", err)
+			return
+		}
+		fmt.Fprintln(w, out)
+		return
+	}
+	fmt.Fprint(w, diagnostics.RenderAll(file, source, reported))
+}
@@ -0,0 +1,277 @@
+package stdlib
+
+// This file implements the JSON functions. JSON objects are converted to maps, which keep the
+// order of their keys, arrays to lists, integers to int64 (or arbitrary-precision integers when
+// they do not fit in one) and other numbers to float64.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"nilan/value"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxJSONDepth is the deepest nesting of arrays and objects `json_parse` and `json_stringify`
+// accept, it guards against deeply nested documents exhausting the stack of the host.
+const maxJSONDepth = 1000
+
+// maxJSONIndent is the largest number of spaces `json_stringify` indents with, like JavaScript's
+// `JSON.stringify`.
+const maxJSONIndent = 10
+
+// jsonFunctions are the JSON functions, which are globals.
+var jsonFunctions = []*value.NativeFunction{
+	{Name: "json_parse", MinArity: 1, MaxArity: 1, Function: jsonParse},
+	{Name: "json_stringify", MinArity: 1, MaxArity: 2, Function: jsonStringify},
+}
+
+// jsonParse converts a JSON document to Nilan values. Errors report the line and column of the
+// document they occur at.
+func jsonParse(args []any) (any, error) {
+	text, err := stringArgument("json_parse", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	parser := jsonParser{text: text, decoder: decoder}
+
+	result, err := parser.parseValue(0)
+	if err != nil {
+		return nil, err
+	}
+	end := decoder.InputOffset()
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		rest := text[end:]
+		end += int64(len(rest) - len(strings.TrimLeft(rest, " \t\r\n")))
+		return nil, parser.errorAt(end, "unexpected data after the JSON value")
+	}
+	return result, nil
+}
+
+// jsonParser reads the tokens of a JSON document and converts them to Nilan values.
+type jsonParser struct {
+	text    string
+	decoder *json.Decoder
+}
+
+// parseValue converts the next value of the document, at a nesting depth of `depth`.
+func (p *jsonParser) parseValue(depth int) (any, error) {
+	offset := p.decoder.InputOffset()
+	tok, err := p.decoder.Token()
+	if err != nil {
+		return nil, p.tokenError(offset, err)
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if depth >= maxJSONDepth {
+			return nil, p.errorAt(offset, fmt.Sprintf("nesting is deeper than %d levels", maxJSONDepth))
+		}
+		if t == '[' {
+			return p.parseArray(depth + 1)
+		}
+		return p.parseObject(depth + 1)
+	case json.Number:
+		return parseJSONNumber(t)
+	}
+	// NOTE: The remaining tokens are strings, bools and nil, which are Nilan values as they are.
+	return tok, nil
+}
+
+// parseArray converts the elements of an array, whose `[` was read, to a list.
+func (p *jsonParser) parseArray(depth int) (any, error) {
+	elements := []any{}
+	for p.decoder.More() {
+		element, err := p.parseValue(depth)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	if err := p.closing(); err != nil {
+		return nil, err
+	}
+	return &value.List{Elements: elements}, nil
+}
+
+// parseObject converts the members of an object, whose `{` was read, to a map.
+// If a key occurs more than once its last value is used.
+func (p *jsonParser) parseObject(depth int) (any, error) {
+	result := value.NewMap()
+	for p.decoder.More() {
+		offset := p.decoder.InputOffset()
+		tok, err := p.decoder.Token()
+		if err != nil {
+			return nil, p.tokenError(offset, err)
+		}
+		// NOTE: The decoder rejects object keys which are not strings.
+		key := tok.(string)
+		val, err := p.parseValue(depth)
+		if err != nil {
+			return nil, err
+		}
+		result.Set(key, val)
+	}
+	if err := p.closing(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// closing reads the `]` or `}` which closes an array or an object.
+func (p *jsonParser) closing() error {
+	offset := p.decoder.InputOffset()
+	if _, err := p.decoder.Token(); err != nil {
+		return p.tokenError(offset, err)
+	}
+	return nil
+}
+
+// tokenError converts an error returned by the decoder while reading the token at `offset`.
+func (p *jsonParser) tokenError(offset int64, err error) error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return p.errorAt(int64(len(p.text)), "unexpected end of JSON input")
+	case errors.As(err, &syntaxErr) && strings.HasPrefix(syntaxErr.Error(), "unexpected end"):
+		return p.errorAt(int64(len(p.text)), syntaxErr.Error())
+	case errors.As(err, &syntaxErr):
+		// NOTE: The offset of a syntax error is after the offending byte.
+		return p.errorAt(syntaxErr.Offset-1, syntaxErr.Error())
+	}
+	return p.errorAt(offset, err.Error())
+}
+
+// errorAt returns an error at the byte `offset` of the document, reported as a line and a column
+// counted in runes, both starting at 1.
+func (p *jsonParser) errorAt(offset int64, message string) error {
+	offset = min(max(offset, 0), int64(len(p.text)))
+	before := p.text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return fmt.Errorf("json_parse() %s at line %d, column %d", message, line, column)
+}
+
+// parseJSONNumber converts a JSON number to an integer if it has no fraction or exponent,
+// otherwise to a float.
+func parseJSONNumber(number json.Number) (any, error) {
+	text := number.String()
+	if !strings.ContainsAny(text, ".eE") {
+		n, ok := new(big.Int).SetString(text, 10)
+		if ok {
+			return normalizeInt(n), nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("json_parse() invalid number %s", text)
+	}
+	// NOTE: Numbers too large for a float64 are converted to an infinity, like Go and JavaScript do.
+	return f, nil
+}
+
+// jsonStringify converts a value to a JSON document, indented with a number of spaces if the
+// second argument is larger than 0. Keys of maps are written in insertion order.
+func jsonStringify(args []any) (any, error) {
+	indent := int64(0)
+	if len(args) == 2 {
+		var err error
+		if indent, err = intArgument("json_stringify", args, 1); err != nil {
+			return nil, err
+		}
+		if indent < 0 || indent > maxJSONIndent {
+			return nil, fmt.Errorf("json_stringify() indent must be between 0 and %d, got %d", maxJSONIndent, indent)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := writeJSON(&buffer, args[0], 0); err != nil {
+		return nil, err
+	}
+	if indent > 0 {
+		var indented bytes.Buffer
+		// NOTE: The compact document was written by writeJSON, so it is always valid.
+		_ = json.Indent(&indented, buffer.Bytes(), "", strings.Repeat(" ", int(indent)))
+		buffer = indented
+	}
+	if buffer.Len() > MaxStringLength {
+		return nil, fmt.Errorf("json_stringify() result is longer than %d bytes", MaxStringLength)
+	}
+	return buffer.String(), nil
+}
+
+// writeJSON writes the compact JSON encoding of a value nested at `depth`.
+func writeJSON(buffer *bytes.Buffer, val any, depth int) error {
+	if depth > maxJSONDepth {
+		return fmt.Errorf("json_stringify() nesting is deeper than %d levels", maxJSONDepth)
+	}
+	if buffer.Len() > MaxStringLength {
+		return fmt.Errorf("json_stringify() result is longer than %d bytes", MaxStringLength)
+	}
+	switch v := val.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		buffer.WriteString(strconv.FormatBool(v))
+	case int64:
+		buffer.WriteString(strconv.FormatInt(v, 10))
+	case *big.Int:
+		buffer.WriteString(v.String())
+	case value.Decimal:
+		// NOTE: Decimals are written with all their digits, so they are not rounded.
+		buffer.WriteString(v.String())
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("json_stringify() can not encode %v", v)
+		}
+		encoded, _ := json.Marshal(v)
+		buffer.Write(encoded)
+	case string:
+		writeJSONString(buffer, v)
+	case *value.List:
+		buffer.WriteByte('[')
+		for i, element := range v.Elements {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeJSON(buffer, element, depth+1); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case *value.Map:
+		buffer.WriteByte('{')
+		for i, key := range v.Keys() {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			writeJSONString(buffer, key)
+			buffer.WriteByte(':')
+			element, _ := v.Get(key)
+			if err := writeJSON(buffer, element, depth+1); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	default:
+		return fmt.Errorf("json_stringify() can not encode %s", describe(val))
+	}
+	return nil
+}
+
+// writeJSONString writes a string as a JSON string. Unlike json.Marshal, `<`, `>` and `&` are
+// not escaped, as the document is not meant to be embedded in HTML.
+func writeJSONString(buffer *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	// NOTE: Encode terminates the value with a newline.
+	buffer.Truncate(buffer.Len() - 1)
+}
@@ -0,0 +1,110 @@
+package stdlib
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJSONParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: `null`, want: "<nil> <nil>"},
+		{input: ` true `, want: "true bool"},
+		{input: `"hé\n"`, want: "\"hé\\n\" string"},
+		{input: `-12`, want: "-12 int64"},
+		{input: `100000000000000000000`, want: "100000000000000000000 *big.Int"},
+		{input: `1.5e3`, want: "1500 float64"},
+		{input: `1e400`, want: "+Inf float64"},
+		{input: `[]`, want: "[] *value.List"},
+		{input: `[1, "a", [null]]`, want: `[1, "a", [null]] *value.List`},
+		{input: `{"b": 1, "a": {"c": []}, "b": 2}`, want: `{"b": 2, "a": {"c": []}} *value.Map`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := call(t, "json_parse", tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			s := fmt.Sprintf("%v %T", got, got)
+			if str, ok := got.(string); ok {
+				s = fmt.Sprintf("%q %T", str, got)
+			}
+			if s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: ``, want: "json_parse() unexpected end of JSON input at line 1, column 1"},
+		{input: `[1, 2`, want: "json_parse() unexpected end of JSON input at line 1, column 6"},
+		{input: `{"a": tru}`, want: "json_parse() invalid character '}' in literal true (expecting 'e') at line 1, column 10"},
+		{input: "{\n  \"é\": x\n}", want: "json_parse() invalid character 'x' looking for beginning of value at line 2, column 8"},
+		{input: `{1: 2}`, want: "json_parse() object member name must be a string at line 1, column 2"},
+		{input: `1 2`, want: "json_parse() unexpected data after the JSON value at line 1, column 3"},
+		{input: strings.Repeat("[", maxJSONDepth+1), want: "json_parse() nesting is deeper than 1000 levels at line 1, column 1001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input[:min(len(tt.input), 20)], func(t *testing.T) {
+			_, err := call(t, "json_parse", tt.input)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error: %v, want: %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	parsed, err := call(t, "json_parse", `{"name": "a<b>", "values": [1, 2.5, true, null], "empty": {}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		args []any
+		want string
+	}{
+		{args: []any{parsed}, want: `{"name":"a<b>","values":[1,2.5,true,null],"empty":{}}`},
+		{args: []any{parsed, int64(2)}, want: "{\n  \"name\": \"a<b>\",\n  \"values\": [\n    1,\n    2.5,\n    true,\n    null\n  ],\n  \"empty\": {}\n}"},
+		{args: []any{decimal(t, "0.10")}, want: "0.10"},
+		{args: []any{1e21}, want: "1e+21"},
+		{args: []any{"tab\t\"quote\""}, want: `"tab\t\"quote\""`},
+	}
+
+	for _, tt := range tests {
+		got, err := call(t, "json_stringify", tt.args...)
+		if err != nil || got != tt.want {
+			t.Errorf("got %v, %v, want %s", got, err, tt.want)
+		}
+	}
+}
+
+func TestJSONStringifyErrors(t *testing.T) {
+	tests := []struct {
+		args []any
+		want string
+	}{
+		{args: []any{math.NaN()}, want: "json_stringify() can not encode NaN"},
+		{args: []any{math.Inf(1)}, want: "json_stringify() can not encode +Inf"},
+		{args: []any{lenFunction}, want: "json_stringify() can not encode <native fn len>"},
+		{args: []any{nil, int64(11)}, want: "json_stringify() indent must be between 0 and 10, got 11"},
+		{args: []any{nil, "  "}, want: "json_stringify() expects an integer, got '  '"},
+	}
+
+	for _, tt := range tests {
+		_, err := call(t, "json_stringify", tt.args...)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("got error: %v, want: %s", err, tt.want)
+		}
+	}
+}
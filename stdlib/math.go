@@ -0,0 +1,220 @@
+package stdlib
+
+// This file implements the math functions, which are global variables and members of the
+// `math` module, e.g `sqrt(2)` and `math.sqrt(2)` call the same function.
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"nilan/value"
+	"strconv"
+	"strings"
+)
+
+// mathFunctions are the functions of the `math` module.
+var mathFunctions = []*value.NativeFunction{
+	floatFunction("sqrt", math.Sqrt),
+	floatFunction("sin", math.Sin),
+	floatFunction("cos", math.Cos),
+	{Name: "pow", MinArity: 2, MaxArity: 2, Function: pow},
+	{Name: "abs", MinArity: 1, MaxArity: 1, Function: abs},
+	roundingFunction("floor", math.Floor, value.RoundFloor),
+	roundingFunction("ceil", math.Ceil, value.RoundCeiling),
+	{Name: "round", MinArity: 1, MaxArity: 2, Function: round},
+	{Name: "min", MinArity: 1, MaxArity: -1, Function: func(args []any) (any, error) { return extremum("min", -1, args) }},
+	{Name: "max", MinArity: 1, MaxArity: -1, Function: func(args []any) (any, error) { return extremum("max", 1, args) }},
+	{Name: "isinf", MinArity: 1, MaxArity: 1, Function: func(args []any) (any, error) { return classify("isinf", args[0], isInf) }},
+	{Name: "isnan", MinArity: 1, MaxArity: 1, Function: func(args []any) (any, error) { return classify("isnan", args[0], math.IsNaN) }},
+	{Name: "int", MinArity: 1, MaxArity: 1, Function: toInt},
+	{Name: "float", MinArity: 1, MaxArity: 1, Function: toFloatFunction},
+}
+
+// mathModule returns the `math` module, which contains the math functions and constants.
+func mathModule() *value.Module {
+	members := map[string]any{
+		"pi":  math.Pi,
+		"e":   math.E,
+		"inf": math.Inf(1),
+		"nan": math.NaN(),
+	}
+	for _, fn := range mathFunctions {
+		members[fn.Name] = fn
+	}
+	return &value.Module{Name: "math", Members: members}
+}
+
+// floatFunction returns a function of one number whose result is computed with floats.
+func floatFunction(name string, function func(float64) float64) *value.NativeFunction {
+	return &value.NativeFunction{Name: name, MinArity: 1, MaxArity: 1, Function: func(args []any) (any, error) {
+		x, ok := toFloat(args[0])
+		if !ok {
+			return nil, argumentError(name, "a number", args[0])
+		}
+		return function(x), nil
+	}}
+}
+
+func pow(args []any) (any, error) {
+	x, ok := toFloat(args[0])
+	if !ok {
+		return nil, argumentError("pow", "a number", args[0])
+	}
+	y, ok := toFloat(args[1])
+	if !ok {
+		return nil, argumentError("pow", "a number", args[1])
+	}
+	return math.Pow(x, y), nil
+}
+
+// abs returns the absolute value of a number, which has the type of the number.
+// The absolute value of the smallest int64 does not fit in an int64, so it is a *big.Int.
+func abs(args []any) (any, error) {
+	switch x := args[0].(type) {
+	case int64:
+		if x == math.MinInt64 {
+			return new(big.Int).Neg(big.NewInt(x)), nil
+		}
+		if x < 0 {
+			return -x, nil
+		}
+		return x, nil
+	case float64:
+		return math.Abs(x), nil
+	case *big.Int:
+		return normalizeInt(new(big.Int).Abs(x)), nil
+	case value.Decimal:
+		if x.Sign() < 0 {
+			return x.Neg(), nil
+		}
+		return x, nil
+	}
+	return nil, argumentError("abs", "a number", args[0])
+}
+
+// roundingFunction returns a function which rounds a number to an integer, using `function`
+// for floats and `rounding` for decimals.
+func roundingFunction(name string, function func(float64) float64, rounding value.Rounding) *value.NativeFunction {
+	return &value.NativeFunction{Name: name, MinArity: 1, MaxArity: 1, Function: func(args []any) (any, error) {
+		return roundToInt(name, args[0], function, rounding)
+	}}
+}
+
+// roundToInt rounds a number to an integer, integers are returned unchanged.
+func roundToInt(name string, x any, function func(float64) float64, rounding value.Rounding) (any, error) {
+	switch x := x.(type) {
+	case int64, *big.Int:
+		return x, nil
+	case float64:
+		return floatToInt(name, function(x))
+	case value.Decimal:
+		return normalizeInt(x.Round(0, rounding).Coefficient()), nil
+	}
+	return nil, argumentError(name, "a number", x)
+}
+
+// floatToInt converts a float without a fractional part to an integer.
+func floatToInt(name string, x float64) (any, error) {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil, fmt.Errorf("%s() can not convert %v to an integer", name, x)
+	}
+	if x >= -(1<<63) && x < 1<<63 {
+		return int64(x), nil
+	}
+	n, _ := big.NewFloat(x).Int(nil)
+	return n, nil
+}
+
+// round rounds a number to the nearest integer, or to a number of digits after the decimal point
+// if it is called with 2 arguments. Ties are rounded to the nearest even digit, e.g `round(2.5)`
+// is 2 and `round(0.125, 2)` is 0.12.
+func round(args []any) (any, error) {
+	if len(args) == 1 {
+		return roundToInt("round", args[0], math.RoundToEven, value.RoundHalfEven)
+	}
+
+	places, ok := args[1].(int64)
+	if !ok || places < 0 || places > value.MaxDecimalExponent {
+		return nil, argumentError("round", fmt.Sprintf("a number of places between 0 and %d", value.MaxDecimalExponent), args[1])
+	}
+	switch x := args[0].(type) {
+	case int64, *big.Int:
+		return x, nil
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return x, nil
+		}
+		// NOTE: The float is rounded as it is printed, e.g 2.675 is rounded to 2.68, even though
+		// the float nearest to 2.675 is slightly smaller.
+		decimal, err := value.ParseDecimal(strconv.FormatFloat(x, 'g', -1, 64))
+		if err != nil {
+			return nil, err
+		}
+		return decimal.Round(int32(places), value.RoundHalfEven).Float64(), nil
+	case value.Decimal:
+		return x.Round(int32(places), value.RoundHalfEven), nil
+	}
+	return nil, argumentError("round", "a number", args[0])
+}
+
+// extremum returns the smallest (sign -1) or the largest (sign +1) of its arguments, which must be
+// numbers. If an argument is NaN, the result is NaN.
+func extremum(name string, sign int, args []any) (any, error) {
+	result := args[0]
+	for _, arg := range args {
+		if !isNumber(arg) {
+			return nil, argumentError(name, "numbers", arg)
+		}
+		if f, ok := arg.(float64); ok && math.IsNaN(f) {
+			return f, nil
+		}
+		if cmp, _ := value.CompareNumbers(arg, result); cmp == sign {
+			result = arg
+		}
+	}
+	return result, nil
+}
+
+func isInf(x float64) bool {
+	return math.IsInf(x, 0)
+}
+
+// classify applies a check of floats to a number, integers and decimals are never infinite or NaN.
+func classify(name string, x any, check func(float64) bool) (any, error) {
+	if !isNumber(x) {
+		return nil, argumentError(name, "a number", x)
+	}
+	f, ok := x.(float64)
+	return ok && check(f), nil
+}
+
+// toInt converts a number or a string to an integer, floats and decimals are truncated towards zero.
+func toInt(args []any) (any, error) {
+	if s, ok := args[0].(string); ok {
+		n, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+		if !ok {
+			return nil, fmt.Errorf("int() can not convert '%s' to an integer", s)
+		}
+		return normalizeInt(n), nil
+	}
+	if !isNumber(args[0]) {
+		return nil, argumentError("int", "a number or a string", args[0])
+	}
+	return roundToInt("int", args[0], math.Trunc, value.RoundDown)
+}
+
+// toFloatFunction converts a number or a string to a float.
+func toFloatFunction(args []any) (any, error) {
+	if s, ok := args[0].(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("float() can not convert '%s' to a float", s)
+		}
+		return f, nil
+	}
+	f, ok := toFloat(args[0])
+	if !ok {
+		return nil, argumentError("float", "a number or a string", args[0])
+	}
+	return f, nil
+}
@@ -0,0 +1,135 @@
+package stdlib
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"nilan/value"
+	"testing"
+)
+
+// call calls the global function `name` of the standard library.
+func call(t *testing.T, name string, args ...any) (any, error) {
+	t.Helper()
+	fn, ok := Globals(nil)[name].(*value.NativeFunction)
+	if !ok {
+		t.Fatalf("%s is not a global function", name)
+	}
+	return fn.Call(args)
+}
+
+func decimal(t *testing.T, text string) value.Decimal {
+	t.Helper()
+	d, err := value.ParseDecimal(text)
+	if err != nil {
+		t.Fatalf("invalid decimal %s: %v", text, err)
+	}
+	return d
+}
+
+func TestMathFunctions(t *testing.T) {
+	huge, _ := new(big.Int).SetString("100000000000000000000", 10)
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{name: "sqrt", args: []any{int64(2)}, want: "1.4142135623730951 float64"},
+		{name: "sqrt", args: []any{decimal(t, "6.25")}, want: "2.5 float64"},
+		{name: "pow", args: []any{int64(2), 0.5}, want: "1.4142135623730951 float64"},
+		{name: "abs", args: []any{int64(-3)}, want: "3 int64"},
+		{name: "abs", args: []any{int64(math.MinInt64)}, want: "9223372036854775808 *big.Int"},
+		{name: "abs", args: []any{math.Inf(-1)}, want: "+Inf float64"},
+		{name: "abs", args: []any{decimal(t, "-1.50")}, want: "1.50 value.Decimal"},
+		{name: "floor", args: []any{-2.5}, want: "-3 int64"},
+		{name: "floor", args: []any{decimal(t, "-2.5")}, want: "-3 int64"},
+		{name: "floor", args: []any{1e20}, want: "100000000000000000000 *big.Int"},
+		{name: "ceil", args: []any{2.1}, want: "3 int64"},
+		{name: "ceil", args: []any{huge}, want: "100000000000000000000 *big.Int"},
+		{name: "round", args: []any{2.5}, want: "2 int64"},
+		{name: "round", args: []any{-3.5}, want: "-4 int64"},
+		{name: "round", args: []any{2.675, int64(2)}, want: "2.68 float64"},
+		{name: "round", args: []any{0.125, int64(2)}, want: "0.12 float64"},
+		{name: "round", args: []any{decimal(t, "2.5"), int64(3)}, want: "2.500 value.Decimal"},
+		{name: "round", args: []any{int64(7), int64(2)}, want: "7 int64"},
+		{name: "min", args: []any{int64(3), 1.5, int64(2)}, want: "1.5 float64"},
+		{name: "min", args: []any{int64(1), math.NaN()}, want: "NaN float64"},
+		{name: "max", args: []any{int64(1), huge, decimal(t, "2.5")}, want: "100000000000000000000 *big.Int"},
+		{name: "max", args: []any{int64(1), math.Inf(1)}, want: "+Inf float64"},
+		{name: "max", args: []any{int64(2), 2.0}, want: "2 int64"},
+		{name: "isinf", args: []any{math.Inf(-1)}, want: "true bool"},
+		{name: "isinf", args: []any{huge}, want: "false bool"},
+		{name: "isnan", args: []any{math.NaN()}, want: "true bool"},
+		{name: "int", args: []any{-2.9}, want: "-2 int64"},
+		{name: "int", args: []any{decimal(t, "-2.9")}, want: "-2 int64"},
+		{name: "int", args: []any{" 42 "}, want: "42 int64"},
+		{name: "int", args: []any{"100000000000000000000"}, want: "100000000000000000000 *big.Int"},
+		{name: "float", args: []any{int64(3)}, want: "3 float64"},
+		{name: "float", args: []any{"1.5e3"}, want: "1500 float64"},
+		{name: "float", args: []any{decimal(t, "0.1")}, want: "0.1 float64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%v", tt.name, tt.args), func(t *testing.T) {
+			got, err := call(t, tt.name, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s := fmt.Sprintf("%v %T", got, got); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestMathFunctionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{name: "sqrt", args: []any{}, want: "sqrt() takes 1 argument, got 0"},
+		{name: "pow", args: []any{int64(1)}, want: "pow() takes 2 arguments, got 1"},
+		{name: "round", args: []any{}, want: "round() takes 1 or 2 arguments, got 0"},
+		{name: "min", args: []any{}, want: "min() takes at least 1 argument, got 0"},
+		{name: "sqrt", args: []any{"4"}, want: "sqrt() expects a number, got '4'"},
+		{name: "abs", args: []any{nil}, want: "abs() expects a number, got null"},
+		{name: "max", args: []any{int64(1), true}, want: "max() expects numbers, got true"},
+		{name: "round", args: []any{1.5, int64(-1)}, want: "round() expects a number of places between 0 and 1000, got -1"},
+		{name: "round", args: []any{1.5, 1.0}, want: "round() expects a number of places between 0 and 1000, got 1"},
+		{name: "floor", args: []any{math.NaN()}, want: "floor() can not convert NaN to an integer"},
+		{name: "int", args: []any{"1.5"}, want: "int() can not convert '1.5' to an integer"},
+		{name: "int", args: []any{false}, want: "int() expects a number or a string, got false"},
+		{name: "float", args: []any{"abc"}, want: "float() can not convert 'abc' to a float"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s%v", tt.name, tt.args), func(t *testing.T) {
+			_, err := call(t, tt.name, tt.args...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error: %v, want: %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMathModule(t *testing.T) {
+	module, ok := Globals(nil)["math"].(*value.Module)
+	if !ok {
+		t.Fatalf("math is not a module")
+	}
+	for _, fn := range mathFunctions {
+		if member, err := module.Member(fn.Name); err != nil || member != fn {
+			t.Errorf("got member %v, %v for %s, want the global function", member, err, fn.Name)
+		}
+		if !IsGlobal(fn.Name) {
+			t.Errorf("expected %s to be a global", fn.Name)
+		}
+	}
+	if pi, _ := module.Member("pi"); pi != math.Pi {
+		t.Errorf("got pi: %v", pi)
+	}
+	if IsGlobal("pi") || !IsGlobal("math") {
+		t.Errorf("expected only math to be a global, not its constants")
+	}
+}
@@ -0,0 +1,101 @@
+// Package stdlib implements the standard library of Nilan: native functions, such as `sqrt`,
+// and modules, such as `math`, which are available to every program. They are shared by the VM
+// and the tree-walk interpreter.
+package stdlib
+
+import (
+	"fmt"
+	"math/big"
+	"nilan/value"
+)
+
+// Globals returns the global variables defined by the standard library, by name: its modules
+// and the functions which can be called without a module, such as `sqrt` and `len`.
+// The system functions, such as `read_file`, use the arguments, input and capabilities of `host`,
+// which is read on every call so it can be changed later. A nil host denies every capability.
+// A new map is returned on every call, so it can be modified by the caller.
+func Globals(host *Host) map[string]any {
+	if host == nil {
+		host = &Host{}
+	}
+	globals := map[string]any{}
+	for _, module := range []*value.Module{mathModule(), stringModule()} {
+		globals[module.Name] = module
+	}
+	for _, fn := range mathFunctions {
+		globals[fn.Name] = fn
+	}
+	for _, fn := range jsonFunctions {
+		globals[fn.Name] = fn
+	}
+	for _, fn := range systemFunctions(host) {
+		globals[fn.Name] = fn
+	}
+	globals[lenFunction.Name] = lenFunction
+	return globals
+}
+
+// IsGlobal determines if `name` is a global variable defined by the standard library.
+func IsGlobal(name string) bool {
+	_, ok := Globals(nil)[name]
+	return ok
+}
+
+// describe formats a value for error messages, quoting strings and using Nilan's `null`
+// instead of Go's `<nil>`.
+func describe(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("'%s'", v)
+	}
+	return fmt.Sprint(val)
+}
+
+// toString converts a value to the text printed for it, `null` for a nil value.
+func toString(val any) string {
+	if val == nil {
+		return "null"
+	}
+	return fmt.Sprint(val)
+}
+
+// argumentError returns the error of a call of `fn` with an argument of the wrong type.
+func argumentError(fn string, expected string, arg any) error {
+	return fmt.Errorf("%s() expects %s, got %s", fn, expected, describe(arg))
+}
+
+// normalizeInt returns an integer as an int64 if it fits in one, otherwise as a *big.Int.
+func normalizeInt(n *big.Int) any {
+	if n.IsInt64() {
+		return n.Int64()
+	}
+	return n
+}
+
+// isNumber determines if a value is an int64, a float64, a *big.Int or a decimal.
+func isNumber(val any) bool {
+	switch val.(type) {
+	case int64, float64, *big.Int, value.Decimal:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a number to a float64. Integers and decimals too large for a float64 are
+// converted to an infinity.
+func toFloat(val any) (float64, bool) {
+	switch v := val.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f, true
+	case value.Decimal:
+		return v.Float64(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,302 @@
+package stdlib
+
+// This file implements the `string` module. Strings are indexed by runes, not bytes, e.g
+// `len("héllo")` is 5 and `string.substring("héllo", 1, 2)` is "é".
+
+import (
+	"fmt"
+	"nilan/value"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxStringLength is the largest number of bytes of a string built by the string functions,
+// string interpolation or concatenation, it guards against programs such as
+// `string.repeat("a", 10000000000)` exhausting the memory of the host.
+const MaxStringLength = 1 << 24
+
+// lenFunction returns the number of runes of a string, the number of elements of a list or the
+// number of keys of a map.
+// It is a global and a member of the `string` module.
+var lenFunction = &value.NativeFunction{Name: "len", MinArity: 1, MaxArity: 1, Function: func(args []any) (any, error) {
+	switch x := args[0].(type) {
+	case string:
+		return int64(utf8.RuneCountInString(x)), nil
+	case *value.List:
+		return int64(len(x.Elements)), nil
+	case *value.Map:
+		return int64(x.Len()), nil
+	}
+	return nil, argumentError("len", "a string, a list or a map", args[0])
+}}
+
+// stringFunctions are the functions of the `string` module.
+var stringFunctions = []*value.NativeFunction{
+	lenFunction,
+	{Name: "split", MinArity: 2, MaxArity: 2, Function: split},
+	{Name: "join", MinArity: 2, MaxArity: 2, Function: join},
+	{Name: "trim", MinArity: 1, MaxArity: 2, Function: trim},
+	stringFunction("upper", strings.ToUpper),
+	stringFunction("lower", strings.ToLower),
+	predicateFunction("contains", strings.Contains),
+	predicateFunction("starts_with", strings.HasPrefix),
+	predicateFunction("ends_with", strings.HasSuffix),
+	{Name: "replace", MinArity: 3, MaxArity: 3, Function: replace},
+	{Name: "substring", MinArity: 2, MaxArity: 3, Function: substring},
+	{Name: "index_of", MinArity: 2, MaxArity: 2, Function: indexOf},
+	{Name: "repeat", MinArity: 2, MaxArity: 2, Function: repeat},
+	{Name: "format", MinArity: 1, MaxArity: -1, Function: format},
+}
+
+// stringModule returns the `string` module, which contains the string functions.
+func stringModule() *value.Module {
+	members := map[string]any{}
+	for _, fn := range stringFunctions {
+		members[fn.Name] = fn
+	}
+	return &value.Module{Name: "string", Members: members}
+}
+
+// stringArgument returns the argument at `index` of a call of `fn`, which must be a string.
+func stringArgument(fn string, args []any, index int) (string, error) {
+	s, ok := args[index].(string)
+	if !ok {
+		return "", argumentError(fn, "a string", args[index])
+	}
+	return s, nil
+}
+
+// intArgument returns the argument at `index` of a call of `fn`, which must be an integer.
+func intArgument(fn string, args []any, index int) (int64, error) {
+	n, ok := args[index].(int64)
+	if !ok {
+		return 0, argumentError(fn, "an integer", args[index])
+	}
+	return n, nil
+}
+
+// stringFunction returns a function which maps a string to a string.
+func stringFunction(name string, function func(string) string) *value.NativeFunction {
+	return &value.NativeFunction{Name: name, MinArity: 1, MaxArity: 1, Function: func(args []any) (any, error) {
+		s, err := stringArgument(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return function(s), nil
+	}}
+}
+
+// predicateFunction returns a function which checks a property of two strings.
+func predicateFunction(name string, function func(string, string) bool) *value.NativeFunction {
+	return &value.NativeFunction{Name: name, MinArity: 2, MaxArity: 2, Function: func(args []any) (any, error) {
+		s, err := stringArgument(name, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		other, err := stringArgument(name, args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return function(s, other), nil
+	}}
+}
+
+// split splits a string around each occurrence of a separator and returns a list of the parts.
+// An empty separator splits the string into its runes.
+func split(args []any) (any, error) {
+	s, err := stringArgument("split", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := stringArgument("split", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(s, separator)
+	elements := make([]any, len(parts))
+	for i, part := range parts {
+		elements[i] = part
+	}
+	return &value.List{Elements: elements}, nil
+}
+
+// join concatenates the elements of a list with a separator between them. The elements are
+// converted to strings the same way `print` does.
+func join(args []any) (any, error) {
+	list, ok := args[0].(*value.List)
+	if !ok {
+		return nil, argumentError("join", "a list", args[0])
+	}
+	separator, err := stringArgument("join", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(list.Elements))
+	length := 0
+	for i, element := range list.Elements {
+		parts[i] = toString(element)
+		length += len(parts[i]) + len(separator)
+	}
+	if length > MaxStringLength {
+		return nil, fmt.Errorf("join() result is longer than %d bytes", MaxStringLength)
+	}
+	return strings.Join(parts, separator), nil
+}
+
+// trim removes the whitespace, or the runes of its second argument, from both ends of a string.
+func trim(args []any) (any, error) {
+	s, err := stringArgument("trim", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 {
+		return strings.TrimSpace(s), nil
+	}
+	cutset, err := stringArgument("trim", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Trim(s, cutset), nil
+}
+
+// replace replaces every occurrence of a string with another string.
+func replace(args []any) (any, error) {
+	var strs [3]string
+	for i := range strs {
+		s, err := stringArgument("replace", args, i)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = s
+	}
+	s, old, replacement := strs[0], strs[1], strs[2]
+	// NOTE: An empty string occurs before every rune and at the end of the string.
+	occurrences := utf8.RuneCountInString(s) + 1
+	if old != "" {
+		occurrences = strings.Count(s, old)
+	}
+	if len(s)+occurrences*(len(replacement)-len(old)) > MaxStringLength {
+		return nil, fmt.Errorf("replace() result is longer than %d bytes", MaxStringLength)
+	}
+	return strings.ReplaceAll(s, old, replacement), nil
+}
+
+// substring returns the runes of a string from the index `start` up to, but not including, the
+// index `end`, which defaults to the length of the string. Negative indices count from the end
+// of the string and indices out of range are clamped, like slices in Python, e.g
+// `string.substring("hello", -3)` is "llo".
+func substring(args []any) (any, error) {
+	s, err := stringArgument("substring", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(s)
+	start, err := intArgument("substring", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	end := int64(len(runes))
+	if len(args) == 3 {
+		if end, err = intArgument("substring", args, 2); err != nil {
+			return nil, err
+		}
+	}
+	start, end = clampIndex(start, len(runes)), clampIndex(end, len(runes))
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
+
+// clampIndex converts an index which is negative when it counts from the end to an index
+// between 0 and `length`.
+func clampIndex(index int64, length int) int64 {
+	if index < 0 {
+		index += int64(length)
+	}
+	return min(max(index, 0), int64(length))
+}
+
+// indexOf returns the rune index of the first occurrence of a string, or -1 if it does not occur.
+func indexOf(args []any) (any, error) {
+	s, err := stringArgument("index_of", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := stringArgument("index_of", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	index := strings.Index(s, sub)
+	if index < 0 {
+		return int64(-1), nil
+	}
+	return int64(utf8.RuneCountInString(s[:index])), nil
+}
+
+// repeat returns a string repeated a number of times.
+func repeat(args []any) (any, error) {
+	s, err := stringArgument("repeat", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	count, err := intArgument("repeat", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("repeat() count must not be negative, got %d", count)
+	}
+	if len(s) > 0 && count > MaxStringLength/int64(len(s)) {
+		return nil, fmt.Errorf("repeat() result is longer than %d bytes", MaxStringLength)
+	}
+	return strings.Repeat(s, int(count)), nil
+}
+
+// format replaces the placeholders of a string with its other arguments, converted to strings
+// the same way `print` does. `{}` is replaced with the next argument and `{0}` with the argument
+// at an index, `{{` and `}}` are written as `{` and `}`, e.g `string.format("{} + {0} = {}", 1, 2)`
+// is "1 + 1 = 2".
+func format(args []any) (any, error) {
+	template, err := stringArgument("format", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	values := args[1:]
+
+	var builder strings.Builder
+	next := 0
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && strings.HasPrefix(template[i:], "{{"), c == '}' && strings.HasPrefix(template[i:], "}}"):
+			builder.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("format() placeholder at index %d is not closed, use '{{' to write '{'", i)
+			}
+			index := next
+			if placeholder := template[i+1 : i+end]; placeholder == "" {
+				next++
+			} else if index, err = strconv.Atoi(placeholder); err != nil || index < 0 {
+				return nil, fmt.Errorf("format() placeholder '{%s}' must be empty or an index", placeholder)
+			}
+			if index >= len(values) {
+				return nil, fmt.Errorf("format() placeholder %d has no argument, got %d arguments", index, len(values))
+			}
+			builder.WriteString(toString(values[index]))
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("format() '}' at index %d is not part of a placeholder, use '}}' to write '}'", i)
+		default:
+			builder.WriteByte(c)
+		}
+		if builder.Len() > MaxStringLength {
+			return nil, fmt.Errorf("format() result is longer than %d bytes", MaxStringLength)
+		}
+	}
+	return builder.String(), nil
+}
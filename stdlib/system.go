@@ -0,0 +1,165 @@
+package stdlib
+
+// This file implements the system functions, which give programs access to the world outside of
+// Nilan: files, the standard input, the arguments of the program, the environment variables and
+// its exit code. Programs are sandboxed, the file system, the standard input and the environment
+// variables can only be accessed if the host embedding Nilan grants the capability to do so.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"nilan/value"
+	"os"
+	"strings"
+)
+
+// Host is the environment a program runs in, provided by the host embedding Nilan.
+// The zero value denies every capability, has no arguments and no standard input.
+type Host struct {
+	// Args are the arguments of the program, returned by `args()`.
+	Args []string
+	// Stdin is the input `read_line()` reads from. A nil reader has no lines.
+	Stdin io.Reader
+	// AllowStdin grants the capability to read lines of `Stdin` with `read_line`.
+	AllowStdin bool
+	// AllowFS grants the capability to read and write files with `read_file` and `write_file`.
+	AllowFS bool
+	// AllowEnv grants the capability to read environment variables with `env`.
+	AllowEnv bool
+
+	// stdin buffers `Stdin` so lines can be read one at a time, it is created by the first `read_line()`.
+	stdin *bufio.Reader
+}
+
+// ExitError is returned by the `exit` function to stop the program with an exit code.
+// It is not a runtime error, so it can not be caught by the program.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit(%d)", e.Code)
+}
+
+// systemFunctions returns the system functions, which use the arguments, input and capabilities of `host`.
+func systemFunctions(host *Host) []*value.NativeFunction {
+	return []*value.NativeFunction{
+		{Name: "read_file", MinArity: 1, MaxArity: 1, Function: host.readFile},
+		{Name: "write_file", MinArity: 2, MaxArity: 2, Function: host.writeFile},
+		{Name: "read_line", MinArity: 0, MaxArity: 0, Function: host.readLine},
+		{Name: "args", MinArity: 0, MaxArity: 0, Function: host.args},
+		{Name: "env", MinArity: 1, MaxArity: 1, Function: host.env},
+		{Name: "exit", MinArity: 0, MaxArity: 1, Function: exit},
+	}
+}
+
+// capabilityError returns the error of a call of `fn` which needs a capability the host did not grant.
+func capabilityError(fn string, capability string, flag string) error {
+	return fmt.Errorf("%s() is not allowed to access %s, the host must grant it (e.g with %s)", fn, capability, flag)
+}
+
+// readFile returns the contents of a file.
+func (host *Host) readFile(args []any) (any, error) {
+	if !host.AllowFS {
+		return nil, capabilityError("read_file", "the file system", "--allow-fs")
+	}
+	path, err := stringArgument("read_file", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read_file() failed: %v", err)
+	}
+	if info.Size() > MaxStringLength {
+		return nil, fmt.Errorf("read_file() file '%s' is longer than %d bytes", path, MaxStringLength)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read_file() failed: %v", err)
+	}
+	return string(data), nil
+}
+
+// writeFile writes a string to a file, replacing its contents. The file is created if it does not exist.
+func (host *Host) writeFile(args []any) (any, error) {
+	if !host.AllowFS {
+		return nil, capabilityError("write_file", "the file system", "--allow-fs")
+	}
+	path, err := stringArgument("write_file", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := stringArgument("write_file", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		return nil, fmt.Errorf("write_file() failed: %v", err)
+	}
+	return nil, nil
+}
+
+// readLine returns the next line of the standard input without its line ending,
+// or null once the input is exhausted.
+func (host *Host) readLine(args []any) (any, error) {
+	if !host.AllowStdin {
+		return nil, capabilityError("read_line", "the standard input", "--allow-stdin")
+	}
+	if host.Stdin == nil {
+		return nil, nil
+	}
+	if host.stdin == nil {
+		host.stdin = bufio.NewReader(host.Stdin)
+	}
+	line, err := host.stdin.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read_line() failed: %v", err)
+	}
+	if err != nil && line == "" {
+		return nil, nil
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// args returns the arguments of the program as a list of strings.
+func (host *Host) args(args []any) (any, error) {
+	elements := make([]any, len(host.Args))
+	for i, arg := range host.Args {
+		elements[i] = arg
+	}
+	return &value.List{Elements: elements}, nil
+}
+
+// env returns the value of an environment variable, or null if it is not set.
+func (host *Host) env(args []any) (any, error) {
+	if !host.AllowEnv {
+		return nil, capabilityError("env", "the environment variables", "--allow-env")
+	}
+	name, err := stringArgument("env", args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if val, ok := os.LookupEnv(name); ok {
+		return val, nil
+	}
+	return nil, nil
+}
+
+// exit stops the program with an exit code between 0 and 255, which defaults to 0.
+func exit(args []any) (any, error) {
+	code := int64(0)
+	if len(args) == 1 {
+		var err error
+		if code, err = intArgument("exit", args, 0); err != nil {
+			return nil, err
+		}
+	}
+	if code < 0 || code > 255 {
+		return nil, fmt.Errorf("exit() code must be between 0 and 255, got %d", code)
+	}
+	return nil, ExitError{Code: int(code)}
+}
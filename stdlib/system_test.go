@@ -0,0 +1,118 @@
+package stdlib
+
+import (
+	"nilan/value"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// callWith calls the global function `name` of the standard library with the given host.
+func callWith(t *testing.T, host *Host, name string, args ...any) (any, error) {
+	t.Helper()
+	fn, ok := Globals(host)[name].(*value.NativeFunction)
+	if !ok {
+		t.Fatalf("%s is not a global function", name)
+	}
+	return fn.Call(args)
+}
+
+func TestCapabilitiesAreDeniedByDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{name: "read_file", args: []any{"a.txt"}, want: "read_file() is not allowed to access the file system, the host must grant it (e.g with --allow-fs)"},
+		{name: "write_file", args: []any{"a.txt", "a"}, want: "write_file() is not allowed to access the file system, the host must grant it (e.g with --allow-fs)"},
+		{name: "read_line", args: []any{}, want: "read_line() is not allowed to access the standard input, the host must grant it (e.g with --allow-stdin)"},
+		{name: "env", args: []any{"HOME"}, want: "env() is not allowed to access the environment variables, the host must grant it (e.g with --allow-env)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := call(t, tt.name, tt.args...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error: %v, want: %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	host := &Host{AllowFS: true}
+	path := filepath.Join(t.TempDir(), "notes.txt")
+
+	if result, err := callWith(t, host, "write_file", path, "héllo\n"); err != nil || result != nil {
+		t.Fatalf("got %v, %v, want null", result, err)
+	}
+	if got, err := callWith(t, host, "read_file", path); err != nil || got != "héllo\n" {
+		t.Errorf("got %q, %v, want the written contents", got, err)
+	}
+
+	_, err := callWith(t, host, "read_file", filepath.Join(t.TempDir(), "missing.txt"))
+	if err == nil || !strings.HasPrefix(err.Error(), "read_file() failed: ") {
+		t.Errorf("got error: %v, want read_file() to fail", err)
+	}
+	_, err = callWith(t, host, "write_file", path, int64(1))
+	if err == nil || err.Error() != "write_file() expects a string, got 1" {
+		t.Errorf("got error: %v", err)
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	host := &Host{Stdin: strings.NewReader("first\r\nsecond\nlast"), AllowStdin: true}
+	for _, want := range []any{"first", "second", "last", nil, nil} {
+		if got, err := callWith(t, host, "read_line"); err != nil || got != want {
+			t.Errorf("got %v, %v, want %v", got, err, want)
+		}
+	}
+
+	if got, err := callWith(t, &Host{AllowStdin: true}, "read_line"); err != nil || got != nil {
+		t.Errorf("got %v, %v, want null without an input", got, err)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	got, err := callWith(t, &Host{Args: []string{"a", "b c"}}, "args")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := got.(*value.List).String(); s != `["a", "b c"]` {
+		t.Errorf("got %s", s)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("NILAN_TEST_ENV", "value")
+	os.Unsetenv("NILAN_TEST_UNSET")
+	host := &Host{AllowEnv: true}
+
+	if got, err := callWith(t, host, "env", "NILAN_TEST_ENV"); err != nil || got != "value" {
+		t.Errorf("got %v, %v, want value", got, err)
+	}
+	if got, err := callWith(t, host, "env", "NILAN_TEST_UNSET"); err != nil || got != nil {
+		t.Errorf("got %v, %v, want null", got, err)
+	}
+}
+
+func TestExit(t *testing.T) {
+	tests := []struct {
+		args []any
+		want error
+	}{
+		{args: []any{}, want: ExitError{Code: 0}},
+		{args: []any{int64(3)}, want: ExitError{Code: 3}},
+	}
+	for _, tt := range tests {
+		if _, err := call(t, "exit", tt.args...); err != tt.want {
+			t.Errorf("got error: %v, want: %v", err, tt.want)
+		}
+	}
+
+	_, err := call(t, "exit", int64(-1))
+	if err == nil || err.Error() != "exit() code must be between 0 and 255, got -1" {
+		t.Errorf("got error: %v", err)
+	}
+}
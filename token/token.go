@@ -10,6 +10,7 @@ const (
 	LPA       = "("
 	RPA       = ")"
 	COMMA     = ","
+	DOT       = "."
 	SEMICOLON = ";"
 	RCUR      = "}"
 	LCUR      = "{"
@@ -17,6 +18,8 @@ const (
 	// naming given by programmer i.e myVar, myFunc, add ..ect
 	IDENTIFIER = "IDENTIFIER"
 	STRING     = "STRING"
+	// the text of a string before an interpolated expression, e.g `"Hello ${` in `"Hello ${name}"`.
+	INTERPOLATION = "INTERPOLATION"
 
 	// operators
 	ASSIGN       = "="
@@ -31,9 +34,29 @@ const (
 	LESS_EQUAL   = "<="
 	LARGER       = ">"
 	LARGER_EQUAL = ">="
+	MOD          = "%"
+	POW          = "**"
+	FLOOR_DIV    = "//"
+	BIT_AND      = "&"
+	BIT_OR       = "|"
+	BIT_XOR      = "^"
+	BIT_NOT      = "~"
+	SHIFT_LEFT   = "<<"
+	SHIFT_RIGHT  = ">>"
+
+	// compound assignment operators
+	ADD_ASSIGN  = "+="
+	SUB_ASSIGN  = "-="
+	MULT_ASSIGN = "*="
+	DIV_ASSIGN  = "/="
 
 	FLOAT = "FLOAT"
 	INT   = "INT"
+	// a decimal number with a `d` suffix, e.g `12.50d`.
+	DECIMAL = "DECIMAL"
+
+	// a `#` comment, which is kept as trivia by the lexer and is not passed to the parser.
+	COMMENT = "COMMENT"
 
 	EOF = "EOF"
 
@@ -54,6 +77,19 @@ const (
 	FALSE  = "FALSE"
 	NULL   = "NULL"
 	PRINT  = "PRINT"
+
+	// exception handling keywords
+	TRY     = "TRY"
+	CATCH   = "CATCH"
+	FINALLY = "FINALLY"
+	THROW   = "THROW"
+
+	// module keywords
+	IMPORT = "IMPORT"
+	AS     = "AS"
+
+	// declares a struct, e.g `struct Point { x, y }`.
+	STRUCT = "STRUCT"
 )
 
 // KeyWords maps reserved keyword strings in Nilan to their
@@ -88,6 +124,16 @@ var KeyWords = map[string]TokenType{
 	"true":   TRUE,
 	"null":   NULL,
 	"print":  PRINT,
+
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"throw":   THROW,
+
+	"import": IMPORT,
+	"as":     AS,
+
+	"struct": STRUCT,
 }
 
 // tokenTypes maps single and multi-character symbols in Nilan
@@ -106,6 +152,7 @@ var tokenTypes = map[TokenType]string{
 	"}":   RCUR,
 	";":   SEMICOLON,
 	",":   COMMA,
+	".":   DOT,
 	"=":   ASSIGN,
 	"*":   MULT,
 	"+":   ADD,
@@ -118,6 +165,28 @@ var tokenTypes = map[TokenType]string{
 	">":   LARGER,
 	">=":  LARGER_EQUAL,
 	"==":  EQUAL_EQUAL,
+	"%":   MOD,
+	"**":  POW,
+	"//":  FLOOR_DIV,
+	"&":   BIT_AND,
+	"|":   BIT_OR,
+	"^":   BIT_XOR,
+	"~":   BIT_NOT,
+	"<<":  SHIFT_LEFT,
+	">>":  SHIFT_RIGHT,
+	"+=":  ADD_ASSIGN,
+	"-=":  SUB_ASSIGN,
+	"*=":  MULT_ASSIGN,
+	"/=":  DIV_ASSIGN,
+}
+
+// CompoundAssignments maps the compound assignment operators to the binary operator
+// they apply to the variable before assigning it, e.g `a += 1` assigns `a + 1`.
+var CompoundAssignments = map[TokenType]TokenType{
+	ADD_ASSIGN:  ADD,
+	SUB_ASSIGN:  SUB,
+	MULT_ASSIGN: MULT,
+	DIV_ASSIGN:  DIV,
 }
 
 type TokenType string
@@ -135,41 +204,52 @@ type TokenType string
 //   - Literal: The interpreted value of the token, if applicable.
 //     For example, a number token might have an integer or float value here.
 //     This is stored as `any`.
-//   - Line: The source line (o-based index) where the token appears.
-//   - Column: The character position (0-based index) within the line where
-//     the token starts.
+//   - Start: The position of the token's first character.
+//   - End: The position right after the token's last character.
 type Token struct {
 	TokenType TokenType
 	Lexeme    string
 	Literal   any
-	Line      int32
-	Column    int
+	Start     Position
+	End       Position
+}
+
+// Position is a location in the source code, set by the lexer on every token.
+//
+// Fields:
+//   - Line: The 1-based line number.
+//   - Column: The 1-based column within the line, counted in characters (runes).
+//   - Offset: The 0-based byte offset from the start of the source code.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 // CreateToken constructs and returns a new Token instance for the given
-// token type and position within the source code.
+// token type. Its position is set by the lexer once the token is scanned.
 //
 // Parameters:
 //   - tokenType: The classification of the token to create.
-//   - line:      The 0-based line number where the token begins.
-//   - column:    The 0-based column number where the token begins.
 //
 // Returns:
 //
-//	A Token with the specified type, position, and an empty Literal value.
+//	A Token with the specified type and an empty Literal value.
 //
 // Note:
 //
 //	The `tokenTypes` map must contain an entry for the given tokenType;
 //	otherwise, the Lexeme will be an empty string.
-func CreateToken(tokenType TokenType, line int32, column int) Token {
+func CreateToken(tokenType TokenType) Token {
 	lexeme := tokenTypes[tokenType]
 	return Token{
 		TokenType: tokenType,
 		Lexeme:    lexeme,
 		Literal:   nil,
-		Line:      line,
-		Column:    column,
 	}
 }
 
@@ -186,19 +266,15 @@ func CreateToken(tokenType TokenType, line int32, column int) Token {
 //   - literal:   The parsed or computed value this token represents. Can be
 //     any type (string, number, bool, etc.).
 //   - lexeme:    The original source text that produced this token.
-//   - line:      The 0-based source line number where the token begins.
-//   - column:    The 0-based column number where the token begins.
 //
 // Returns:
 //
-//	A Token with the specified type, lexeme, literal, and position.
-func CreateLiteralToken(tokenType TokenType, literal any, lexeme string, line int32, column int) Token {
+//	A Token with the specified type, lexeme and literal. Its position is set by the lexer.
+func CreateLiteralToken(tokenType TokenType, literal any, lexeme string) Token {
 	return Token{
 		TokenType: tokenType,
 		Lexeme:    lexeme,
 		Literal:   literal,
-		Line:      line,
-		Column:    column,
 	}
 }
 
@@ -208,7 +284,7 @@ func CreateLiteralToken(tokenType TokenType, literal any, lexeme string, line in
 //
 // Example:
 //
-//	tok := CreateLiteralToken(NUMBER, 123, "123", 3, 10)
+//	tok := CreateLiteralToken(NUMBER, 123, "123")
 //	fmt.Println(tok)
 //	// Output: Token {Type: NUMBER, Value: "123"}
 func (t Token) String() string {
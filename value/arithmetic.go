@@ -0,0 +1,135 @@
+package value
+
+import (
+	"math"
+	"math/big"
+)
+
+// FloorDivInt and FloorDivFloat divide and round the quotient towards negative infinity,
+// e.g `-7 // 2` is -4. The divisor must not be 0.
+func FloorDivInt(a int64, b int64) int64 {
+	quotient := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		quotient--
+	}
+	return quotient
+}
+
+func FloorDivFloat(a float64, b float64) float64 {
+	return math.Floor(a / b)
+}
+
+// ModInt and ModFloat return the remainder of the floor division, which has the sign of
+// the divisor, so that `a == (a // b) * b + a % b`, e.g `-7 % 3` is 2. The divisor must not be 0.
+func ModInt(a int64, b int64) int64 {
+	remainder := a % b
+	if remainder != 0 && (remainder < 0) != (b < 0) {
+		remainder += b
+	}
+	return remainder
+}
+
+func ModFloat(a float64, b float64) float64 {
+	remainder := math.Mod(a, b)
+	if remainder != 0 && (remainder < 0) != (b < 0) {
+		remainder += b
+	}
+	return remainder
+}
+
+// PowInt raises an integer to a non-negative integer exponent by repeated squaring.
+// A negative exponent has a fractional result, which is computed with math.Pow instead.
+func PowInt(base int64, exponent int64) int64 {
+	result := int64(1)
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exponent >>= 1
+	}
+	return result
+}
+
+// AddIntChecked, SubIntChecked, MultIntChecked, NegIntChecked, FloorDivIntChecked, PowIntChecked
+// and ShiftLeftIntChecked return the result of the integer operation and whether it fits in an
+// int64. The result wraps around when it does not, e.g `9223372036854775807 + 1` is
+// -9223372036854775808.
+func AddIntChecked(a int64, b int64) (int64, bool) {
+	result := a + b
+	return result, (result > a) == (b > 0)
+}
+
+func SubIntChecked(a int64, b int64) (int64, bool) {
+	result := a - b
+	return result, (result < a) == (b > 0)
+}
+
+func MultIntChecked(a int64, b int64) (int64, bool) {
+	result := a * b
+	if a == 0 || b == 0 {
+		return result, true
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return result, false
+	}
+	return result, result/b == a
+}
+
+func NegIntChecked(a int64) (int64, bool) {
+	return -a, a != math.MinInt64
+}
+
+// NOTE: The divisor must not be 0.
+func FloorDivIntChecked(a int64, b int64) (int64, bool) {
+	if a == math.MinInt64 && b == -1 {
+		return a, false
+	}
+	return FloorDivInt(a, b), true
+}
+
+// NOTE: The exponent must not be negative.
+func PowIntChecked(base int64, exponent int64) (int64, bool) {
+	result := int64(1)
+	fits := true
+	for exponent > 0 {
+		var ok bool
+		if exponent&1 == 1 {
+			result, ok = MultIntChecked(result, base)
+			fits = fits && ok
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			base, ok = MultIntChecked(base, base)
+			fits = fits && ok
+		}
+	}
+	return result, fits
+}
+
+// NOTE: The shift count must not be negative.
+func ShiftLeftIntChecked(a int64, count int64) (int64, bool) {
+	if count >= 64 {
+		return 0, a == 0
+	}
+	result := a << count
+	return result, result>>count == a
+}
+
+// FloorDivBig and ModBig are FloorDivInt and ModInt for arbitrary-precision integers.
+// They return a new integer. The divisor must not be 0.
+func FloorDivBig(a *big.Int, b *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if remainder.Sign() != 0 && remainder.Sign() != b.Sign() {
+		quotient.Sub(quotient, big.NewInt(1))
+	}
+	return quotient
+}
+
+func ModBig(a *big.Int, b *big.Int) *big.Int {
+	remainder := new(big.Int).Rem(a, b)
+	if remainder.Sign() != 0 && remainder.Sign() != b.Sign() {
+		remainder.Add(remainder, b)
+	}
+	return remainder
+}
@@ -0,0 +1,64 @@
+package value
+
+import (
+	"math"
+	"math/big"
+)
+
+// CompareNumbers compares the values of two numbers, which can be int64, float64, *big.Int or
+// Decimal values, returning -1, 0 or +1. The comparison is exact, e.g 9007199254740993 is larger
+// than 9007199254740992.0. It returns false if the values can not be ordered, which is the case
+// for NaN and non-numeric values.
+func CompareNumbers(a any, b any) (int, bool) {
+	// NOTE: Infinities have no exact rational value, they are larger or smaller than any other value.
+	if f, ok := a.(float64); ok && math.IsInf(f, 0) {
+		if g, ok := b.(float64); ok && g == f {
+			return 0, true
+		}
+		if _, ok := toRat(b); !ok && !isInf(b) {
+			return 0, false
+		}
+		return int(math.Copysign(1, f)), true
+	}
+	if f, ok := b.(float64); ok && math.IsInf(f, 0) {
+		if _, ok := toRat(a); !ok {
+			return 0, false
+		}
+		return -int(math.Copysign(1, f)), true
+	}
+
+	aRat, ok := toRat(a)
+	if !ok {
+		return 0, false
+	}
+	bRat, ok := toRat(b)
+	if !ok {
+		return 0, false
+	}
+	return aRat.Cmp(bRat), true
+}
+
+// isInf determines if a value is a float64 infinity.
+func isInf(val any) bool {
+	f, ok := val.(float64)
+	return ok && math.IsInf(f, 0)
+}
+
+// toRat converts a finite number to its exact rational value, it returns false for NaN,
+// infinities and non-numeric values.
+func toRat(val any) (*big.Rat, bool) {
+	switch v := val.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case *big.Int:
+		return new(big.Rat).SetInt(v), true
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(v), true
+	case Decimal:
+		return new(big.Rat).SetFrac(v.coefficientOrZero(), pow10(int64(v.scale))), true
+	}
+	return nil, false
+}
@@ -0,0 +1,284 @@
+package value
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, the value of a numeric literal with a `d` suffix such
+// as `12.50d`. Its value is its coefficient multiplied by 10^-scale, e.g 12.50 has the
+// coefficient 1250 and the scale 2.
+//
+// Addition, subtraction and multiplication are exact and keep the trailing zeros of their
+// operands, e.g `1.10d + 2.20d` is 3.30. Division is rounded, see Quo.
+//
+// The zero value is 0. A Decimal is immutable.
+type Decimal struct {
+	coefficient *big.Int
+	scale       int32
+}
+
+// MaxDecimalExponent is the largest magnitude of the exponent of a decimal literal, e.g `1e1000d`.
+const MaxDecimalExponent = 1000
+
+// ErrDecimalRange is returned by ParseDecimal for a decimal whose exponent is larger than
+// MaxDecimalExponent.
+var ErrDecimalRange = errors.New("decimal exponent out of range")
+
+// ParseDecimal converts the text of a decimal number, such as `12.50`, `.5`, `-3` or `1.5e3`,
+// to a Decimal. The text must not contain `_` separators or the `d` suffix.
+func ParseDecimal(text string) (Decimal, error) {
+	mantissa, exponentText, hasExponent := strings.Cut(strings.ToLower(text), "e")
+	exponent := int64(0)
+	if hasExponent {
+		var err error
+		exponent, err = strconv.ParseInt(exponentText, 10, 32)
+		if err != nil || exponent > MaxDecimalExponent || exponent < -MaxDecimalExponent {
+			return Decimal{}, ErrDecimalRange
+		}
+	}
+
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	coefficient, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok || strings.ContainsAny(fraction, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal: '%s'", text)
+	}
+	return NewDecimal(coefficient, int64(len(fraction))-exponent), nil
+}
+
+// NewDecimal returns the decimal coefficient * 10^-scale. A negative scale multiplies the
+// coefficient, so the scale of a Decimal is never negative.
+func NewDecimal(coefficient *big.Int, scale int64) Decimal {
+	if scale < 0 {
+		return Decimal{coefficient: new(big.Int).Mul(coefficient, pow10(-scale)), scale: 0}
+	}
+	return Decimal{coefficient: new(big.Int).Set(coefficient), scale: int32(scale)}
+}
+
+// DecimalFromInt returns the decimal with the value of an integer and a scale of 0.
+func DecimalFromInt(n *big.Int) Decimal {
+	return NewDecimal(n, 0)
+}
+
+// Coefficient returns the coefficient of the decimal, the digits of its value.
+func (d Decimal) Coefficient() *big.Int {
+	if d.coefficient == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.coefficient)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// coefficientOrZero returns the coefficient of the decimal without copying it, it must not be modified.
+func (d Decimal) coefficientOrZero() *big.Int {
+	if d.coefficient == nil {
+		return new(big.Int)
+	}
+	return d.coefficient
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the decimal.
+func (d Decimal) Sign() int {
+	return d.coefficientOrZero().Sign()
+}
+
+// rescale returns the coefficient of the decimal with `scale` digits after the decimal point,
+// which must not be smaller than the scale of the decimal.
+func (d Decimal) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(d.coefficientOrZero(), pow10(int64(scale-d.scale)))
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{coefficient: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{coefficient: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coefficient: new(big.Int).Mul(d.coefficientOrZero(), other.coefficientOrZero()), scale: d.scale + other.scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coefficient: new(big.Int).Neg(d.coefficientOrZero()), scale: d.scale}
+}
+
+// Quo divides the decimal by `other`, which must not be 0. An exact quotient keeps the larger
+// scale of its operands, e.g `10.00d / 4` is 2.50, otherwise the quotient is rounded to `places`
+// digits after the decimal point, e.g `10d / 3` is 3.3333333333333333 with 16 places.
+func (d Decimal) Quo(other Decimal, places int32, rounding Rounding) Decimal {
+	minScale := max(d.scale, other.scale)
+	scale := max(places, minScale)
+
+	// NOTE: d / other * 10^scale == d.coefficient * 10^(scale + other.scale - d.scale) / other.coefficient
+	numerator := d.coefficientOrZero()
+	denominator := other.coefficientOrZero()
+	if exponent := int64(scale) + int64(other.scale) - int64(d.scale); exponent >= 0 {
+		numerator = new(big.Int).Mul(numerator, pow10(exponent))
+	} else {
+		denominator = new(big.Int).Mul(denominator, pow10(-exponent))
+	}
+
+	quotient := Decimal{coefficient: divRound(numerator, denominator, rounding), scale: scale}
+	return quotient.trimZeros(minScale)
+}
+
+// Round rounds the decimal to `places` digits after the decimal point. A decimal with fewer
+// digits is padded with zeros, e.g rounding 2.5 to 2 places is 2.50.
+func (d Decimal) Round(places int32, rounding Rounding) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return Decimal{coefficient: d.rescale(places), scale: places}
+	}
+	return Decimal{coefficient: divRound(d.coefficientOrZero(), pow10(int64(d.scale-places)), rounding), scale: places}
+}
+
+// trimZeros removes the trailing zeros after the decimal point, keeping at least `minScale` digits.
+func (d Decimal) trimZeros(minScale int32) Decimal {
+	coefficient := d.coefficientOrZero()
+	scale := d.scale
+	ten := big.NewInt(10)
+	quotient, remainder := new(big.Int), new(big.Int)
+	for scale > minScale {
+		quotient.QuoRem(coefficient, ten, remainder)
+		if remainder.Sign() != 0 {
+			break
+		}
+		coefficient = new(big.Int).Set(quotient)
+		scale--
+	}
+	return Decimal{coefficient: coefficient, scale: scale}
+}
+
+// Cmp compares the values of two decimals, returning -1, 0 or +1. Trailing zeros are
+// not significant, e.g 1.50 is equal to 1.5.
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+// Float64 returns the float64 nearest to the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.coefficientOrZero(), pow10(int64(d.scale))).Float64()
+	return f
+}
+
+// String formats the decimal with all the digits after its decimal point, e.g `12.50`.
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.coefficientOrZero()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalText encodes the decimal as its text, e.g in the JSON of an AST.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// pow10 returns 10^exponent, the exponent must not be negative.
+func pow10(exponent int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(exponent), nil)
+}
+
+// Rounding determines how an inexact decimal result is rounded.
+type Rounding int
+
+const (
+	// Rounds to the nearest value, and ties to the nearest even digit, e.g 2.5 is 2 and 3.5 is 4.
+	// This is the default, as it does not bias sums of rounded values.
+	RoundHalfEven Rounding = iota
+	// Rounds to the nearest value, and ties away from zero, e.g 2.5 is 3 and -2.5 is -3.
+	RoundHalfUp
+	// Rounds towards zero, e.g 2.9 is 2 and -2.9 is -2.
+	RoundDown
+	// Rounds away from zero, e.g 2.1 is 3 and -2.1 is -3.
+	RoundUp
+	// Rounds towards positive infinity, e.g 2.1 is 3 and -2.9 is -2.
+	RoundCeiling
+	// Rounds towards negative infinity, e.g 2.9 is 2 and -2.1 is -3.
+	RoundFloor
+)
+
+var roundingNames = map[Rounding]string{
+	RoundHalfEven: "half-even",
+	RoundHalfUp:   "half-up",
+	RoundDown:     "down",
+	RoundUp:       "up",
+	RoundCeiling:  "ceiling",
+	RoundFloor:    "floor",
+}
+
+func (rounding Rounding) String() string {
+	if name, ok := roundingNames[rounding]; ok {
+		return name
+	}
+	return fmt.Sprintf("Rounding(%d)", int(rounding))
+}
+
+// ParseRounding returns the Rounding named `name`, e.g "half-even".
+func ParseRounding(name string) (Rounding, error) {
+	for rounding, roundingName := range roundingNames {
+		if roundingName == name {
+			return rounding, nil
+		}
+	}
+	return RoundHalfEven, fmt.Errorf("unknown rounding mode: '%s', expected 'half-even', 'half-up', 'down', 'up', 'ceiling' or 'floor'", name)
+}
+
+// divRound divides two integers and rounds the quotient to an integer. The denominator must not be 0.
+func divRound(numerator *big.Int, denominator *big.Int, rounding Rounding) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	negative := numerator.Sign() != denominator.Sign()
+	// NOTE: The comparison of twice the remainder with the denominator determines if the
+	// remainder is below, at or above half of the denominator.
+	half := new(big.Int).Lsh(new(big.Int).Abs(remainder), 1).Cmp(new(big.Int).Abs(denominator))
+
+	var awayFromZero bool
+	switch rounding {
+	case RoundHalfEven:
+		awayFromZero = half > 0 || half == 0 && quotient.Bit(0) == 1
+	case RoundHalfUp:
+		awayFromZero = half >= 0
+	case RoundDown:
+		awayFromZero = false
+	case RoundUp:
+		awayFromZero = true
+	case RoundCeiling:
+		awayFromZero = !negative
+	case RoundFloor:
+		awayFromZero = negative
+	}
+	if !awayFromZero {
+		return quotient
+	}
+	if negative {
+		return quotient.Sub(quotient, big.NewInt(1))
+	}
+	return quotient.Add(quotient, big.NewInt(1))
+}
@@ -0,0 +1,133 @@
+package value
+
+import (
+	"math/big"
+	"testing"
+)
+
+func mustParseDecimal(t *testing.T, text string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(text)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q) error: %v", text, err)
+	}
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  string
+		scale int32
+	}{
+		{text: "12.50", want: "12.50", scale: 2},
+		{text: "-0.05", want: "-0.05", scale: 2},
+		{text: ".5", want: "0.5", scale: 1},
+		{text: "7", want: "7", scale: 0},
+		{text: "1.5e3", want: "1500", scale: 0},
+		{text: "1.5E-3", want: "0.0015", scale: 4},
+		{text: "0.000", want: "0.000", scale: 3},
+	}
+	for _, tt := range tests {
+		d := mustParseDecimal(t, tt.text)
+		if d.String() != tt.want || d.Scale() != tt.scale {
+			t.Errorf("ParseDecimal(%q) = %s with scale %d, want %s with scale %d", tt.text, d, d.Scale(), tt.want, tt.scale)
+		}
+	}
+
+	for _, text := range []string{"", ".", "1.2.3", "1.-5", "1e", "abc"} {
+		if _, err := ParseDecimal(text); err == nil {
+			t.Errorf("ParseDecimal(%q) returned no error", text)
+		}
+	}
+	if _, err := ParseDecimal("1e1001"); err != ErrDecimalRange {
+		t.Errorf("got error %v, want ErrDecimalRange", err)
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := mustParseDecimal(t, "0.1")
+	b := mustParseDecimal(t, "0.20")
+
+	if got := a.Add(b).String(); got != "0.30" {
+		t.Errorf("0.1 + 0.20 = %s, want 0.30", got)
+	}
+	if got := a.Sub(b).String(); got != "-0.10" {
+		t.Errorf("0.1 - 0.20 = %s, want -0.10", got)
+	}
+	if got := a.Mul(b).String(); got != "0.020" {
+		t.Errorf("0.1 * 0.20 = %s, want 0.020", got)
+	}
+	if got := a.Neg().String(); got != "-0.1" {
+		t.Errorf("-0.1 = %s, want -0.1", got)
+	}
+	if a.Add(b).Cmp(mustParseDecimal(t, "0.3")) != 0 {
+		t.Errorf("0.1 + 0.20 is not equal to 0.3")
+	}
+	if a.Cmp(b) != -1 || b.Cmp(a) != 1 {
+		t.Errorf("0.1 is not smaller than 0.20")
+	}
+	var zero Decimal
+	if zero.String() != "0" || zero.Add(a).String() != "0.1" {
+		t.Errorf("the zero value is not 0")
+	}
+	if got := DecimalFromInt(big.NewInt(-3)).Float64(); got != -3 {
+		t.Errorf("got %v, want -3", got)
+	}
+}
+
+func TestDecimalQuo(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		places   int32
+		rounding Rounding
+		want     string
+	}{
+		{a: "10.00", b: "4", places: 16, rounding: RoundHalfEven, want: "2.50"},
+		{a: "10", b: "3", places: 4, rounding: RoundHalfEven, want: "3.3333"},
+		{a: "2", b: "3", places: 2, rounding: RoundHalfEven, want: "0.67"},
+		{a: "2", b: "3", places: 2, rounding: RoundDown, want: "0.66"},
+		{a: "-2", b: "3", places: 2, rounding: RoundDown, want: "-0.66"},
+		{a: "-2", b: "3", places: 2, rounding: RoundFloor, want: "-0.67"},
+		{a: "1", b: "3", places: 2, rounding: RoundCeiling, want: "0.34"},
+		{a: "1", b: "3", places: 2, rounding: RoundUp, want: "0.34"},
+		{a: "0.125", b: "1", places: 2, rounding: RoundHalfEven, want: "0.125"},
+		{a: "1", b: "8", places: 2, rounding: RoundHalfEven, want: "0.12"},
+		{a: "3", b: "8", places: 2, rounding: RoundHalfEven, want: "0.38"},
+		{a: "1", b: "8", places: 2, rounding: RoundHalfUp, want: "0.13"},
+		{a: "-1", b: "8", places: 2, rounding: RoundHalfUp, want: "-0.13"},
+		{a: "1", b: "0.001", places: 0, rounding: RoundHalfEven, want: "1000.000"},
+		{a: "1", b: "7", places: 0, rounding: RoundHalfEven, want: "0"},
+	}
+	for _, tt := range tests {
+		got := mustParseDecimal(t, tt.a).Quo(mustParseDecimal(t, tt.b), tt.places, tt.rounding)
+		if got.String() != tt.want {
+			t.Errorf("%s / %s with %d places %v = %s, want %s", tt.a, tt.b, tt.places, tt.rounding, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	d := mustParseDecimal(t, "2.345")
+	if got := d.Round(2, RoundHalfEven).String(); got != "2.34" {
+		t.Errorf("got %s, want 2.34", got)
+	}
+	if got := d.Round(2, RoundHalfUp).String(); got != "2.35" {
+		t.Errorf("got %s, want 2.35", got)
+	}
+	if got := d.Round(5, RoundHalfEven).String(); got != "2.34500" {
+		t.Errorf("got %s, want 2.34500", got)
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	for rounding := range roundingNames {
+		got, err := ParseRounding(rounding.String())
+		if err != nil || got != rounding {
+			t.Errorf("ParseRounding(%q) = %v, %v, want %v", rounding.String(), got, err, rounding)
+		}
+	}
+	if _, err := ParseRounding("bankers"); err == nil {
+		t.Errorf("got no error for an unknown rounding mode")
+	}
+}
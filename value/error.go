@@ -0,0 +1,38 @@
+// Package value contains the runtime values of Nilan which do not map directly to a Go type,
+// and the arithmetic on numbers which has no Go operator. They are shared by the VM and the
+// tree-walk interpreter.
+package value
+
+import "fmt"
+
+// Error is the value a runtime error, for example a type mismatch, is converted to
+// when it is caught by a `catch` clause. Its `message` and `line` are read as properties,
+// e.g `e.message`.
+type Error struct {
+	// Describes what went wrong.
+	Message string
+
+	// The source line (1-based) of the code which raised the error, 0 if unknown.
+	Line int
+}
+
+func (e Error) String() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("Error: %s (line %d)", e.Message, e.Line)
+	}
+	return fmt.Sprintf("Error: %s", e.Message)
+}
+
+// Member returns the `message` or the `line` of the error, the line is null if it is unknown.
+func (e Error) Member(name string) (any, error) {
+	switch name {
+	case "message":
+		return e.Message, nil
+	case "line":
+		if e.Line == 0 {
+			return nil, nil
+		}
+		return int64(e.Line), nil
+	}
+	return nil, fmt.Errorf("error has no property '%s'", name)
+}
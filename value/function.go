@@ -0,0 +1,87 @@
+package value
+
+import "fmt"
+
+// NativeFunction is a function implemented in Go, such as `sqrt`, which can be called by scripts.
+type NativeFunction struct {
+	Name string
+	// The smallest number of arguments the function can be called with.
+	MinArity int
+	// The largest number of arguments the function can be called with, -1 if it takes any number of
+	// arguments, e.g `max`.
+	MaxArity int
+	// Function computes the result of a call, after the number of arguments has been checked.
+	// The message of a returned error is reported as a runtime error.
+	Function func(args []any) (any, error)
+}
+
+// Call checks the number of arguments and calls the function.
+func (fn *NativeFunction) Call(args []any) (any, error) {
+	if len(args) < fn.MinArity || fn.MaxArity >= 0 && len(args) > fn.MaxArity {
+		return nil, fmt.Errorf("%s() takes %s, got %d", fn.Name, fn.arity(), len(args))
+	}
+	return fn.Function(args)
+}
+
+// arity describes the number of arguments the function takes, e.g "1 or 2 arguments".
+func (fn *NativeFunction) arity() string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return fmt.Sprintf("%d arguments", n)
+	}
+	switch {
+	case fn.MaxArity < 0:
+		return "at least " + plural(fn.MinArity)
+	case fn.MinArity == fn.MaxArity:
+		return plural(fn.MinArity)
+	case fn.MaxArity == fn.MinArity+1:
+		return fmt.Sprintf("%d or %s", fn.MinArity, plural(fn.MaxArity))
+	}
+	return fmt.Sprintf("%d to %s", fn.MinArity, plural(fn.MaxArity))
+}
+
+func (fn *NativeFunction) String() string {
+	return fmt.Sprintf("<native fn %s>", fn.Name)
+}
+
+// Callable is a value which can be called, such as a native function or a struct, whose calls
+// create instances.
+type Callable interface {
+	// Call returns the result of calling the value with `args`, or an error reported as a runtime error.
+	Call(args []any) (any, error)
+}
+
+// Object is a value with properties, which are read with `.`, such as a module or a map.
+type Object interface {
+	// Member returns the property called `name`, or an error if there is none.
+	Member(name string) (any, error)
+}
+
+// MutableObject is an object whose properties can be assigned with `.`, such as a struct instance.
+type MutableObject interface {
+	Object
+	// SetMember assigns the property called `name`, or returns an error if it can not be assigned.
+	SetMember(name string, val any) error
+}
+
+// Module is a namespace of values, such as `math`, whose members are accessed with `.`,
+// e.g `math.sqrt(2)`.
+type Module struct {
+	Name    string
+	Members map[string]any
+}
+
+// Member returns the member of the module called `name`.
+func (module *Module) Member(name string) (any, error) {
+	member, ok := module.Members[name]
+	if !ok {
+		return nil, fmt.Errorf("module '%s' has no member '%s'", module.Name, name)
+	}
+	return member, nil
+}
+
+func (module *Module) String() string {
+	return fmt.Sprintf("<module %s>", module.Name)
+}
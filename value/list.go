@@ -0,0 +1,54 @@
+package value
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// List is an ordered sequence of values, such as the result of `string.split("a,b", ",")`.
+type List struct {
+	Elements []any
+}
+
+// Equal determines if both lists have the same number of elements and their elements are equal
+// according to `equal`.
+func (list *List) Equal(other *List, equal func(a any, b any) bool) bool {
+	if len(list.Elements) != len(other.Elements) {
+		return false
+	}
+	for i, element := range list.Elements {
+		if !equal(element, other.Elements[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the list like its elements would be written in the source code,
+// e.g `["a", 1, null]`.
+func (list *List) String() string {
+	var builder strings.Builder
+	builder.WriteString("[")
+	for i, element := range list.Elements {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		writeElement(&builder, element)
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// writeElement writes an element of a collection, quoting strings so they can be told apart
+// from other values.
+func writeElement(builder *strings.Builder, element any) {
+	switch e := element.(type) {
+	case nil:
+		builder.WriteString("null")
+	case string:
+		builder.WriteString(strconv.Quote(e))
+	default:
+		fmt.Fprint(builder, e)
+	}
+}
@@ -0,0 +1,85 @@
+package value
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Map is a collection of values indexed by string keys, such as the objects returned by
+// `json_parse`. It remembers the order its keys were first inserted in, which is the order
+// they are printed and encoded in.
+type Map struct {
+	keys    []string
+	entries map[string]any
+}
+
+// NewMap returns an empty map.
+func NewMap() *Map {
+	return &Map{entries: map[string]any{}}
+}
+
+// Get returns the value of `key` and whether the map contains it.
+func (m *Map) Get(key string) (any, bool) {
+	val, ok := m.entries[key]
+	return val, ok
+}
+
+// Set sets the value of `key`, which keeps its position if the map already contains it.
+func (m *Map) Set(key string, val any) {
+	if _, ok := m.entries[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.entries[key] = val
+}
+
+// Keys returns the keys of the map in insertion order.
+func (m *Map) Keys() []string {
+	return m.keys
+}
+
+// Len returns the number of keys of the map.
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// Member returns the value of the key called `name`, so keys which are identifiers can be read
+// as properties, e.g `config.name`.
+func (m *Map) Member(name string) (any, error) {
+	val, ok := m.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("map has no key '%s'", name)
+	}
+	return val, nil
+}
+
+// Equal determines if both maps have the same keys, in any order, with equal values
+// according to `equal`.
+func (m *Map) Equal(other *Map, equal func(a any, b any) bool) bool {
+	if m.Len() != other.Len() {
+		return false
+	}
+	for key, val := range m.entries {
+		otherVal, ok := other.entries[key]
+		if !ok || !equal(val, otherVal) {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the map like a JSON object, e.g `{"name": "nilan", "tags": ["a"]}`.
+func (m *Map) String() string {
+	var builder strings.Builder
+	builder.WriteString("{")
+	for i, key := range m.keys {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(strconv.Quote(key))
+		builder.WriteString(": ")
+		writeElement(&builder, m.entries[key])
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
@@ -0,0 +1,132 @@
+package value
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Struct is a type of record declared with `struct`, e.g `struct Point { x, y }`. Calling it
+// creates an instance with a value for each of its fields, in order: `Point(1, 2)`.
+//
+// Structs are compared by identity: instances of two declarations with the same fields are not equal.
+type Struct struct {
+	Name   string
+	Fields []string
+}
+
+// Call creates an instance of the struct whose fields are the arguments, in order.
+func (s *Struct) Call(args []any) (any, error) {
+	if len(args) != len(s.Fields) {
+		return nil, fmt.Errorf("%s() takes %d arguments, one for each field (%s), got %d", s.Name, len(s.Fields), strings.Join(s.Fields, ", "), len(args))
+	}
+	return &Instance{Struct: s, Values: append([]any(nil), args...)}, nil
+}
+
+func (s *Struct) String() string {
+	return fmt.Sprintf("<struct %s>", s.Name)
+}
+
+// Instance is a value created by calling a struct, whose fields are read and assigned with `.`,
+// e.g `p.x = p.x + 1`.
+type Instance struct {
+	Struct *Struct
+	// Values holds the value of each field of the struct, in the order of `Struct.Fields`.
+	Values []any
+}
+
+// Member returns the value of the field called `name`.
+func (instance *Instance) Member(name string) (any, error) {
+	index, err := instance.field(name)
+	if err != nil {
+		return nil, err
+	}
+	return instance.Values[index], nil
+}
+
+// SetMember assigns the field called `name`. Fields which are not declared by the struct can
+// not be added.
+func (instance *Instance) SetMember(name string, val any) error {
+	index, err := instance.field(name)
+	if err != nil {
+		return err
+	}
+	instance.Values[index] = val
+	return nil
+}
+
+// field returns the index of the field called `name`.
+func (instance *Instance) field(name string) (int, error) {
+	index := slices.Index(instance.Struct.Fields, name)
+	if index == -1 {
+		return 0, fmt.Errorf("struct '%s' has no field '%s'", instance.Struct.Name, name)
+	}
+	return index, nil
+}
+
+// Equal determines if both instances are of the same struct and their fields are equal
+// according to `equal`. Fields holding instances are compared by Equal, so instances which
+// reference themselves, e.g after `p.next = p`, can be compared.
+func (instance *Instance) Equal(other *Instance, equal func(a any, b any) bool) bool {
+	return instance.equal(other, equal, map[[2]*Instance]bool{})
+}
+
+// equal compares two instances. `comparing` holds the pairs of instances being compared by the
+// callers, a pair which is compared again is part of a cycle and is assumed to be equal.
+func (instance *Instance) equal(other *Instance, equal func(a any, b any) bool, comparing map[[2]*Instance]bool) bool {
+	if instance.Struct != other.Struct {
+		return false
+	}
+	pair := [2]*Instance{instance, other}
+	if instance == other || comparing[pair] {
+		return true
+	}
+	comparing[pair] = true
+	for i, val := range instance.Values {
+		x, isXInstance := val.(*Instance)
+		y, isYInstance := other.Values[i].(*Instance)
+		switch {
+		case isXInstance && isYInstance:
+			if !x.equal(y, equal, comparing) {
+				return false
+			}
+		case !equal(val, other.Values[i]):
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the instance with its fields, e.g `Point(x: 1, y: 2)`. An instance which
+// is nested in itself is written as `...`.
+func (instance *Instance) String() string {
+	var builder strings.Builder
+	instance.write(&builder, map[*Instance]bool{})
+	return builder.String()
+}
+
+// write writes the instance, `writing` holds the instances it is nested in.
+func (instance *Instance) write(builder *strings.Builder, writing map[*Instance]bool) {
+	if writing[instance] {
+		builder.WriteString("...")
+		return
+	}
+	writing[instance] = true
+	defer delete(writing, instance)
+
+	builder.WriteString(instance.Struct.Name)
+	builder.WriteString("(")
+	for i, field := range instance.Struct.Fields {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(field)
+		builder.WriteString(": ")
+		if nested, ok := instance.Values[i].(*Instance); ok {
+			nested.write(builder, writing)
+		} else {
+			writeElement(builder, instance.Values[i])
+		}
+	}
+	builder.WriteString(")")
+}
@@ -0,0 +1,71 @@
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"nilan/compiler"
+	"nilan/value"
+)
+
+// DefaultDecimalPlaces is the number of digits after the decimal point an inexact decimal
+// division is rounded to, unless it is changed with SetDecimalRounding.
+const DefaultDecimalPlaces = 16
+
+// SetDecimalRounding sets how the VM rounds the result of a decimal division which is not exact,
+// e.g `10d / 3`: to `places` digits after the decimal point using `rounding`.
+// Decimal addition, subtraction and multiplication are always exact.
+func (vm *VirtualMachine) SetDecimalRounding(rounding value.Rounding, places int32) {
+	vm.decimalRounding = rounding
+	vm.decimalPlaces = max(places, 0)
+}
+
+// isDecimal determines if a value is a decimal.
+func isDecimal(val any) bool {
+	_, ok := val.(value.Decimal)
+	return ok
+}
+
+// toDecimal converts a decimal or an integer to a decimal. Floats are not converted, as they
+// are not exact.
+func toDecimal(val any) (value.Decimal, bool) {
+	switch v := val.(type) {
+	case value.Decimal:
+		return v, true
+	case int64:
+		return value.DecimalFromInt(big.NewInt(v)), true
+	case *big.Int:
+		return value.DecimalFromInt(v), true
+	}
+	return value.Decimal{}, false
+}
+
+// decimalArithmetic applies an arithmetic operation to operands of which at least one is a decimal.
+// The other operand must be a decimal or an integer, and only `+`, `-`, `*` and `/` are supported.
+// Division by zero is handled before this function is called.
+func (vm *VirtualMachine) decimalArithmetic(opCode compiler.Opcode, a any, b any) (any, error) {
+	aDecimal, isADecimal := toDecimal(a)
+	bDecimal, isBDecimal := toDecimal(b)
+	if !isADecimal || !isBDecimal {
+		message := fmt.Sprintf("operands must be decimal or integer values: %v,%v", formatOperand(a), formatOperand(b))
+		return nil, RuntimeError{Message: message}
+	}
+
+	var result value.Decimal
+	switch opCode {
+	case compiler.OP_ADD:
+		result = aDecimal.Add(bDecimal)
+	case compiler.OP_SUBTRACT:
+		result = aDecimal.Sub(bDecimal)
+	case compiler.OP_MULTIPLY:
+		result = aDecimal.Mul(bDecimal)
+	case compiler.OP_DIVIDE:
+		result = aDecimal.Quo(bDecimal, vm.decimalPlaces, vm.decimalRounding)
+	default:
+		return nil, RuntimeError{Message: fmt.Sprintf("operator %s is not supported for decimal values: %v,%v", operatorSymbols[opCode], a, b)}
+	}
+
+	if result.Coefficient().BitLen() > maxIntegerBits {
+		return nil, RuntimeError{Message: fmt.Sprintf("decimal overflow: the result has more than %d bits", maxIntegerBits)}
+	}
+	return result, nil
+}
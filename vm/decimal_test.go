@@ -0,0 +1,105 @@
+package vm
+
+import (
+	"nilan/value"
+	"testing"
+)
+
+func TestDecimals(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "printed with their scale",
+			source: "print 12.50d\nprint -0.05d\nprint \"${1.10d}\"",
+			want:   "12.50\n-0.05\n1.10\n",
+		},
+		{
+			name:   "exact arithmetic",
+			source: "print 0.1d + 0.2d\nprint 0.1d + 0.2d == 0.3d\nprint 19.99d * 3\nprint 10.00d - 0.01d\nprint -(1.5d)",
+			want:   "0.3\ntrue\n59.97\n9.99\n-1.5\n",
+		},
+		{
+			name:   "division",
+			source: "print 10.00d / 4\nprint 1d / 3\nprint 2 / 3.00d",
+			want:   "2.50\n0.3333333333333333\n0.6666666666666667\n",
+		},
+		{
+			name:   "comparison",
+			source: "print 1.50d == 1.5d\nprint 1.0d == 1\nprint 1.0d == 1.0\nprint 1.5d != 1.5d\nprint 0.3d > 0.29999d\nprint 2 >= 2.00d\nprint 0.1d < 0.1\nprint 1.5d < 2 ** 62",
+			want:   "true\ntrue\nfalse\nfalse\ntrue\ntrue\ntrue\ntrue\n",
+		},
+		{
+			name:   "compound assignment",
+			source: "var total = 0d\nvar i = 0\nwhile i < 10 { total += 0.10d i += 1 }\nprint total\nprint total == 1",
+			want:   "1.00\ntrue\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runSource(t, tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	tests := []struct {
+		rounding value.Rounding
+		places   int32
+		want     string
+	}{
+		{rounding: value.RoundHalfEven, places: 2, want: "0.67\n-0.67\n0.12\n"},
+		{rounding: value.RoundHalfUp, places: 2, want: "0.67\n-0.67\n0.13\n"},
+		{rounding: value.RoundDown, places: 2, want: "0.66\n-0.66\n0.12\n"},
+		{rounding: value.RoundFloor, places: 1, want: "0.6\n-0.7\n0.1\n"},
+		{rounding: value.RoundCeiling, places: 0, want: "1\n0\n1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rounding.String(), func(t *testing.T) {
+			vm := New()
+			vm.SetDecimalRounding(tt.rounding, tt.places)
+			got, err := runSourceWith(t, "print 2d / 3\nprint -2d / 3\nprint 1d / 8", vm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "print 1.5d + 0.5", want: "operands must be decimal or integer values: 1.5,0.5"},
+		{source: "print 1.5d / 0", want: "division by zero"},
+		{source: "print 1.5d / 0.00d", want: "division by zero"},
+		{source: "print 7.5d % 2", want: "operator % is not supported for decimal values: 7.5,2"},
+		{source: "print 1.5d ** 2", want: "operator ** is not supported for decimal values: 1.5,2"},
+		{source: "print 1.5d & 1", want: "operands must be integer values: 1.5,1"},
+		{source: "print 1.5d > \"a\"", want: "operands must be numeric values: 1.5,a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := runSource(t, tt.source)
+			runtimeErr, ok := err.(RuntimeError)
+			if !ok || runtimeErr.Message != tt.want {
+				t.Errorf("got error: %v, want a RuntimeError %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,63 @@
 package vm
 
-import "fmt"
+import (
+	"fmt"
+	"nilan/diagnostics"
+	"nilan/token"
+)
 
 type RuntimeError struct {
 	Message string
+	// The span of the source code of the instruction which raised the error, e.g the operator
+	// of a binary expression. Empty if unknown.
+	Start token.Position
+	End   token.Position
+	// module is the name of the imported module which raised the error, its location is part of
+	// the message and the span is the span of the import. It is empty for errors of the main program.
+	module string
 }
 
 func (e RuntimeError) Error() string {
+	if e.Start.Line > 0 {
+		return fmt.Sprintf("💥 RuntimeError: %s, line: %d", e.Message, e.Start.Line)
+	}
 	return fmt.Sprintf("💥 RuntimeError: %s", e.Message)
 }
+
+// Diagnostic describes the error for rendering.
+func (e RuntimeError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeRuntime,
+		Message:  e.Message,
+		Start:    e.Start,
+		End:      e.End,
+	}
+}
+
+// ThrownError is returned by the VM when a value raised by a `throw` statement
+// is not caught by any `catch` clause.
+type ThrownError struct {
+	Value any
+	// The span of the `throw` keyword, empty if unknown.
+	Start token.Position
+	End   token.Position
+}
+
+func (e ThrownError) Error() string {
+	if e.Start.Line > 0 {
+		return fmt.Sprintf("💥 Uncaught exception: %v, line: %d", formatOperand(e.Value), e.Start.Line)
+	}
+	return fmt.Sprintf("💥 Uncaught exception: %v", formatOperand(e.Value))
+}
+
+func (e ThrownError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Severity: diagnostics.SeverityError,
+		Code:     diagnostics.CodeUncaught,
+		Message:  fmt.Sprintf("uncaught exception: %v", formatOperand(e.Value)),
+		Help:     "wrap the code in `try { ... } catch (e) { ... }` to handle the exception",
+		Start:    e.Start,
+		End:      e.End,
+	}
+}
@@ -0,0 +1,178 @@
+package vm
+
+import (
+	"bytes"
+	"nilan/compiler"
+	"nilan/lexer"
+	"nilan/parser"
+	"nilan/token"
+	"nilan/value"
+	"testing"
+)
+
+// runSource compiles and runs a Nilan program, returning its output and the error returned by the VM.
+func runSource(t *testing.T, source string) (string, error) {
+	t.Helper()
+	return runSourceWith(t, source, New())
+}
+
+// runSourceWith compiles and runs a Nilan program on the given VM, returning its output and the
+// error returned by the VM.
+func runSourceWith(t *testing.T, source string, vm *VirtualMachine) (string, error) {
+	t.Helper()
+	tokens, lexErrs := lexer.New(source).Scan()
+	if len(lexErrs) > 0 {
+		t.Fatalf("lexing error: %v", lexErrs)
+	}
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("parsing errors: %v", parseErrs)
+	}
+	bytecode, errs := compiler.NewASTCompiler().CompileAST(statements)
+	if len(errs) > 0 {
+		t.Fatalf("compilation error: %v", errs)
+	}
+	if err := compiler.Verify(bytecode); err != nil {
+		t.Fatalf("verification error: %v", err)
+	}
+
+	var out bytes.Buffer
+	vm.SetOutput(&out)
+	vm.SetInstructionBudget(10_000)
+	err := vm.Run(bytecode)
+	if len(vm.handlers) != 0 {
+		t.Errorf("got %d exception handlers left after running, want 0", len(vm.handlers))
+	}
+	return out.String(), err
+}
+
+func TestVMTryCatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "catch thrown value",
+			source: "try { throw 42 } catch (e) { print e }",
+			want:   "42\n",
+		},
+		{
+			name:   "catch runtime error",
+			source: "try {\n print 1 + null\n} catch (e) { print e }",
+			want:   "Error: operands must be numeric values: 1,null (line 2)\n",
+		},
+		{
+			name:   "body without error skips catch",
+			source: "try { print 1 } catch (e) { print e }\nprint 2",
+			want:   "1\n2\n",
+		},
+		{
+			name:   "finally runs without error",
+			source: "try { print 1 } finally { print 2 }",
+			want:   "1\n2\n",
+		},
+		{
+			name:   "finally runs after catch",
+			source: "try { throw 1 } catch (e) { print e } finally { print 2 }",
+			want:   "1\n2\n",
+		},
+		{
+			name:   "finally runs before the error propagates",
+			source: "try { try { throw 1 } finally { print 2 } } catch (e) { print e }",
+			want:   "2\n1\n",
+		},
+		{
+			name:   "error in catch is caught by enclosing try",
+			source: "try { try { throw 1 } catch (e) { throw e + 1 } } catch (e) { print e }",
+			want:   "2\n",
+		},
+		{
+			name:   "locals are unwound",
+			source: "{ var a = 1 try { var b = 2 { var c = 3 throw c } } catch (e) { var d = a + e print d } print a }",
+			want:   "4\n1\n",
+		},
+		{
+			name:   "catch inside a loop",
+			source: "var i = 0\nwhile i < 3 { try { if i == 1 { throw i } print i } catch (e) { print \"caught\" } i = i + 1 }",
+			want:   "0\ncaught\n2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runSource(t, tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVMStringInterpolation(t *testing.T) {
+	got, err := runSource(t, "var a = 1\n{ var b = \"x\" print \"${a + 1}${b}, ${null} ${a > 0} ${1.5}!\" }")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "2x, null true 1.5!\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+
+	_, err = runSource(t, "print \"${1 / 0}\"")
+	if _, ok := err.(RuntimeError); !ok {
+		t.Errorf("got error %v (%T), want a RuntimeError from the interpolated expression", err, err)
+	}
+}
+
+func TestVMUncaughtErrors(t *testing.T) {
+	t.Run("uncaught thrown value", func(t *testing.T) {
+		_, err := runSource(t, "print 1\nthrow \"boom\"")
+		thrown, ok := err.(ThrownError)
+		if !ok {
+			t.Fatalf("got error %v (%T), want a ThrownError", err, err)
+		}
+		if thrown.Value != "boom" || thrown.Start.Line != 2 {
+			t.Errorf("got %+v, want value \"boom\" on line 2", thrown)
+		}
+	})
+
+	t.Run("rethrown after finally", func(t *testing.T) {
+		out, err := runSource(t, "try {\n print -null\n} finally { print \"cleanup\" }")
+		if out != "cleanup\n" {
+			t.Errorf("got output %q, want %q", out, "cleanup\n")
+		}
+		thrown, ok := err.(ThrownError)
+		if !ok {
+			t.Fatalf("got error %v (%T), want a ThrownError", err, err)
+		}
+		if _, ok := thrown.Value.(value.Error); !ok {
+			t.Errorf("got thrown value %v (%T), want a value.Error", thrown.Value, thrown.Value)
+		}
+	})
+
+	t.Run("runtime error reports the span of its operator", func(t *testing.T) {
+		_, err := runSource(t, "var a = 1\n\nprint a / 0")
+		runtimeErr, ok := err.(RuntimeError)
+		if !ok {
+			t.Fatalf("got error %v (%T), want a RuntimeError", err, err)
+		}
+		start, end := token.Position{Line: 3, Column: 9, Offset: 19}, token.Position{Line: 3, Column: 10, Offset: 20}
+		if runtimeErr.Start != start || runtimeErr.End != end {
+			t.Errorf("got span %v-%v, want %v-%v", runtimeErr.Start, runtimeErr.End, start, end)
+		}
+	})
+
+	t.Run("instruction budget can not be caught", func(t *testing.T) {
+		_, err := runSource(t, "try { while true { } } catch (e) { print e }")
+		runtimeErr, ok := err.(RuntimeError)
+		if !ok {
+			t.Fatalf("got error %v (%T), want a RuntimeError", err, err)
+		}
+		if runtimeErr.Message != "instruction budget of 10000 exceeded" {
+			t.Errorf("got message %q", runtimeErr.Message)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"io"
+	"nilan/compiler"
+	"nilan/lexer"
+	"nilan/parser"
+	"testing"
+)
+
+// fuzzInstructionBudget bounds the number of instructions a fuzzed program can execute,
+// so programs containing infinite loops terminate with an error. It is kept small so a single
+// slow input, e.g a long loop, does not stall the fuzzer.
+const fuzzInstructionBudget = 10_000
+
+// FuzzRun checks that the verifier and the VM never panic when given arbitrary bytecode,
+// for example bytecode with out of range operands, invalid jumps or a missing OP_END.
+func FuzzRun(f *testing.F) {
+	f.Add([]byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_CONSTANT), 0, 1, byte(compiler.OP_ADD), byte(compiler.OP_END)})
+	f.Add([]byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_CONSTANT), 0, 4, byte(compiler.OP_DIVIDE), byte(compiler.OP_END)})
+	f.Add([]byte{byte(compiler.OP_CONSTANT), 0, 9, byte(compiler.OP_END)})
+	f.Add([]byte{byte(compiler.OP_CONSTANT), 0, 0})
+	f.Add([]byte{byte(compiler.OP_JUMP), 0, 0})
+	f.Add([]byte{byte(compiler.OP_GET_LOCAL), 0, 3, byte(compiler.OP_END)})
+	f.Add([]byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_SET_GLOBAL), 0, 0, byte(compiler.OP_GET_GLOBAL), 0, 0, byte(compiler.OP_END)})
+	f.Add([]byte{byte(compiler.OP_TRY_BEGIN), 0, 0, byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_THROW), byte(compiler.OP_TRY_END), byte(compiler.OP_END), byte(compiler.OP_POP), byte(compiler.OP_END)})
+
+	f.Fuzz(func(t *testing.T, instructions []byte) {
+		bytecode := compiler.Bytecode{
+			Instructions:  instructions,
+			ConstantsPool: []any{int64(2), 1.5, true, "nilan", int64(0), nil},
+			NameConstants: []string{"a"},
+			Handlers:      []compiler.ExceptionHandler{{Start: 3, End: 7, Target: 9}, {Start: 0, End: 0, Target: 1000}},
+		}
+		compiler.Verify(bytecode)
+
+		machine := New()
+		machine.SetOutput(io.Discard)
+		machine.SetInstructionBudget(fuzzInstructionBudget)
+		machine.Run(bytecode)
+	})
+}
+
+// FuzzPipeline runs source code through the whole pipeline: lexer, parser, ASTCompiler and VM.
+// No stage is allowed to panic, every failure must be reported as an error.
+func FuzzPipeline(f *testing.F) {
+	seeds := []string{
+		"1 + 2 * 3",
+		"var a = 1\nprint a",
+		"print 1 / 0",
+		"print -true",
+		"var n = 0\nwhile n < 10 { n = n + 1 }\nprint n",
+		"while true { }",
+		"{ var a = 1 { var b = a + 1 print b } }",
+		"if 1 > 2 { print 1 } else { print 2 }",
+		"print \"a\" + 1",
+		"try { print 1 / 0 } catch (e) { print e } finally { print 1 }",
+		"try { throw 1 } finally { }",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, source string) {
+		tokens, lexErrs := lexer.New(source).Scan()
+		if len(lexErrs) > 0 {
+			return
+		}
+		statements, parseErrs := parser.Make(tokens).Parse()
+		if len(parseErrs) > 0 {
+			return
+		}
+		bytecode, errs := compiler.NewASTCompiler().CompileAST(statements)
+		if len(errs) > 0 {
+			return
+		}
+		machine := New()
+		machine.SetOutput(io.Discard)
+		machine.SetInstructionBudget(fuzzInstructionBudget)
+		machine.Run(bytecode)
+	})
+}
@@ -0,0 +1,34 @@
+package vm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"nilan/compiler"
+	"os"
+	"strings"
+)
+
+// LoadBytecode reads a `.nic` file written by `ASTCompiler.DumpBytecode` and decodes it.
+//
+// Bytecode read from disk is not trusted, the file could have been corrupted, crafted by hand
+// or written by a different version of Nilan. So the decoded bytecode is checked with
+// `compiler.Verify` and an error is returned if it is malformed, instead of letting the VM execute it.
+func LoadBytecode(filePath string) (compiler.Bytecode, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return compiler.Bytecode{}, err
+	}
+	decoded, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return compiler.Bytecode{}, fmt.Errorf("bytecode file '%s' is not hexadecimal encoded: %v", filePath, err)
+	}
+
+	var bytecode compiler.Bytecode
+	if err := bytecode.UnmarshalBinary(decoded); err != nil {
+		return compiler.Bytecode{}, fmt.Errorf("failed to decode bytecode file '%s': %v", filePath, err)
+	}
+	if err := compiler.Verify(bytecode); err != nil {
+		return compiler.Bytecode{}, err
+	}
+	return bytecode, nil
+}
@@ -0,0 +1,53 @@
+package vm
+
+import (
+	"encoding/hex"
+	"io"
+	"nilan/compiler"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeBytecodeFile(t *testing.T, bytecode compiler.Bytecode) string {
+	t.Helper()
+	data, err := bytecode.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "program.nic")
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(data)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadBytecode(t *testing.T) {
+	path := writeBytecodeFile(t, compiler.Bytecode{
+		Instructions:  []byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_CONSTANT), 0, 1, byte(compiler.OP_ADD), byte(compiler.OP_END)},
+		ConstantsPool: []any{int64(2), int64(3)},
+	})
+	bytecode, err := LoadBytecode(path)
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+
+	vm := New()
+	vm.SetOutput(io.Discard)
+	if err := vm.Run(bytecode); err != nil {
+		t.Fatalf("runtime error: %v", err)
+	}
+	if got := vm.stack.Peek(); got != int64(5) {
+		t.Errorf("got: %v, want: 5", got)
+	}
+}
+
+func TestLoadBytecodeRejectsMalformedBytecode(t *testing.T) {
+	path := writeBytecodeFile(t, compiler.Bytecode{
+		Instructions: []byte{byte(compiler.OP_CONSTANT), 0, 7, byte(compiler.OP_END)},
+	})
+	_, err := LoadBytecode(path)
+	if _, ok := err.(compiler.VerificationError); !ok {
+		t.Fatalf("expected a VerificationError, got: %v", err)
+	}
+}
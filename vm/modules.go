@@ -0,0 +1,126 @@
+package vm
+
+import (
+	"fmt"
+	"maps"
+	"nilan/compiler"
+	"nilan/stdlib"
+	"nilan/value"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Importer finds and compiles the modules imported by `import` statements.
+type Importer interface {
+	// Import returns the canonical path of the module imported as `path` by the module whose
+	// canonical path is `from`, "" for a program which is not a file, and the module's bytecode.
+	// The canonical path identifies the module, a module imported with different paths is only
+	// executed once.
+	Import(path string, from string) (string, compiler.Bytecode, error)
+}
+
+// SetImporter sets the importer of the modules imported by the programs executed by the VM, and
+// the canonical path of the main program, "" if it is not a file. Without an importer `import`
+// statements raise a runtime error.
+func (vm *VirtualMachine) SetImporter(importer Importer, main string) {
+	vm.importer = importer
+	vm.main = main
+}
+
+// execImportInstruction pushes the module whose path is the constant referenced by the
+// instruction's operand. The first time a module is imported it is executed in its own global
+// namespace, every variable it declares at the top level whose name does not start with `_` is
+// a member of the module.
+// It returns the number of bytes consumed by the instruction, or a RuntimeError if the module
+// can not be imported, is part of an import cycle or fails.
+func (vm *VirtualMachine) execImportInstruction(bytecode compiler.Bytecode) (int, error) {
+	operand := int(vm.getOperand(bytecode))
+	if operand >= len(bytecode.ConstantsPool) {
+		return 0, RuntimeError{Message: fmt.Sprintf("constant index %d is out of range", operand)}
+	}
+	path, _ := bytecode.ConstantsPool[operand].(string)
+	if vm.importer == nil {
+		return 0, RuntimeError{Message: fmt.Sprintf("can not import '%s', modules can not be imported by this program", path)}
+	}
+
+	from := vm.main
+	if len(vm.importing) > 0 {
+		from = vm.importing[len(vm.importing)-1]
+	}
+	canonical, moduleBytecode, err := vm.importer.Import(path, from)
+	if err != nil {
+		return 0, RuntimeError{Message: err.Error()}
+	}
+	if module, ok := vm.modules[canonical]; ok {
+		vm.stack.Push(module)
+		return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+	}
+	if canonical == vm.main || slices.Contains(vm.importing, canonical) {
+		return 0, RuntimeError{Message: importCycle(vm.main, vm.importing, canonical)}
+	}
+
+	module, err := vm.runModule(canonical, moduleBytecode)
+	if err != nil {
+		return 0, err
+	}
+	if vm.modules == nil {
+		vm.modules = map[string]*value.Module{}
+	}
+	vm.modules[canonical] = module
+	vm.stack.Push(module)
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+}
+
+// runModule executes the bytecode of a module in a new global namespace and returns the module.
+// The state of the importing program is restored afterwards.
+func (vm *VirtualMachine) runModule(path string, bytecode compiler.Bytecode) (*value.Module, error) {
+	builtins := stdlib.Globals(vm.host)
+	ip, stack, handlers, globals := vm.ip, vm.stack, vm.handlers, vm.globalVars
+	vm.ip, vm.stack, vm.handlers, vm.globalVars = 0, Stack{}, nil, maps.Clone(builtins)
+	vm.importing = append(vm.importing, path)
+
+	err := vm.Run(bytecode)
+	moduleGlobals := vm.globalVars
+	vm.ip, vm.stack, vm.handlers, vm.globalVars = ip, stack, handlers, globals
+	vm.importing = vm.importing[:len(vm.importing)-1]
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch e := err.(type) {
+	case nil:
+	case RuntimeError:
+		// NOTE: The line of the error is in the module, the line of the import is added by the caller.
+		// Errors of modules imported by the module already describe where they were raised.
+		if e.module != "" {
+			return nil, RuntimeError{Message: e.Message, module: e.module}
+		}
+		return nil, RuntimeError{Message: fmt.Sprintf("%s (in module '%s', line %d)", e.Message, name, e.Start.Line), module: name}
+	case ThrownError:
+		// NOTE: Values thrown by a module can be caught by the importing program.
+		return nil, ThrownError{Value: e.Value}
+	default:
+		return nil, err
+	}
+
+	members := map[string]any{}
+	for member, val := range moduleGlobals {
+		if builtin, ok := builtins[member]; (ok && builtin == val) || strings.HasPrefix(member, "_") {
+			continue
+		}
+		members[member] = val
+	}
+	return &value.Module{Name: name, Members: members}, nil
+}
+
+// importCycle describes the chain of imports from the main program to the module which is
+// imported again, e.g "import cycle: main.ni -> a.ni -> main.ni".
+func importCycle(main string, importing []string, path string) string {
+	var names []string
+	for _, module := range append([]string{main}, importing...) {
+		if module != "" {
+			names = append(names, filepath.Base(module))
+		}
+	}
+	names = append(names, filepath.Base(path))
+	return "import cycle: " + strings.Join(names, " -> ")
+}
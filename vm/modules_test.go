@@ -0,0 +1,140 @@
+package vm
+
+import (
+	"fmt"
+	"nilan/compiler"
+	"nilan/lexer"
+	"nilan/parser"
+	"strings"
+	"testing"
+)
+
+// fakeImporter imports modules from sources held in memory, the path of a module is its canonical path.
+type fakeImporter struct {
+	sources map[string]string
+}
+
+func (importer *fakeImporter) Import(path string, from string) (string, compiler.Bytecode, error) {
+	source, ok := importer.sources[path]
+	if !ok {
+		return "", compiler.Bytecode{}, fmt.Errorf("module '%s' not found", path)
+	}
+	tokens, _ := lexer.New(source).Scan()
+	statements, parseErrs := parser.Make(tokens).Parse()
+	if len(parseErrs) > 0 {
+		return "", compiler.Bytecode{}, parseErrs[0]
+	}
+	bytecode, errs := compiler.NewASTCompiler().CompileAST(statements)
+	if len(errs) > 0 {
+		return "", compiler.Bytecode{}, errs[0]
+	}
+	return path, bytecode, nil
+}
+
+func TestImport(t *testing.T) {
+	importer := &fakeImporter{sources: map[string]string{
+		"lib.ni":     "var greeting = \"hello\"\nvar _secret = 1\nprint \"loading lib\"",
+		"counter.ni": "import \"lib.ni\" as lib\nvar count = 2\nvar size = len(lib.greeting)",
+		"broken.ni":  "var a = 1\nprint a + \"x\" * 2",
+		"cycle_a.ni": "import \"cycle_b.ni\" as b",
+		"cycle_b.ni": "import \"cycle_a.ni\" as a",
+		"echo.ni":    "var x = 1\n42",
+	}}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "members",
+			source: "import \"lib.ni\" as lib\nprint lib.greeting\nvar greeting = 2\nprint greeting",
+			want:   "loading lib\nhello\n2\n",
+		},
+		{
+			name:   "modules are executed once",
+			source: "import \"lib.ni\" as a\nimport \"counter.ni\" as c\nimport \"lib.ni\" as b\nprint c.size\nprint c.count",
+			want:   "loading lib\n5\n2\n",
+		},
+		{
+			name:    "private members",
+			source:  "import \"lib.ni\" as lib\nprint lib._secret",
+			want:    "loading lib\n",
+			wantErr: "has no member '_secret'",
+		},
+		{
+			name:    "builtins are not members",
+			source:  "import \"lib.ni\" as lib\nprint lib.sqrt",
+			want:    "loading lib\n",
+			wantErr: "has no member 'sqrt'",
+		},
+		{
+			name:   "the last expression of a module is not echoed",
+			source: "import \"echo.ni\" as echo",
+			want:   "",
+		},
+		{
+			name:    "module runtime error",
+			source:  "print 1\nimport \"broken.ni\" as broken",
+			want:    "1\n",
+			wantErr: "(in module 'broken', line 2)",
+		},
+		{
+			name:    "cycle",
+			source:  "import \"cycle_a.ni\" as a",
+			wantErr: "import cycle: cycle_a.ni -> cycle_b.ni -> cycle_a.ni",
+		},
+		{
+			name:    "not found",
+			source:  "import \"missing.ni\" as missing",
+			wantErr: "module 'missing.ni' not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine := New()
+			machine.SetImporter(importer, "")
+			out, err := runSourceWith(t, tt.source, machine)
+			if out != tt.want {
+				t.Errorf("got output: %q, want: %q", out, tt.want)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("got error: %v, want: %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportThrownValue(t *testing.T) {
+	importer := &fakeImporter{sources: map[string]string{"throws.ni": "throw \"from module\""}}
+	machine := New()
+	machine.SetImporter(importer, "")
+	_, err := runSourceWith(t, "import \"throws.ni\" as t", machine)
+	thrown, ok := err.(ThrownError)
+	if !ok || thrown.Value != "from module" {
+		t.Errorf("got error: %#v, want the thrown value 'from module'", err)
+	}
+}
+
+func TestImportWithoutImporter(t *testing.T) {
+	_, err := runSource(t, "import \"lib.ni\" as lib")
+	want := "can not import 'lib.ni', modules can not be imported by this program"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error: %v, want: %s", err, want)
+	}
+}
+
+func TestImportCycleWithMain(t *testing.T) {
+	importer := &fakeImporter{sources: map[string]string{"main.ni": "", "lib.ni": "import \"main.ni\" as main"}}
+	machine := New()
+	machine.SetImporter(importer, "main.ni")
+	_, err := runSourceWith(t, "import \"lib.ni\" as lib", machine)
+	want := "import cycle: main.ni -> lib.ni -> main.ni"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error: %v, want: %s", err, want)
+	}
+}
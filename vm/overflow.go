@@ -0,0 +1,251 @@
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"nilan/compiler"
+	"nilan/value"
+)
+
+// IntegerOverflow determines what the VM does when the result of an integer operation
+// does not fit in an int64.
+type IntegerOverflow int
+
+const (
+	// The result wraps around, e.g `9223372036854775807 + 1` is -9223372036854775808.
+	OverflowWrap IntegerOverflow = iota
+	// A RuntimeError is raised, which reports the operation and its line.
+	OverflowCheck
+	// The result is promoted to an arbitrary-precision integer, a *big.Int. Results which fit
+	// in an int64 are always int64 values, e.g `(9223372036854775807 + 1) - 1` is an int64.
+	OverflowPromote
+)
+
+func (overflow IntegerOverflow) String() string {
+	switch overflow {
+	case OverflowWrap:
+		return "wrap"
+	case OverflowCheck:
+		return "check"
+	case OverflowPromote:
+		return "promote"
+	}
+	return fmt.Sprintf("IntegerOverflow(%d)", int(overflow))
+}
+
+// ParseIntegerOverflow returns the IntegerOverflow named `name`: "wrap", "check" or "promote".
+func ParseIntegerOverflow(name string) (IntegerOverflow, error) {
+	for _, overflow := range []IntegerOverflow{OverflowWrap, OverflowCheck, OverflowPromote} {
+		if overflow.String() == name {
+			return overflow, nil
+		}
+	}
+	return OverflowWrap, fmt.Errorf("unknown integer overflow mode: '%s', expected 'wrap', 'check' or 'promote'", name)
+}
+
+// maxIntegerBits is the largest size of an arbitrary-precision integer, it guards against
+// programs such as `2 ** 10000000000` exhausting the memory of the host.
+const maxIntegerBits = 1 << 16
+
+type checkedFuncInt func(a int64, b int64) (int64, bool)
+type bigFunc func(a *big.Int, b *big.Int) *big.Int
+
+// checkedIntOperations maps the opcodes of the integer operations which can overflow to
+// the functions detecting it.
+var checkedIntOperations = map[compiler.Opcode]checkedFuncInt{
+	compiler.OP_ADD:          value.AddIntChecked,
+	compiler.OP_SUBTRACT:     value.SubIntChecked,
+	compiler.OP_MULTIPLY:     value.MultIntChecked,
+	compiler.OP_FLOOR_DIVIDE: value.FloorDivIntChecked,
+	compiler.OP_POWER:        value.PowIntChecked,
+	compiler.OP_SHIFT_LEFT:   value.ShiftLeftIntChecked,
+}
+
+// bigIntOperations maps the opcodes of the integer operations to their arbitrary-precision
+// implementation. Division by zero, negative exponents and negative shift counts are handled
+// before these functions are called.
+var bigIntOperations = map[compiler.Opcode]bigFunc{
+	compiler.OP_ADD:          func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Add(a, b) },
+	compiler.OP_SUBTRACT:     func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Sub(a, b) },
+	compiler.OP_MULTIPLY:     func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Mul(a, b) },
+	compiler.OP_FLOOR_DIVIDE: value.FloorDivBig,
+	compiler.OP_MODULO:       value.ModBig,
+	compiler.OP_POWER:        func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Exp(a, b, nil) },
+	compiler.OP_BITWISE_AND:  func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).And(a, b) },
+	compiler.OP_BITWISE_OR:   func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Or(a, b) },
+	compiler.OP_BITWISE_XOR:  func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Xor(a, b) },
+	compiler.OP_SHIFT_LEFT:   func(a *big.Int, b *big.Int) *big.Int { return new(big.Int).Lsh(a, uint(b.Int64())) },
+	compiler.OP_SHIFT_RIGHT: func(a *big.Int, b *big.Int) *big.Int {
+		// NOTE: Shifting by the length of `a` or more results in 0, or -1 for negative values.
+		count := int64(a.BitLen())
+		if b.IsInt64() && b.Int64() < count {
+			count = b.Int64()
+		}
+		return new(big.Int).Rsh(a, uint(count))
+	},
+}
+
+// operatorSymbols maps the opcodes of the arithmetic operations to their operator, for error messages.
+var operatorSymbols = map[compiler.Opcode]string{
+	compiler.OP_ADD:          "+",
+	compiler.OP_SUBTRACT:     "-",
+	compiler.OP_MULTIPLY:     "*",
+	compiler.OP_DIVIDE:       "/",
+	compiler.OP_FLOOR_DIVIDE: "//",
+	compiler.OP_MODULO:       "%",
+	compiler.OP_POWER:        "**",
+	compiler.OP_SHIFT_LEFT:   "<<",
+}
+
+// SetIntegerOverflow sets what the VM does when the result of an integer operation does not fit
+// in an int64. The default is OverflowWrap.
+func (vm *VirtualMachine) SetIntegerOverflow(overflow IntegerOverflow) {
+	vm.overflow = overflow
+}
+
+// isBigInt determines if a value is an arbitrary-precision integer.
+func isBigInt(value any) bool {
+	_, ok := value.(*big.Int)
+	return ok
+}
+
+// toBigInt converts an int64 or a *big.Int to a *big.Int.
+func toBigInt(value any) (*big.Int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return big.NewInt(v), true
+	case *big.Int:
+		return v, true
+	}
+	return nil, false
+}
+
+// toFloat converts an int64, a float64 or a *big.Int to a float64. Integers too large for a
+// float64 are converted to an infinity.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f, true
+	}
+	return 0, false
+}
+
+// normalizeBigInt returns an integer as an int64 if it fits in one.
+func normalizeBigInt(n *big.Int) any {
+	if n.IsInt64() {
+		return n.Int64()
+	}
+	return n
+}
+
+// intOperation applies an integer operation, handling an overflow according to the VM's
+// IntegerOverflow mode.
+func (vm *VirtualMachine) intOperation(opCode compiler.Opcode, operation arithmeticFuncInt, a int64, b int64) (any, error) {
+	checked, ok := checkedIntOperations[opCode]
+	if vm.overflow == OverflowWrap || !ok {
+		return operation(a, b), nil
+	}
+	if result, ok := checked(a, b); ok {
+		return result, nil
+	}
+	if vm.overflow == OverflowCheck {
+		return nil, RuntimeError{Message: fmt.Sprintf("integer overflow: %d %s %d", a, operatorSymbols[opCode], b)}
+	}
+	return bigIntOperation(opCode, big.NewInt(a), big.NewInt(b))
+}
+
+// negateInt negates an integer, handling the overflow of `-(-9223372036854775808)` according
+// to the VM's IntegerOverflow mode.
+func (vm *VirtualMachine) negateInt(a int64) (any, error) {
+	result, ok := value.NegIntChecked(a)
+	if ok || vm.overflow == OverflowWrap {
+		return result, nil
+	}
+	if vm.overflow == OverflowCheck {
+		return nil, RuntimeError{Message: fmt.Sprintf("integer overflow: -(%d)", a)}
+	}
+	return new(big.Int).Neg(big.NewInt(a)), nil
+}
+
+// bigIntOperation applies an integer operation with arbitrary precision.
+// A RuntimeError is returned if the result would be larger than maxIntegerBits.
+func bigIntOperation(opCode compiler.Opcode, a *big.Int, b *big.Int) (any, error) {
+	// NOTE: The size of powers and left shifts is estimated before they are computed,
+	// since computing them may exhaust the memory.
+	tooLarge := false
+	switch opCode {
+	case compiler.OP_POWER:
+		if a.CmpAbs(big.NewInt(1)) > 0 {
+			tooLarge = !b.IsInt64() || b.Int64() > maxIntegerBits || int64(a.BitLen()-1)*b.Int64() > maxIntegerBits
+		}
+	case compiler.OP_SHIFT_LEFT:
+		if a.Sign() != 0 {
+			tooLarge = !b.IsInt64() || b.Int64() > maxIntegerBits
+		}
+	}
+
+	var result *big.Int
+	if !tooLarge {
+		result = bigIntOperations[opCode](a, b)
+		tooLarge = result.BitLen() > maxIntegerBits
+	}
+	if tooLarge {
+		return nil, RuntimeError{Message: fmt.Sprintf("integer overflow: the result is larger than %d bits", maxIntegerBits)}
+	}
+	return normalizeBigInt(result), nil
+}
+
+// bigArithmetic applies an arithmetic operation to operands of which at least one is a *big.Int.
+// Operations with a float and divisions are computed with floats, as they are for int64 values.
+func bigArithmetic(operationFloat arithmeticFuncFloat, opCode compiler.Opcode, a any, b any) (any, error) {
+	aBig, isABig := toBigInt(a)
+	bBig, isBBig := toBigInt(b)
+	if isABig && isBBig && opCode != compiler.OP_DIVIDE && !(opCode == compiler.OP_POWER && bBig.Sign() < 0) {
+		return bigIntOperation(opCode, aBig, bBig)
+	}
+
+	aFloat, isANumeric := toFloat(a)
+	bFloat, isBNumeric := toFloat(b)
+	if !isANumeric || !isBNumeric {
+		return nil, RuntimeError{Message: fmt.Sprintf("operands must be numeric values: %v,%v", formatOperand(a), formatOperand(b))}
+	}
+	return operationFloat(aFloat, bFloat), nil
+}
+
+// isArbitraryPrecision determines if a value is an arbitrary-precision integer or a decimal.
+func isArbitraryPrecision(value any) bool {
+	return isBigInt(value) || isDecimal(value)
+}
+
+// valuesEqual determines if two values are equal, comparing arbitrary-precision integers
+// and decimals by their value, e.g `1.50d == 1.5d` and `1.0d == 1` are true, lists and maps
+// by their elements and struct instances by their fields.
+func valuesEqual(a any, b any) bool {
+	if isDecimal(a) || isDecimal(b) {
+		aDecimal, isADecimal := toDecimal(a)
+		bDecimal, isBDecimal := toDecimal(b)
+		return isADecimal && isBDecimal && aDecimal.Cmp(bDecimal) == 0
+	}
+	aBig, isABig := a.(*big.Int)
+	bBig, isBBig := b.(*big.Int)
+	if isABig && isBBig {
+		return aBig.Cmp(bBig) == 0
+	}
+	switch x := a.(type) {
+	case *value.List:
+		y, ok := b.(*value.List)
+		return ok && x.Equal(y, valuesEqual)
+	case *value.Map:
+		y, ok := b.(*value.Map)
+		return ok && x.Equal(y, valuesEqual)
+	case *value.Instance:
+		y, ok := b.(*value.Instance)
+		return ok && x.Equal(y, valuesEqual)
+	}
+	return a == b
+}
@@ -0,0 +1,181 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wrap    string
+		check   string
+		promote string
+	}{
+		{
+			name:    "addition",
+			source:  "print 9223372036854775807 + 1",
+			wrap:    "-9223372036854775808\n",
+			check:   "integer overflow: 9223372036854775807 + 1",
+			promote: "9223372036854775808\n",
+		},
+		{
+			name:    "subtraction",
+			source:  "print -9223372036854775807 - 2",
+			wrap:    "9223372036854775807\n",
+			check:   "integer overflow: -9223372036854775807 - 2",
+			promote: "-9223372036854775809\n",
+		},
+		{
+			name:    "multiplication",
+			source:  "print 4294967296 * 4294967296",
+			wrap:    "0\n",
+			check:   "integer overflow: 4294967296 * 4294967296",
+			promote: "18446744073709551616\n",
+		},
+		{
+			name:    "power",
+			source:  "print 3 ** 40",
+			wrap:    "-6289078614652622815\n",
+			check:   "integer overflow: 3 ** 40",
+			promote: "12157665459056928801\n",
+		},
+		{
+			name:    "floor division",
+			source:  "var a = -9223372036854775807 - 1\nprint a // -1",
+			wrap:    "-9223372036854775808\n",
+			check:   "integer overflow: -9223372036854775808 // -1",
+			promote: "9223372036854775808\n",
+		},
+		{
+			name:    "negation",
+			source:  "var a = -9223372036854775807 - 1\nprint -a",
+			wrap:    "-9223372036854775808\n",
+			check:   "integer overflow: -(-9223372036854775808)",
+			promote: "9223372036854775808\n",
+		},
+		{
+			name:    "left shift",
+			source:  "print 3 << 62",
+			wrap:    "-4611686018427387904\n",
+			check:   "integer overflow: 3 << 62",
+			promote: "13835058055282163712\n",
+		},
+		{
+			name:    "no overflow",
+			source:  "print 9223372036854775806 + 1\nprint -4 ** 3\nprint 1 << 62",
+			wrap:    "9223372036854775807\n-64\n4611686018427387904\n",
+			promote: "9223372036854775807\n-64\n4611686018427387904\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, overflow := range []IntegerOverflow{OverflowWrap, OverflowCheck, OverflowPromote} {
+				vm := New()
+				vm.SetIntegerOverflow(overflow)
+				got, err := runSourceWith(t, tt.source, vm)
+
+				want := map[IntegerOverflow]string{OverflowWrap: tt.wrap, OverflowCheck: tt.check, OverflowPromote: tt.promote}[overflow]
+				if overflow == OverflowCheck && tt.check != "" {
+					runtimeErr, ok := err.(RuntimeError)
+					if !ok || runtimeErr.Message != want || runtimeErr.Start.Line == 0 {
+						t.Errorf("%v: got error %v, want a located RuntimeError %q", overflow, err, want)
+					}
+					continue
+				}
+				if overflow == OverflowCheck {
+					want = tt.wrap
+				}
+				if err != nil {
+					t.Fatalf("%v: unexpected error: %v", overflow, err)
+				}
+				if got != want {
+					t.Errorf("%v: got output %q, want %q", overflow, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "results which fit are int64 values",
+			source: "var a = 9223372036854775807 + 1\nprint a - 1\nprint a - 1 == 9223372036854775807",
+			want:   "9223372036854775807\ntrue\n",
+		},
+		{
+			name:   "arithmetic",
+			source: "var a = 2 ** 64\nprint a * a\nprint a // 3\nprint -a % 7\nprint -a // 7\nprint a / 4\nprint a + 0.5",
+			want:   "340282366920938463463374607431768211456\n6148914691236517205\n5\n-2635249153387078803\n4.611686018427388e+18\n1.8446744073709552e+19\n",
+		},
+		{
+			name:   "comparison",
+			source: "var a = 2 ** 64\nprint a > 9223372036854775807\nprint a < 2 ** 65\nprint a >= 18446744073709551615.0\nprint -a < 0.5\nprint a == 2 ** 64\nprint a != 2 ** 65\nprint a == 18446744073709551616.0",
+			want:   "true\ntrue\ntrue\ntrue\ntrue\ntrue\nfalse\n",
+		},
+		{
+			name:   "bitwise operators",
+			source: "var a = 1 << 64\nprint a | 1\nprint (a | 5) & 7\nprint ~a\nprint a >> 63\nprint a >> 1000",
+			want:   "18446744073709551617\n5\n-18446744073709551617\n2\n0\n",
+		},
+		{
+			name:   "printed in strings",
+			source: "print \"${2 ** 70}!\"",
+			want:   "1180591620717411303424!\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := New()
+			vm.SetIntegerOverflow(OverflowPromote)
+			got, err := runSourceWith(t, tt.source, vm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("size is limited", func(t *testing.T) {
+		for _, source := range []string{"print 2 ** 100000", "print 1 << 100000", "var a = 2 ** 40000\nprint a * a"} {
+			vm := New()
+			vm.SetIntegerOverflow(OverflowPromote)
+			_, err := runSourceWith(t, source, vm)
+			if _, ok := err.(RuntimeError); !ok {
+				t.Errorf("%q: got error %v, want a RuntimeError", source, err)
+			}
+		}
+	})
+}
+
+func TestParseIntegerOverflow(t *testing.T) {
+	for _, overflow := range []IntegerOverflow{OverflowWrap, OverflowCheck, OverflowPromote} {
+		got, err := ParseIntegerOverflow(overflow.String())
+		if err != nil || got != overflow {
+			t.Errorf("ParseIntegerOverflow(%q) = %v, %v, want %v", overflow.String(), got, err, overflow)
+		}
+	}
+	if _, err := ParseIntegerOverflow("saturate"); err == nil {
+		t.Errorf("got no error for an unknown mode")
+	}
+}
+
+func TestNormalizeBigInt(t *testing.T) {
+	if got := normalizeBigInt(big.NewInt(-5)); got != int64(-5) {
+		t.Errorf("got %v (%T), want int64 -5", got, got)
+	}
+	large := new(big.Int).Lsh(big.NewInt(1), 63)
+	if got := normalizeBigInt(large); got != large {
+		t.Errorf("got %v (%T), want the *big.Int", got, got)
+	}
+}
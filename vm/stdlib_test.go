@@ -0,0 +1,138 @@
+package vm
+
+import (
+	"nilan/stdlib"
+	"strings"
+	"testing"
+)
+
+func TestNativeFunctions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "globals and the math module",
+			source: "print sqrt(16)\nprint math.sqrt(16)\nprint math.floor == floor",
+			want:   "4\n4\ntrue\n",
+		},
+		{
+			name:   "integer results",
+			source: "print floor(-2.5) + 1\nprint ceil(2.1) // 2\nprint round(2.5) + round(3.5)\nprint int(\"42\") + int(-2.9)",
+			want:   "-2\n1\n6\n40\n",
+		},
+		{
+			name:   "arguments are evaluated in order",
+			source: "var a = 1\nprint max(a = 2, a * 10, a)\nprint min(3, -1.5, 2)",
+			want:   "20\n-1.5\n",
+		},
+		{
+			name:   "decimals and big integers",
+			source: "print abs(-1.50d)\nprint round(2.345d, 2)\nprint max(1.5d, 2 ** 62)",
+			want:   "1.50\n2.34\n4611686018427387904\n",
+		},
+		{
+			name:   "functions are values",
+			source: "var f = math.max\n{ var g = f print g(1, 2) }\nprint f\nprint math",
+			want:   "2\n<native fn max>\n<module math>\n",
+		},
+		{
+			name:   "builtins can be redefined",
+			source: "print abs(-1)\nvar abs = 2\nprint abs\nprint math.abs(-3)",
+			want:   "1\n2\n3\n",
+		},
+		{
+			name:   "errors can be caught",
+			source: "try { sqrt(1, 2) } catch (e) { print e }",
+			want:   "Error: sqrt() takes 1 argument, got 2 (line 1)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runSource(t, tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNativeFunctionErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "print 1\nprint pow(1)", want: "pow() takes 2 arguments, got 1"},
+		{source: "print 1\nprint max()", want: "max() takes at least 1 argument, got 0"},
+		{source: "print 1\nprint round(1, 2, 3)", want: "round() takes 1 or 2 arguments, got 3"},
+		{source: "print 1\nprint sqrt(\"a\")", want: "sqrt() expects a number, got 'a'"},
+		{source: "print 1\nprint int(math.inf)", want: "int() can not convert +Inf to an integer"},
+		{source: "print 1\nprint 1(2)", want: "can only call functions and structs, got 1"},
+		{source: "print 1\nprint null()", want: "can only call functions and structs, got null"},
+		{source: "print 1\nprint math.tau", want: "module 'math' has no member 'tau'"},
+		{source: "print 1\nprint sqrt.name", want: "only modules, maps, struct instances and errors have properties, got <native fn sqrt>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := runSource(t, tt.source)
+			runtimeErr, ok := err.(RuntimeError)
+			if !ok || runtimeErr.Message != tt.want {
+				t.Fatalf("got error: %v, want a RuntimeError %q", err, tt.want)
+			}
+			if runtimeErr.Start.Line != 2 {
+				t.Errorf("got line %d, want 2", runtimeErr.Start.Line)
+			}
+		})
+	}
+}
+
+func TestSystemFunctions(t *testing.T) {
+	vm := New()
+	vm.SetHost(stdlib.Host{Args: []string{"input.txt"}, Stdin: strings.NewReader("line\n"), AllowStdin: true})
+	got, err := runSourceWith(t, "print args()\nprint read_line()\nprint read_line()", vm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "[\"input.txt\"]\nline\nnull\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+}
+
+func TestExitIsNotCaught(t *testing.T) {
+	got, err := runSource(t, "try {\n  print 1\n  exit(2)\n} catch (e) {\n  print e\n}\nprint 3")
+	if exit, ok := err.(stdlib.ExitError); !ok || exit.Code != 2 {
+		t.Errorf("got error: %v, want exit(2)", err)
+	}
+	if got != "1\n" {
+		t.Errorf("got output %q, want the output before exiting", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	source := `var doc = json_parse("{\"name\": \"nilan\", \"tags\": [\"a\", \"b\"]}")
+print doc.name
+print len(doc.tags)
+print json_stringify(doc)
+print doc == json_parse("{\"tags\": [\"a\", \"b\"], \"name\": \"nilan\"}")
+try {
+  json_parse("{\"name\": }")
+} catch (e) {
+  print e
+}
+print doc.version`
+	got, err := runSource(t, source)
+	want := "nilan\n2\n{\"name\":\"nilan\",\"tags\":[\"a\",\"b\"]}\ntrue\n" +
+		"Error: json_parse() missing value after object key at line 1, column 10 (line 7)\n"
+	if got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+	if runtimeErr, ok := err.(RuntimeError); !ok || runtimeErr.Message != "map has no key 'version'" || runtimeErr.Start.Line != 11 {
+		t.Errorf("got error: %v, want a RuntimeError on line 11", err)
+	}
+}
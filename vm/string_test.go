@@ -0,0 +1,134 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStringModule(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "len counts runes",
+			source: "print len(\"héllo\")\nprint string.len(\"\")\nprint len(\"😀\")",
+			want:   "5\n0\n1\n",
+		},
+		{
+			name:   "split and join",
+			source: "var parts = string.split(\"a,b,,c\", \",\")\nprint parts\nprint len(parts)\nprint string.join(parts, \"-\")\nprint string.split(\"hé\", \"\")",
+			want:   "[\"a\", \"b\", \"\", \"c\"]\n4\na-b--c\n[\"h\", \"é\"]\n",
+		},
+		{
+			name:   "trim",
+			source: "print \"[${string.trim(\"  a b \\t\\n\")}]\"\nprint string.trim(\"xxaxx\", \"x\")",
+			want:   "[a b]\na\n",
+		},
+		{
+			name:   "upper and lower",
+			source: "print string.upper(\"héllo\")\nprint string.lower(\"ÉCOLE\")",
+			want:   "HÉLLO\nécole\n",
+		},
+		{
+			name:   "contains, starts_with and ends_with",
+			source: "print string.contains(\"nilan\", \"la\")\nprint string.starts_with(\"nilan\", \"ni\")\nprint string.ends_with(\"nilan\", \"ni\")",
+			want:   "true\ntrue\nfalse\n",
+		},
+		{
+			name:   "replace",
+			source: "print string.replace(\"a.b.c\", \".\", \"::\")\nprint string.replace(\"ab\", \"\", \"-\")",
+			want:   "a::b::c\n-a-b-\n",
+		},
+		{
+			name:   "substring",
+			source: "print string.substring(\"héllo\", 1, 3)\nprint string.substring(\"hello\", -3)\nprint string.substring(\"hello\", 2, 100)\nprint \"[${string.substring(\"hello\", 4, 1)}]\"",
+			want:   "él\nllo\nllo\n[]\n",
+		},
+		{
+			name:   "index_of",
+			source: "print string.index_of(\"héllo\", \"l\")\nprint string.index_of(\"hello\", \"z\")",
+			want:   "2\n-1\n",
+		},
+		{
+			name:   "repeat",
+			source: "print string.repeat(\"ab\", 3)\nprint \"[${string.repeat(\"ab\", 0)}]\"",
+			want:   "ababab\n[]\n",
+		},
+		{
+			name:   "format",
+			source: "print string.format(\"{} + {0} = {}\", 1, 2)\nprint string.format(\"{{{}}} is {}\", null, 1.5d)",
+			want:   "1 + 1 = 2\n{null} is 1.5\n",
+		},
+		{
+			name:   "functions are module members",
+			source: "var upper = string.upper\nprint upper(\"a\")\nprint string\nprint string.len == len",
+			want:   "A\n<module string>\ntrue\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runSource(t, tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringModuleErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "print len(1)", want: "len() expects a string, a list or a map, got 1"},
+		{source: "print string.upper(1)", want: "upper() expects a string, got 1"},
+		{source: "print string.split(\"a\")", want: "split() takes 2 arguments, got 1"},
+		{source: "print string.join(\"a\", \",\")", want: "join() expects a list, got 'a'"},
+		{source: "print string.substring(\"a\", 1.5)", want: "substring() expects an integer, got 1.5"},
+		{source: "print string.repeat(\"a\", -1)", want: "repeat() count must not be negative, got -1"},
+		{source: "print string.repeat(\"ab\", 10000000000)", want: "repeat() result is longer than 16777216 bytes"},
+		{source: "print string.replace(string.repeat(\"a\", 1000000), \"a\", \"aaaaaaaaaaaaaaaaaaaa\")", want: "replace() result is longer than 16777216 bytes"},
+		{source: "print string.format(\"{} {}\", 1)", want: "format() placeholder 1 has no argument, got 1 arguments"},
+		{source: "print string.format(\"{x}\", 1)", want: "format() placeholder '{x}' must be empty or an index"},
+		{source: "print string.format(\"{\", 1)", want: "format() placeholder at index 0 is not closed, use '{{' to write '{'"},
+		{source: "print string.format(\"a}\")", want: "format() '}' at index 1 is not part of a placeholder, use '}}' to write '}'"},
+		{source: "print string.reverse(\"a\")", want: "module 'string' has no member 'reverse'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source[:min(len(tt.source), 40)], func(t *testing.T) {
+			_, err := runSource(t, tt.source)
+			runtimeErr, ok := err.(RuntimeError)
+			if !ok || runtimeErr.Message != tt.want {
+				t.Errorf("got error: %v, want a RuntimeError %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringModuleCatchesErrors(t *testing.T) {
+	got, err := runSource(t, "try {\n  string.upper(null)\n} catch (e) {\n  print e\n}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Error: upper() expects a string, got null (line 2)"; strings.TrimSpace(got) != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+}
+
+func TestInterpolationLengthLimit(t *testing.T) {
+	// NOTE: The string doubles on every iteration, it exceeds the limit long before the
+	// instruction budget is exhausted.
+	_, err := runSource(t, "var s = \"x\"\nwhile true {\n  s = \"${s}${s}\"\n}")
+	want := "interpolated string is longer than 16777216 bytes"
+	runtimeErr, ok := err.(RuntimeError)
+	if !ok || runtimeErr.Message != want {
+		t.Errorf("got error: %v, want a RuntimeError %q", err, want)
+	}
+}
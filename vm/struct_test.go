@@ -0,0 +1,81 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "constructor and fields",
+			source: "struct Point { x, y }\nvar p = Point(1, \"a\")\nprint p\nprint p.x\nprint p.y\nprint Point",
+			want:   "Point(x: 1, y: \"a\")\n1\na\n<struct Point>\n",
+		},
+		{
+			name:   "field assignment",
+			source: "struct Point { x, y }\nvar p = Point(1, 2)\np.x = 3\nprint p.y = p.x + 1\np.x *= 10\nprint p",
+			want:   "4\nPoint(x: 30, y: 4)\n",
+		},
+		{
+			name:   "instances are shared",
+			source: "struct Box { value }\nvar a = Box(1)\nvar b = a\nb.value = 2\nprint a.value",
+			want:   "2\n",
+		},
+		{
+			name:   "structural equality",
+			source: "struct P { x, y }\nstruct Q { x, y }\nprint P(1, string.split(\"a,b\", \",\")) == P(1, string.split(\"a,b\", \",\"))\nprint P(1, 2) == P(1, 3)\nprint P(1, 2) == Q(1, 2)\nprint P(P(1, 2), null) != P(P(1, 2), null)\nprint P == P",
+			want:   "true\nfalse\nfalse\nfalse\ntrue\n",
+		},
+		{
+			name:   "cyclic instances",
+			source: "struct Node { value, next }\nvar a = Node(1, null)\na.next = a\nvar b = Node(1, null)\nb.next = b\nprint a\nprint a == b",
+			want:   "Node(value: 1, next: ...)\ntrue\n",
+		},
+		{
+			name:   "local struct",
+			source: "{\n    struct Empty {}\n    var e = Empty()\n    print e\n    print e == Empty()\n}",
+			want:   "Empty()\ntrue\n",
+		},
+		{
+			name:   "compound assignment of a nested field",
+			source: "struct Counter { n }\nvar c = Counter(0)\nstruct Holder { counter }\nvar h = Holder(c)\nh.counter.n += 5\nprint c",
+			want:   "Counter(n: 5)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runSource(t, tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tt.want {
+				t.Errorf("got output: %q, want: %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "struct P { x, y }\nP(1)", want: "P() takes 2 arguments, one for each field (x, y), got 1"},
+		{source: "struct P { x }\nprint P(1).y", want: "struct 'P' has no field 'y'"},
+		{source: "struct P { x }\nvar p = P(1)\np.y = 2", want: "struct 'P' has no field 'y'"},
+		{source: "math.pi = 3", want: "only struct instances have assignable fields, got <module math>"},
+		{source: "var a = 1\na.b += 1", want: "only modules, maps, struct instances and errors have properties, got 1"},
+		{source: "struct P { x }\nprint P.x", want: "only modules, maps, struct instances and errors have properties, got <struct P>"},
+	}
+	for _, tt := range tests {
+		_, err := runSource(t, tt.source)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got error for %q: %v, want: %s", tt.source, err, tt.want)
+		}
+	}
+}
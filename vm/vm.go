@@ -3,7 +3,14 @@ package vm
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
 	"nilan/compiler"
+	"nilan/stdlib"
+	"nilan/value"
+	"os"
+	"strings"
 )
 
 type arithmeticFuncFloat func(a float64, b float64) float64
@@ -27,16 +34,61 @@ func multFloat(a float64, b float64) float64 {
 func multInt(a int64, b int64) int64 {
 	return a * b
 }
+
+// NOTE: Division by zero is reported as a RuntimeError by execArithmeticInstruction
+// before these functions are called.
 func divFloat(a float64, b float64) float64 {
-	// TODO: Add runtime error division by zero
 	return a / b
 }
 func divInt(a int64, b int64) int64 {
-	// TODO: Add runtime error division by zero
-
 	return a / b
 }
 
+// NOTE: Floor division and modulo by zero are reported as a RuntimeError by execArithmeticInstruction
+// before these functions are called.
+func floorDivFloat(a float64, b float64) float64 {
+	return value.FloorDivFloat(a, b)
+}
+func floorDivInt(a int64, b int64) int64 {
+	return value.FloorDivInt(a, b)
+}
+func modFloat(a float64, b float64) float64 {
+	return value.ModFloat(a, b)
+}
+func modInt(a int64, b int64) int64 {
+	return value.ModInt(a, b)
+}
+
+func powFloat(a float64, b float64) float64 {
+	return math.Pow(a, b)
+}
+
+// NOTE: Negative exponents are computed with powFloat by execArithmeticInstruction.
+func powInt(a int64, b int64) int64 {
+	return value.PowInt(a, b)
+}
+
+type bitwiseFunc func(a int64, b int64) int64
+
+func bitwiseAnd(a int64, b int64) int64 {
+	return a & b
+}
+func bitwiseOr(a int64, b int64) int64 {
+	return a | b
+}
+func bitwiseXor(a int64, b int64) int64 {
+	return a ^ b
+}
+
+// NOTE: Negative shift counts are reported as a RuntimeError by execBitwiseInstruction
+// before these functions are called.
+func shiftLeft(a int64, b int64) int64 {
+	return a << b
+}
+func shiftRight(a int64, b int64) int64 {
+	return a >> b
+}
+
 type equalityFuncFloat func(a float64, b float64) bool
 type equalityFuncInt func(a int64, b int64) bool
 
@@ -169,6 +221,30 @@ func makeComparisonHandler(f equalityFuncFloat, i equalityFuncInt) comparisonOpH
 
 }
 
+// isZero determines if a value is a numeric zero.
+func isZero(val any) bool {
+	switch v := val.(type) {
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	case *big.Int:
+		return v.Sign() == 0
+	case value.Decimal:
+		return v.Sign() == 0
+	}
+	return false
+}
+
+// formatOperand formats an operand for error messages, using Nilan's `null`
+// instead of Go's `<nil>`.
+func formatOperand(value any) any {
+	if value == nil {
+		return "null"
+	}
+	return value
+}
+
 func isFalsey(value any) bool {
 	if value == nil {
 		return true
@@ -198,13 +274,52 @@ type VirtualMachine struct {
 	globalVars map[string]any
 	// comparisonOpHandlers maps comparison opcodes to their corresponding handler functions.
 	comparisonOpHandlers map[compiler.Opcode]comparisonOpHandler
+	// out is where `print` statements and REPL results are written to. Defaults to standard output.
+	out io.Writer
+	// instructionCount is the total number of instructions executed by the VM.
+	instructionCount uint64
+	// instructionBudget is the maximum number of instructions the VM is allowed to execute.
+	// A budget of 0 means the VM can execute an unlimited number of instructions.
+	instructionBudget uint64
+	// handlers stores the exception handlers registered by OP_TRY_BEGIN instructions,
+	// the most recently registered handler is the last one.
+	handlers []handlerFrame
+	// overflow determines what the VM does when the result of an integer operation does not fit in an int64.
+	overflow IntegerOverflow
+	// decimalRounding and decimalPlaces determine how an inexact decimal division is rounded.
+	decimalRounding value.Rounding
+	decimalPlaces   int32
+	// host provides the arguments, input and capabilities used by the system functions of the standard library.
+	host *stdlib.Host
+	// importer finds and compiles the modules imported by the program, nil if it can not import modules.
+	importer Importer
+	// main is the canonical path of the main program, "" if it is not a file.
+	main string
+	// modules caches the imported modules by their canonical path, so each module is executed once.
+	modules map[string]*value.Module
+	// importing holds the canonical paths of the modules being executed, from the first module
+	// imported by the main program to the current module. It is used to detect import cycles.
+	importing []string
+}
+
+// handlerFrame is an exception handler registered by the VM while executing a `try` statement.
+type handlerFrame struct {
+	// The byte offset of the instruction the VM jumps to when an error is caught.
+	target int
+	// The number of values on the stack when the handler was registered.
+	// The stack is restored to this depth when an error is caught.
+	stackDepth int
 }
 
 // Creates a new VM instance
 func New() *VirtualMachine {
+	host := &stdlib.Host{}
 	return &VirtualMachine{
-		debug:      true,
-		globalVars: make(map[string]any),
+		debug:         true,
+		host:          host,
+		globalVars:    stdlib.Globals(host),
+		out:           os.Stdout,
+		decimalPlaces: DefaultDecimalPlaces,
 		comparisonOpHandlers: map[compiler.Opcode]comparisonOpHandler{
 			compiler.OP_LARGER:       makeComparisonHandler(largerThanFloat, largerThanInt),
 			compiler.OP_LESS:         makeComparisonHandler(smallerThanFloat, smallerThanInt),
@@ -214,11 +329,44 @@ func New() *VirtualMachine {
 	}
 }
 
+// SetOutput sets the writer where the VM writes the output of `print` statements.
+// This is useful to capture the output of a program, for example in tests and benchmarks.
+func (vm *VirtualMachine) SetOutput(out io.Writer) {
+	vm.out = out
+}
+
+// SetHost sets the arguments, the standard input and the capabilities of the programs executed
+// by the VM. By default programs have no arguments and no input, and can not access the file
+// system or the environment variables.
+func (vm *VirtualMachine) SetHost(host stdlib.Host) {
+	*vm.host = host
+}
+
+// InstructionCount returns the total number of instructions executed by the VM
+// since it was created.
+func (vm *VirtualMachine) InstructionCount() uint64 {
+	return vm.instructionCount
+}
+
+// SetInstructionBudget limits the total number of instructions the VM can execute.
+// Once the budget is exhausted `Run` returns a RuntimeError, which guarantees that
+// untrusted programs (e.g containing infinite loops) terminate. A budget of 0 removes the limit.
+func (vm *VirtualMachine) SetInstructionBudget(budget uint64) {
+	vm.instructionBudget = budget
+}
+
 // handleNumericEqualityOps applies numeric comparison functions to the two topmost
 // values on the VM stack.
 func (vm *VirtualMachine) handleNumericEqualityOps(floatFunc equalityFuncFloat, intFunc equalityFuncInt) error {
 	b := vm.stack.Pop()
 	a := vm.stack.Pop()
+	if (isArbitraryPrecision(a) || isArbitraryPrecision(b)) && (isNumeric(a) || isArbitraryPrecision(a)) && (isNumeric(b) || isArbitraryPrecision(b)) {
+		// NOTE: Comparing the result of value.CompareNumbers with 0 using intFunc has the same result
+		// as comparing the values, e.g `cmp > 0` when a > b.
+		cmp, ok := value.CompareNumbers(a, b)
+		vm.stack.Push(ok && intFunc(int64(cmp), 0))
+		return nil
+	}
 	if !isNumeric(a) || !isNumeric(b) {
 		return RuntimeError{Message: fmt.Sprintf("operands must be numeric values: %v,%v", a, b)}
 	}
@@ -282,27 +430,122 @@ func (vm *VirtualMachine) handleNumericEqualityOps(floatFunc equalityFuncFloat,
 // instruction after its execution.
 //
 // Execution terminates normally when an OP_END opcode is encountered,
-// or returns an error if an unknown opcode is found.
+// or returns an error if an unknown opcode is found, an instruction is truncated,
+// an operand is out of range or the bytecode ends without an OP_END opcode.
+//
+// Runtime errors and values raised by OP_THROW are first passed to the most recently registered
+// exception handler, and are only returned if there is none. Errors returned include the source
+// line of the instruction which raised them when the bytecode contains a line table.
 //
 // Parameters:
 //   - bytecode: The compiled instructions to execute.
 //
 // Returns:
-//   - error: Any error encountered during execution, including unknown opcodes.
+//   - error: Any error encountered during execution, including unknown opcodes, or a
+//     stdlib.ExitError if the program called `exit`.
 func (vm *VirtualMachine) Run(bytecode compiler.Bytecode) error {
+	for {
+		err := vm.execute(bytecode)
+		if err == nil {
+			return nil
+		}
+		if !vm.catch(bytecode, err) {
+			vm.handlers = nil
+			return withSpan(bytecode, vm.ip, err)
+		}
+	}
+}
+
+// catch passes an error raised by the instruction at the current instruction pointer to the most
+// recently registered exception handler. The handler is removed, the stack is restored to its
+// depth when the handler was registered and the error, converted to a Nilan value, is pushed onto it.
+// Execution then continues at the handler's target.
+//
+// It returns false if the error can not be caught, either because there is no handler or the
+// error is not raised by the Nilan program, e.g the instruction budget was exceeded.
+func (vm *VirtualMachine) catch(bytecode compiler.Bytecode, err error) bool {
+	if len(vm.handlers) == 0 {
+		return false
+	}
+	if vm.instructionBudget > 0 && vm.instructionCount > vm.instructionBudget {
+		return false
+	}
+
+	var thrown any
+	switch e := err.(type) {
+	case ThrownError:
+		thrown = e.Value
+	case RuntimeError:
+		thrown = value.Error{Message: e.Message, Line: bytecode.LineAt(vm.ip)}
+	default:
+		return false
+	}
+
+	handler := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	if len(vm.stack) > handler.stackDepth {
+		vm.stack = vm.stack[:handler.stackDepth]
+	}
+	vm.stack.Push(thrown)
+	vm.ip = handler.target
+	return true
+}
+
+// withSpan adds the span of the source code of the instruction at `ip` to runtime errors which
+// do not have one.
+func withSpan(bytecode compiler.Bytecode, ip int, err error) error {
+	switch e := err.(type) {
+	case RuntimeError:
+		if e.Start.Line == 0 {
+			e.Start, e.End = bytecode.SpanAt(ip)
+		}
+		return e
+	case ThrownError:
+		if e.Start.Line == 0 {
+			e.Start, e.End = bytecode.SpanAt(ip)
+		}
+		return e
+	}
+	return err
+}
+
+// execute runs the instructions until OP_END is reached or an error is raised.
+// When an error is raised, the instruction pointer is left at the instruction which raised it.
+func (vm *VirtualMachine) execute(bytecode compiler.Bytecode) error {
 
 	var instructionLength int
 	for {
+		if vm.ip < 0 || vm.ip >= len(bytecode.Instructions) {
+			return RuntimeError{Message: fmt.Sprintf("instruction pointer %d is out of bounds, perhaps the bytecode is missing OP_END", vm.ip)}
+		}
 		opCode := compiler.Opcode(bytecode.Instructions[vm.ip])
 		intOpCode := int(opCode)
 
+		// Guards against reading operands past the end of the instructions array.
+		width := compiler.InstructionWidth(opCode)
+		if width == 0 {
+			// NOTE: This should only happen in development mode.
+			return fmt.Errorf("unknown opcode %v at ip %d", opCode, vm.ip)
+		}
+		if vm.ip+width > len(bytecode.Instructions) {
+			return RuntimeError{Message: fmt.Sprintf("truncated instruction %v at ip %d", opCode, vm.ip)}
+		}
+
+		vm.instructionCount++
+		if vm.instructionBudget > 0 && vm.instructionCount > vm.instructionBudget {
+			return RuntimeError{Message: fmt.Sprintf("instruction budget of %d exceeded", vm.instructionBudget)}
+		}
+
 		switch opCode {
 		case compiler.OP_END:
-			if vm.stack.Peek() != nil {
+			// NOTE: Modules are not echoed, importing a module whose last statement is an
+			// expression must not print its value. A module is compiled like any program, as
+			// its cached bytecode may also be executed as the main program.
+			if len(vm.importing) == 0 && vm.stack.Peek() != nil {
 				// NOTE: temp code to handle operations such as 2+2 to be printed in the REPL
 				// Can there be a more suitable place to handle this other than in the VM?
 				// for now it does not hurt to leave it here...
-				fmt.Println(vm.stack.Peek())
+				fmt.Fprintln(vm.out, vm.stack.Peek())
 			}
 			return nil
 
@@ -314,7 +557,11 @@ func (vm *VirtualMachine) Run(bytecode compiler.Bytecode) error {
 			l := vm.execPrintInstruction()
 			instructionLength = l
 		case compiler.OP_CONSTANT:
-			instructionLength = vm.execConstantInstruction(bytecode)
+			l, err := vm.execConstantInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
 
 		case compiler.OP_ADD:
 			l, err := vm.execArithmeticInstruction(addFloat, addInt, intOpCode)
@@ -340,8 +587,57 @@ func (vm *VirtualMachine) Run(bytecode compiler.Bytecode) error {
 				return err
 			}
 			instructionLength = l
+		case compiler.OP_FLOOR_DIVIDE:
+			l, err := vm.execArithmeticInstruction(floorDivFloat, floorDivInt, intOpCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_MODULO:
+			l, err := vm.execArithmeticInstruction(modFloat, modInt, intOpCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_POWER:
+			l, err := vm.execArithmeticInstruction(powFloat, powInt, intOpCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
 
-		case compiler.OP_NEGATE, compiler.OP_NOT:
+		case compiler.OP_BITWISE_AND:
+			l, err := vm.execBitwiseInstruction(bitwiseAnd, opCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_BITWISE_OR:
+			l, err := vm.execBitwiseInstruction(bitwiseOr, opCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_BITWISE_XOR:
+			l, err := vm.execBitwiseInstruction(bitwiseXor, opCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_SHIFT_LEFT:
+			l, err := vm.execBitwiseInstruction(shiftLeft, opCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_SHIFT_RIGHT:
+			l, err := vm.execBitwiseInstruction(shiftRight, opCode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+
+		case compiler.OP_NEGATE, compiler.OP_NOT, compiler.OP_BITWISE_NOT:
 			l, err := vm.execUnaryInstruction(opCode)
 			if err != nil {
 				return err
@@ -370,15 +666,74 @@ func (vm *VirtualMachine) Run(bytecode compiler.Bytecode) error {
 			vm.ip = vm.execJumpIfFalseInstruction(bytecode)
 			continue
 		case compiler.OP_SET_GLOBAL:
-			instructionLength = vm.execDefineGlobalInstruction(bytecode)
+			l, err := vm.execDefineGlobalInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
 		case compiler.OP_GET_GLOBAL:
-			instructionLength = vm.execGetGlobalInstruction(bytecode)
+			l, err := vm.execGetGlobalInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
 		case compiler.OP_SET_LOCAL:
 			instructionLength = vm.execSetLocalInstruction(bytecode)
 		case compiler.OP_GET_LOCAL:
-			instructionLength = vm.execGetLocalInstruction(bytecode)
+			l, err := vm.execGetLocalInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
 		case compiler.OP_SCOPE_EXIT:
 			instructionLength = vm.execScopeExitInstruction(bytecode)
+		case compiler.OP_TRY_BEGIN:
+			l, err := vm.execTryBeginInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_TRY_END:
+			if len(vm.handlers) == 0 {
+				return RuntimeError{Message: "OP_TRY_END without a registered exception handler"}
+			}
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			instructionLength = compiler.OPCODE_TOTAL_BYTES
+		case compiler.OP_THROW:
+			return ThrownError{Value: vm.stack.Pop()}
+		case compiler.OP_BUILD_STRING:
+			l, err := vm.execBuildStringInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_CALL:
+			l, err := vm.execCallInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_GET_PROPERTY:
+			l, err := vm.execGetPropertyInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_IMPORT:
+			l, err := vm.execImportInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_SET_PROPERTY:
+			l, err := vm.execSetPropertyInstruction(bytecode)
+			if err != nil {
+				return err
+			}
+			instructionLength = l
+		case compiler.OP_DUP:
+			vm.stack.Push(vm.stack.Peek())
+			instructionLength = compiler.OPCODE_TOTAL_BYTES
 		default:
 			// NOTE: This should only happen in development mode.
 			return fmt.Errorf("unknown opcode %v at ip %d", opCode, vm.ip)
@@ -390,13 +745,120 @@ func (vm *VirtualMachine) Run(bytecode compiler.Bytecode) error {
 
 func (vm *VirtualMachine) execPrintInstruction() int {
 	value := vm.stack.Pop()
+	fmt.Fprintln(vm.out, formatValue(value))
+	return compiler.OPCODE_TOTAL_BYTES
+}
+
+// formatValue converts a value to the text printed for it, `null` for a nil value.
+func formatValue(value any) string {
 	if value == nil {
-		fmt.Println("null")
-		return compiler.OPCODE_TOTAL_BYTES
+		return "null"
 	}
+	return fmt.Sprint(value)
+}
 
-	fmt.Println(value)
-	return compiler.OPCODE_TOTAL_BYTES
+// execBuildStringInstruction pops the number of values given by the instruction's operand and
+// pushes the string concatenating them, in the order they were pushed.
+// It returns the number of bytes consumed by the instruction, or an error if the stack holds
+// fewer values than the operand or the string would be longer than `stdlib.MaxStringLength`.
+func (vm *VirtualMachine) execBuildStringInstruction(bytecode compiler.Bytecode) (int, error) {
+	total := int(vm.getOperand(bytecode))
+	if total > len(vm.stack) {
+		return 0, RuntimeError{Message: fmt.Sprintf("OP_BUILD_STRING expects %d values, the stack holds %d", total, len(vm.stack))}
+	}
+	values := vm.stack[len(vm.stack)-total:]
+	parts := make([]string, len(values))
+	length := 0
+	for i, value := range values {
+		parts[i] = formatValue(value)
+		length += len(parts[i])
+		if length > stdlib.MaxStringLength {
+			return 0, RuntimeError{Message: fmt.Sprintf("interpolated string is longer than %d bytes", stdlib.MaxStringLength)}
+		}
+	}
+	vm.stack = vm.stack[:len(vm.stack)-total]
+	vm.stack.Push(strings.Join(parts, ""))
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+}
+
+// execCallInstruction pops the number of arguments given by the instruction's operand and the
+// function below them, and pushes the result of calling the function with the arguments. Calling
+// a struct creates an instance.
+// It returns the number of bytes consumed by the instruction, a RuntimeError if the callee is
+// not a function or a struct or the call fails, or a stdlib.ExitError if the function exits the program.
+func (vm *VirtualMachine) execCallInstruction(bytecode compiler.Bytecode) (int, error) {
+	total := int(vm.getOperand(bytecode))
+	if total >= len(vm.stack) {
+		return 0, RuntimeError{Message: fmt.Sprintf("OP_CALL expects %d values, the stack holds %d", total+1, len(vm.stack))}
+	}
+	callee := vm.stack[len(vm.stack)-total-1]
+	function, ok := callee.(value.Callable)
+	if !ok {
+		return 0, RuntimeError{Message: fmt.Sprintf("can only call functions and structs, got %v", formatOperand(callee))}
+	}
+	// NOTE: The arguments are copied, as the stack is reused once they are popped.
+	arguments := append([]any(nil), vm.stack[len(vm.stack)-total:]...)
+	vm.stack = vm.stack[:len(vm.stack)-total-1]
+
+	result, err := function.Call(arguments)
+	if exit, ok := err.(stdlib.ExitError); ok {
+		// NOTE: Exiting is not an error of the program, so it is returned as is and can not be caught.
+		return 0, exit
+	}
+	if err != nil {
+		return 0, RuntimeError{Message: err.Error()}
+	}
+	vm.stack.Push(result)
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+}
+
+// execGetPropertyInstruction pops a module, a map or a struct instance and pushes its member
+// named by the constant referenced by the instruction's operand.
+// It returns the number of bytes consumed by the instruction, or a RuntimeError if the value has
+// no properties or no such member.
+func (vm *VirtualMachine) execGetPropertyInstruction(bytecode compiler.Bytecode) (int, error) {
+	operand := int(vm.getOperand(bytecode))
+	if operand >= len(bytecode.ConstantsPool) {
+		return 0, RuntimeError{Message: fmt.Sprintf("constant index %d is out of range", operand)}
+	}
+	name, _ := bytecode.ConstantsPool[operand].(string)
+
+	object := vm.stack.Pop()
+	holder, ok := object.(value.Object)
+	if !ok {
+		return 0, RuntimeError{Message: fmt.Sprintf("only modules, maps, struct instances and errors have properties, got %v", formatOperand(object))}
+	}
+	member, err := holder.Member(name)
+	if err != nil {
+		return 0, RuntimeError{Message: err.Error()}
+	}
+	vm.stack.Push(member)
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+}
+
+// execSetPropertyInstruction pops a value and a struct instance, assigns the value to the field
+// named by the constant referenced by the instruction's operand and pushes the value back, as
+// assignments are expressions.
+// It returns the number of bytes consumed by the instruction, or a RuntimeError if the object
+// is not a struct instance or has no such field.
+func (vm *VirtualMachine) execSetPropertyInstruction(bytecode compiler.Bytecode) (int, error) {
+	operand := int(vm.getOperand(bytecode))
+	if operand >= len(bytecode.ConstantsPool) {
+		return 0, RuntimeError{Message: fmt.Sprintf("constant index %d is out of range", operand)}
+	}
+	name, _ := bytecode.ConstantsPool[operand].(string)
+
+	val := vm.stack.Pop()
+	object := vm.stack.Pop()
+	holder, ok := object.(value.MutableObject)
+	if !ok {
+		return 0, RuntimeError{Message: fmt.Sprintf("only struct instances have assignable fields, got %v", formatOperand(object))}
+	}
+	if err := holder.SetMember(name, val); err != nil {
+		return 0, RuntimeError{Message: err.Error()}
+	}
+	vm.stack.Push(val)
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
 }
 
 // execJumpInstruction executes a `OP_JUMP` instruction by reading the target byte
@@ -441,13 +903,13 @@ func (vm *VirtualMachine) execEqualityInstruction(opCode compiler.Opcode) (int,
 	if opCode == compiler.OP_EQUALITY {
 		b := vm.stack.Pop()
 		a := vm.stack.Pop()
-		vm.stack.Push(a == b)
+		vm.stack.Push(valuesEqual(a, b))
 		return compiler.OPCODE_TOTAL_BYTES, nil
 	}
 	if opCode == compiler.OP_NOT_EQUAL {
 		b := vm.stack.Pop()
 		a := vm.stack.Pop()
-		vm.stack.Push(a != b)
+		vm.stack.Push(!valuesEqual(a, b))
 		return compiler.OPCODE_TOTAL_BYTES, nil
 	}
 	return 0, RuntimeError{Message: fmt.Sprintf("unknown equality opcode %v", opCode)}
@@ -510,11 +972,14 @@ func (vm *VirtualMachine) execSetLocalInstruction(bytecode compiler.Bytecode) in
 // execGetLocalInstruction retrieves a local variable from the stack at the position
 // specified by the operand in the bytecode, and pushes its value onto the top
 // of the stack. It returns the number of bytes consumed by the instruction.
-func (vm *VirtualMachine) execGetLocalInstruction(bytecode compiler.Bytecode) int {
+func (vm *VirtualMachine) execGetLocalInstruction(bytecode compiler.Bytecode) (int, error) {
 	slot := vm.getOperand(bytecode)
+	if int(slot) >= len(vm.stack) {
+		return 0, RuntimeError{Message: fmt.Sprintf("local variable slot %d is out of range", slot)}
+	}
 	// NOTE: The value is pushed to the top of the stack so it can be used by subsequent operations.
 	vm.stack.Push(vm.stack[slot])
-	return compiler.THREE_BYTE_INSTRUCTION_LENGTH
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
 }
 
 // execScopeExitInstruction handles the execution of the scope exit instruction in the VM.
@@ -528,34 +993,75 @@ func (vm *VirtualMachine) execScopeExitInstruction(bytecode compiler.Bytecode) i
 	return compiler.THREE_BYTE_INSTRUCTION_LENGTH
 }
 
+// execTryBeginInstruction registers the exception handler referenced by the instruction's operand.
+// It returns the number of bytes consumed by the instruction.
+func (vm *VirtualMachine) execTryBeginInstruction(bytecode compiler.Bytecode) (int, error) {
+	operand := vm.getOperand(bytecode)
+	if int(operand) >= len(bytecode.Handlers) {
+		return 0, RuntimeError{Message: fmt.Sprintf("exception handler index %d is out of range", operand)}
+	}
+	target := bytecode.Handlers[operand].Target
+	if target < 0 || target >= len(bytecode.Instructions) {
+		return 0, RuntimeError{Message: fmt.Sprintf("exception handler target %d is out of bounds", target)}
+	}
+	vm.handlers = append(vm.handlers, handlerFrame{target: target, stackDepth: len(vm.stack)})
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+}
+
 // execDefineGlobalInstruction defines a global variable, and assigns the corresponding
 // value from the top of the stack to it.
-func (vm *VirtualMachine) execDefineGlobalInstruction(bytecode compiler.Bytecode) int {
-	operand := vm.getOperand(bytecode)
-	name := bytecode.NameConstants[operand]
+func (vm *VirtualMachine) execDefineGlobalInstruction(bytecode compiler.Bytecode) (int, error) {
+	name, err := vm.getNameConstant(bytecode)
+	if err != nil {
+		return 0, err
+	}
 
-	vm.globalVars[name] = vm.stack.Pop()
-	return compiler.THREE_BYTE_INSTRUCTION_LENGTH
+	// NOTE: The value is not popped from the stack, as assignments are expressions
+	// and their value may be used in subsequent instructions, e.g `a = b = 10`.
+	vm.globalVars[name] = vm.stack.Peek()
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
 }
 
 // execSetGlobalInstruction sets the value of an existing global variable
-func (vm *VirtualMachine) execGetGlobalInstruction(bytecode compiler.Bytecode) int {
-	operand := vm.getOperand(bytecode)
-	name := bytecode.NameConstants[operand]
+func (vm *VirtualMachine) execGetGlobalInstruction(bytecode compiler.Bytecode) (int, error) {
+	name, err := vm.getNameConstant(bytecode)
+	if err != nil {
+		return 0, err
+	}
 	vm.stack.Push(vm.globalVars[name])
-	return compiler.THREE_BYTE_INSTRUCTION_LENGTH
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
+}
+
+// getNameConstant returns the name in the bytecode's NameConstants pool referenced by
+// the operand of the current instruction.
+// A RuntimeError is returned if the operand is out of range.
+func (vm *VirtualMachine) getNameConstant(bytecode compiler.Bytecode) (string, error) {
+	operand := vm.getOperand(bytecode)
+	if int(operand) >= len(bytecode.NameConstants) {
+		return "", RuntimeError{Message: fmt.Sprintf("name constant index %d is out of range", operand)}
+	}
+	return bytecode.NameConstants[operand], nil
 }
 
 // Executes a unary operations and pushes the result onto the VM's stack.
 func (vm *VirtualMachine) execUnaryInstruction(opCode compiler.Opcode) (int, error) {
-	value := vm.stack.Pop()
-	if value == nil {
+	// NOTE: `null` is a valid operand, e.g `!null`, so underflow is detected by the stack's size.
+	if vm.stack.IsEmpty() {
 		return 0, RuntimeError{Message: "stack underflow on unary operation"}
 	}
+	operand := vm.stack.Pop()
 
 	if opCode == compiler.OP_NEGATE {
-		if isFloat(value) {
-			val, err := literalToFloat64(value)
+		if d, ok := operand.(value.Decimal); ok {
+			vm.stack.Push(d.Neg())
+			return compiler.OPCODE_TOTAL_BYTES, nil
+		}
+		if n, ok := operand.(*big.Int); ok {
+			vm.stack.Push(normalizeBigInt(new(big.Int).Neg(n)))
+			return compiler.OPCODE_TOTAL_BYTES, nil
+		}
+		if isFloat(operand) {
+			val, err := literalToFloat64(operand)
 			if err != nil {
 				return 0, RuntimeError{Message: err.Error()}
 			}
@@ -563,22 +1069,32 @@ func (vm *VirtualMachine) execUnaryInstruction(opCode compiler.Opcode) (int, err
 			return compiler.OPCODE_TOTAL_BYTES, nil
 		}
 
-		val, err := literalToInt64(value)
+		val, err := literalToInt64(operand)
 		if err != nil {
-			return 0, RuntimeError{Message: err.Error()}
+			return 0, RuntimeError{Message: fmt.Sprintf("operand must be a numeric value: -%v", formatOperand(operand))}
 		}
-		vm.stack.Push(-val)
+		result, err := vm.negateInt(val)
+		if err != nil {
+			return 0, err
+		}
+		vm.stack.Push(result)
 
 	}
 
-	if opCode == compiler.OP_NOT {
-		switch v := value.(type) {
-		case bool:
-			vm.stack.Push(!v)
-		default:
-			// non-nil, non-falsey values are truthy -> !truthy == false
-			vm.stack.Push(false)
+	if opCode == compiler.OP_BITWISE_NOT {
+		if n, ok := operand.(*big.Int); ok {
+			vm.stack.Push(normalizeBigInt(new(big.Int).Not(n)))
+			return compiler.OPCODE_TOTAL_BYTES, nil
 		}
+		val, ok := operand.(int64)
+		if !ok {
+			return 0, RuntimeError{Message: fmt.Sprintf("operand must be an integer value: ~%v", operand)}
+		}
+		vm.stack.Push(^val)
+	}
+
+	if opCode == compiler.OP_NOT {
+		vm.stack.Push(isFalsey(operand))
 	}
 
 	return compiler.OPCODE_TOTAL_BYTES, nil
@@ -598,11 +1114,15 @@ func (vm *VirtualMachine) execUnaryInstruction(opCode compiler.Opcode) (int, err
 // Returns:
 //   - int: The total number of bytes consumed by this instruction, used to
 //     increment the VM's instruction pointer.
-func (vm *VirtualMachine) execConstantInstruction(bytecode compiler.Bytecode) int {
+//   - error: A RuntimeError if the operand is out of range of the constants pool.
+func (vm *VirtualMachine) execConstantInstruction(bytecode compiler.Bytecode) (int, error) {
 	operand := vm.getOperand(bytecode)
+	if int(operand) >= len(bytecode.ConstantsPool) {
+		return 0, RuntimeError{Message: fmt.Sprintf("constant index %d is out of range", operand)}
+	}
 	value := bytecode.ConstantsPool[operand]
 	vm.stack.Push(value)
-	return compiler.THREE_BYTE_INSTRUCTION_LENGTH
+	return compiler.THREE_BYTE_INSTRUCTION_LENGTH, nil
 }
 
 // Executes an arithmetic operation on the VM's stack
@@ -625,7 +1145,36 @@ func (vm *VirtualMachine) execArithmeticInstruction(operationFloat arithmeticFun
 	b := vm.stack.Pop()
 	a := vm.stack.Pop()
 
-	if a != nil && b != nil {
+	if a == nil || b == nil {
+		message := fmt.Sprintf("operands must be numeric values: %v,%v", formatOperand(a), formatOperand(b))
+		return 0, RuntimeError{Message: message}
+	}
+
+	if (opCode == int(compiler.OP_DIVIDE) || opCode == int(compiler.OP_FLOOR_DIVIDE)) && isZero(b) {
+		return 0, RuntimeError{Message: "division by zero"}
+	}
+	if opCode == int(compiler.OP_MODULO) && isZero(b) {
+		return 0, RuntimeError{Message: "modulo by zero"}
+	}
+
+	if isDecimal(a) || isDecimal(b) {
+		result, err := vm.decimalArithmetic(compiler.Opcode(opCode), a, b)
+		if err != nil {
+			return 0, err
+		}
+		vm.stack.Push(result)
+		return compiler.OPCODE_TOTAL_BYTES, nil
+	}
+	if isBigInt(a) || isBigInt(b) {
+		result, err := bigArithmetic(operationFloat, compiler.Opcode(opCode), a, b)
+		if err != nil {
+			return 0, err
+		}
+		vm.stack.Push(result)
+		return compiler.OPCODE_TOTAL_BYTES, nil
+	}
+
+	{
 		var aFloatVal float64
 		var aIntVal int64
 		var bFloatVal float64
@@ -652,7 +1201,7 @@ func (vm *VirtualMachine) execArithmeticInstruction(operationFloat arithmeticFun
 			bIntVal = val
 		}
 
-		if !isAFloat && !isBFloat && !isAInt && !isBInt {
+		if !(isAFloat || isAInt) || !(isBFloat || isBInt) {
 			message := fmt.Sprintf("operands must be numeric values: %v,%v", a, b)
 			return 0, RuntimeError{Message: message}
 		}
@@ -670,11 +1219,15 @@ func (vm *VirtualMachine) execArithmeticInstruction(operationFloat arithmeticFun
 			vm.stack.Push(result)
 		}
 		if isAInt && isBInt {
-			if opCode == int(compiler.OP_DIVIDE) {
+			// NOTE: Division and negative powers of integers, e.g `7 / 2` and `2 ** -1`, are floats.
+			if opCode == int(compiler.OP_DIVIDE) || opCode == int(compiler.OP_POWER) && bIntVal < 0 {
 				result := operationFloat(float64(aIntVal), float64(bIntVal))
 				vm.stack.Push(result)
 			} else {
-				result := operationInt(aIntVal, bIntVal)
+				result, err := vm.intOperation(compiler.Opcode(opCode), operationInt, aIntVal, bIntVal)
+				if err != nil {
+					return 0, err
+				}
 				vm.stack.Push(result)
 			}
 		}
@@ -683,6 +1236,40 @@ func (vm *VirtualMachine) execArithmeticInstruction(operationFloat arithmeticFun
 	return compiler.OPCODE_TOTAL_BYTES, nil
 }
 
+// execBitwiseInstruction executes a bitwise operation (&, |, ^, <<, >>) on the two integers on
+// top of the VM's stack and pushes the result.
+// It returns the number of bytes consumed by the instruction, or an error if an operand is not
+// an integer or a shift count is negative.
+func (vm *VirtualMachine) execBitwiseInstruction(operation bitwiseFunc, opCode compiler.Opcode) (int, error) {
+	b := vm.stack.Pop()
+	a := vm.stack.Pop()
+
+	aBig, isAInt := toBigInt(a)
+	bBig, isBInt := toBigInt(b)
+	if !isAInt || !isBInt {
+		message := fmt.Sprintf("operands must be integer values: %v,%v", formatOperand(a), formatOperand(b))
+		return 0, RuntimeError{Message: message}
+	}
+	if (opCode == compiler.OP_SHIFT_LEFT || opCode == compiler.OP_SHIFT_RIGHT) && bBig.Sign() < 0 {
+		return 0, RuntimeError{Message: fmt.Sprintf("negative shift count: %v", b)}
+	}
+
+	var result any
+	var err error
+	aIntVal, isAInt64 := a.(int64)
+	bIntVal, isBInt64 := b.(int64)
+	if isAInt64 && isBInt64 {
+		result, err = vm.intOperation(opCode, arithmeticFuncInt(operation), aIntVal, bIntVal)
+	} else {
+		result, err = bigIntOperation(opCode, aBig, bBig)
+	}
+	if err != nil {
+		return 0, err
+	}
+	vm.stack.Push(result)
+	return compiler.OPCODE_TOTAL_BYTES, nil
+}
+
 // getOperand extracts a 16-bit operand from the bytecode instructions at the current instruction pointer (ip).
 // It reads the operand by skipping the opcode and returns it as a uint16 value using big-endian encoding.
 func (vm *VirtualMachine) getOperand(bytecode compiler.Bytecode) uint16 {
@@ -1,7 +1,10 @@
 package vm
 
 import (
+	"bytes"
 	"nilan/compiler"
+	"nilan/lexer"
+	"nilan/parser"
 	"testing"
 )
 
@@ -642,6 +645,17 @@ func TestExecuteBytecodeNotOpVMStack(t *testing.T) {
 			},
 			expectedStack: []any{bool(true)},
 		},
+		{
+			bytecode: compiler.Bytecode{
+				Instructions: []byte{
+					byte(compiler.OP_CONSTANT), 0, 0,
+					byte(compiler.OP_NOT),
+					byte(compiler.OP_END),
+				},
+				ConstantsPool: []any{nil},
+			},
+			expectedStack: []any{bool(true)},
+		},
 	}
 
 	assertResults(tests, t)
@@ -1029,3 +1043,176 @@ func TestExecuteBytecodeComparisonOpVMStack(t *testing.T) {
 
 	assertResults(tests, t)
 }
+
+func TestRunMalformedBytecodeReturnsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytecode compiler.Bytecode
+	}{
+		{
+			name:     "missing OP_END",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_CONSTANT), 0, 0}, ConstantsPool: []any{int64(1)}},
+		},
+		{
+			name:     "truncated instruction",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_CONSTANT), 0}},
+		},
+		{
+			name:     "constant out of range",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_CONSTANT), 0, 9, byte(compiler.OP_END)}},
+		},
+		{
+			name:     "name constant out of range",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_GET_GLOBAL), 0, 1, byte(compiler.OP_END)}},
+		},
+		{
+			name:     "local slot out of range",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_GET_LOCAL), 0, 3, byte(compiler.OP_END)}},
+		},
+		{
+			name: "division by zero",
+			bytecode: compiler.Bytecode{
+				Instructions:  []byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_CONSTANT), 0, 1, byte(compiler.OP_DIVIDE), byte(compiler.OP_END)},
+				ConstantsPool: []any{int64(1), int64(0)},
+			},
+		},
+		{
+			name:     "build string with too few values",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_BUILD_STRING), 0, 2, byte(compiler.OP_END)}},
+		},
+		{
+			name:     "infinite loop exceeds budget",
+			bytecode: compiler.Bytecode{Instructions: []byte{byte(compiler.OP_JUMP), 0, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := New()
+			vm.SetInstructionBudget(1000)
+			if err := vm.Run(tt.bytecode); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+// binaryBytecode returns the bytecode applying a binary opcode to two constants.
+func binaryBytecode(opCode compiler.Opcode, a any, b any) compiler.Bytecode {
+	return compiler.Bytecode{
+		Instructions: []byte{
+			byte(compiler.OP_CONSTANT), 0, 0,
+			byte(compiler.OP_CONSTANT), 0, 1,
+			byte(opCode),
+			byte(compiler.OP_END),
+		},
+		ConstantsPool: []any{a, b},
+	}
+}
+
+func TestExecuteBytecodeIntegerAndBitwiseOpVMStack(t *testing.T) {
+	tests := []struct {
+		bytecode      compiler.Bytecode
+		expectedStack any
+	}{
+		{bytecode: binaryBytecode(compiler.OP_MODULO, int64(-7), int64(3)), expectedStack: []any{int64(2)}},
+		{bytecode: binaryBytecode(compiler.OP_MODULO, float64(7.5), int64(-2)), expectedStack: []any{float64(-0.5)}},
+		{bytecode: binaryBytecode(compiler.OP_FLOOR_DIVIDE, int64(-7), int64(2)), expectedStack: []any{int64(-4)}},
+		{bytecode: binaryBytecode(compiler.OP_FLOOR_DIVIDE, float64(7), int64(2)), expectedStack: []any{float64(3)}},
+		{bytecode: binaryBytecode(compiler.OP_DIVIDE, int64(6), int64(2)), expectedStack: []any{float64(3)}},
+		{bytecode: binaryBytecode(compiler.OP_POWER, int64(3), int64(4)), expectedStack: []any{int64(81)}},
+		{bytecode: binaryBytecode(compiler.OP_POWER, int64(2), int64(-2)), expectedStack: []any{float64(0.25)}},
+		{bytecode: binaryBytecode(compiler.OP_POWER, float64(4), float64(0.5)), expectedStack: []any{float64(2)}},
+		{bytecode: binaryBytecode(compiler.OP_BITWISE_AND, int64(12), int64(10)), expectedStack: []any{int64(8)}},
+		{bytecode: binaryBytecode(compiler.OP_BITWISE_OR, int64(12), int64(10)), expectedStack: []any{int64(14)}},
+		{bytecode: binaryBytecode(compiler.OP_BITWISE_XOR, int64(12), int64(10)), expectedStack: []any{int64(6)}},
+		{bytecode: binaryBytecode(compiler.OP_SHIFT_LEFT, int64(3), int64(2)), expectedStack: []any{int64(12)}},
+		{bytecode: binaryBytecode(compiler.OP_SHIFT_RIGHT, int64(-12), int64(2)), expectedStack: []any{int64(-3)}},
+		{
+			bytecode: compiler.Bytecode{
+				Instructions:  []byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_BITWISE_NOT), byte(compiler.OP_END)},
+				ConstantsPool: []any{int64(0)},
+			},
+			expectedStack: []any{int64(-1)},
+		},
+	}
+
+	assertResults(tests, t)
+}
+
+func TestIntegerAndBitwiseOpErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytecode compiler.Bytecode
+		want     string
+	}{
+		{name: "floor division by zero", bytecode: binaryBytecode(compiler.OP_FLOOR_DIVIDE, int64(1), int64(0)), want: "division by zero"},
+		{name: "modulo by zero", bytecode: binaryBytecode(compiler.OP_MODULO, float64(1), float64(0)), want: "modulo by zero"},
+		{name: "bitwise operation on a float", bytecode: binaryBytecode(compiler.OP_BITWISE_AND, float64(1), int64(1)), want: "operands must be integer values: 1,1"},
+		{name: "negative shift count", bytecode: binaryBytecode(compiler.OP_SHIFT_LEFT, int64(1), int64(-1)), want: "negative shift count: -1"},
+		{
+			name: "bitwise not of a float",
+			bytecode: compiler.Bytecode{
+				Instructions:  []byte{byte(compiler.OP_CONSTANT), 0, 0, byte(compiler.OP_BITWISE_NOT), byte(compiler.OP_END)},
+				ConstantsPool: []any{float64(1.5)},
+			},
+			want: "operand must be an integer value: ~1.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Run(tt.bytecode)
+			runtimeErr, ok := err.(RuntimeError)
+			if !ok || runtimeErr.Message != tt.want {
+				t.Errorf("got error: %v, want a RuntimeError %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestREPLSessionAfterCompileError compiles each input with the same compiler and runs it on the
+// same VM, like the REPL. An input which does not compile must not leave code behind.
+func TestREPLSessionAfterCompileError(t *testing.T) {
+	astCompiler := compiler.NewASTCompiler()
+	machine := New()
+	var out bytes.Buffer
+	machine.SetOutput(&out)
+
+	inputs := []struct {
+		source  string
+		wantErr bool
+	}{
+		{source: "var x = 40"},
+		{source: "print a", wantErr: true},
+		{source: "var z = x\nprint b", wantErr: true},
+		{source: "print 1 + 1"},
+		{source: "x + 2"},
+	}
+	for _, input := range inputs {
+		tokens, _ := lexer.New(input.source).Scan()
+		statements, parseErrs := parser.Make(tokens).Parse()
+		if len(parseErrs) > 0 {
+			if !input.wantErr {
+				t.Fatalf("parsing errors in %q: %v", input.source, parseErrs)
+			}
+			continue
+		}
+		bytecode, errs := astCompiler.CompileAST(statements)
+		if (len(errs) > 0) != input.wantErr {
+			t.Fatalf("got compilation errors in %q: %v, want errors: %t", input.source, errs, input.wantErr)
+		}
+		if len(errs) > 0 {
+			continue
+		}
+		if err := compiler.Verify(bytecode); err != nil {
+			t.Fatalf("verification error in %q: %v", input.source, err)
+		}
+		if err := machine.Run(bytecode); err != nil {
+			t.Fatalf("unexpected error running %q: %v", input.source, err)
+		}
+	}
+	if want := "2\n42\n"; out.String() != want {
+		t.Errorf("got output: %q, want: %q", out.String(), want)
+	}
+}